@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+const (
+	// NamespacedPolicyNamespaceLabel is the namespace of the
+	// NamespacedNodeNetworkConfigurationPolicy a cluster policy applies
+	NamespacedPolicyNamespaceLabel = "nmstate.io/namespaced-policy-namespace"
+	// NamespacedPolicyNameLabel is the name of the
+	// NamespacedNodeNetworkConfigurationPolicy a cluster policy applies
+	NamespacedPolicyNameLabel = "nmstate.io/namespaced-policy-name"
+)
+
+// NamespacedNodeNetworkConfigurationPolicyStatus defines the observed state
+// of NamespacedNodeNetworkConfigurationPolicy
+type NamespacedNodeNetworkConfigurationPolicyStatus struct {
+	// Policy is the cluster NodeNetworkConfigurationPolicy applying the
+	// namespaced policy at the nodes
+	// +optional
+	Policy string `json:"policy,omitempty"`
+	// Conditions are the conditions of the cluster policy
+	// +optional
+	Conditions ConditionList `json:"conditions,omitempty"`
+	// Error is the failure checking the policy against the namespace quotas
+	// or applying the cluster policy
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// NodeNetworkConfigurationQuotaSpec defines what the namespaced policies of
+// some namespaces can configure
+type NodeNetworkConfigurationQuotaSpec struct {
+	// Namespaces are the namespaces whose namespaced policies are limited by
+	// the quota
+	Namespaces []string `json:"namespaces"`
+	// NodeSelector are the labels every namespaced policy node selector has
+	// to include, so the policies only select the nodes of the namespaces.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Interfaces are the names of the interfaces the namespaced policies can
+	// configure, route through or use as ports. Shell patterns like "eth1.*"
+	// are supported.
+	Interfaces []string `json:"interfaces"`
+	// Routes are the route destinations and tables the namespaced policies
+	// can configure, if it is not set they cannot configure routes.
+	// +optional
+	Routes *NodeNetworkConfigurationQuotaRoutes `json:"routes,omitempty"`
+}
+
+// NodeNetworkConfigurationQuotaRoutes limits the routes the namespaced
+// policies can configure
+type NodeNetworkConfigurationQuotaRoutes struct {
+	// Destinations are the CIDRs the route destinations have to be within,
+	// "0.0.0.0/0" or "::/0" have to be listed to configure default routes.
+	Destinations []string `json:"destinations"`
+	// TableIDs are the route tables the routes can be configured at, the
+	// main table 254 has to be listed to configure routes without table-id.
+	// +optional
+	TableIDs []int64 `json:"tableIDs,omitempty"`
+}
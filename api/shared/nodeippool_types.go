@@ -0,0 +1,64 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+const (
+	// IPAllocationPoolLabel is the NodeIPPool of a NodeIPAllocation
+	IPAllocationPoolLabel = "nmstate.io/ippool"
+	// IPAllocationNodeLabel is the node holding a NodeIPAllocation
+	IPAllocationNodeLabel = "nmstate.io/node"
+)
+
+// NodeIPPoolSpec defines the addresses of a NodeIPPool
+type NodeIPPoolSpec struct {
+	// Range is the addresses handed out to the nodes
+	Range NodeIPPoolRange `json:"range"`
+}
+
+// NodeIPPoolRange defines a range of addresses inside a subnet
+type NodeIPPoolRange struct {
+	// CIDR is the subnet of the addresses, its prefix length is the one
+	// configured at the interfaces, for example 10.10.10.0/24.
+	CIDR string `json:"cidr"`
+	// Start is the first address of the range. Default is the first host
+	// address of the subnet.
+	// +optional
+	Start string `json:"start,omitempty"`
+	// End is the last address of the range. Default is the last host address
+	// of the subnet.
+	// +optional
+	End string `json:"end,omitempty"`
+	// Exclude are addresses or subnets of the range that are not handed out
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// NodeIPAllocationSpec defines the address of a NodeIPPool held by a node
+type NodeIPAllocationSpec struct {
+	// Pool is the NodeIPPool the address belongs to
+	Pool string `json:"pool"`
+	// Node is the node holding the address
+	Node string `json:"node"`
+	// Address is the allocated address with the pool prefix length, for
+	// example 10.10.10.5/24
+	Address string `json:"address"`
+	// Policies are the NodeNetworkConfigurationPolicies using the address at
+	// the node, the address is released when none of them does anymore.
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+}
@@ -18,6 +18,7 @@ limitations under the License.
 package shared
 
 import (
+	"bytes"
 	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,6 +44,66 @@ type NodeNetworkConfigurationEnactmentStatus struct {
 	Conditions ConditionList `json:"conditions,omitempty"`
 
 	Features []string `json:"features,omitempty"`
+
+	// The last desired states successfully applied at the node, newest first,
+	// bounded to EnactmentHistoryLimit entries
+	History []NodeNetworkConfigurationEnactmentHistoryEntry `json:"history,omitempty"`
+
+	// A summary of the differences between the desired state and the node
+	// current state after the last apply
+	Diff *StateDiffSummary `json:"diff,omitempty"`
+
+	// The node state captured right before applying the policy generation,
+	// used to roll it back
+	StateBeforeApply *NodeNetworkConfigurationEnactmentStateBeforeApply `json:"stateBeforeApply,omitempty"`
+}
+
+// NodeNetworkConfigurationEnactmentStateBeforeApply contains the node state
+// touched by a desired state as it was before applying it
+type NodeNetworkConfigurationEnactmentStateBeforeApply struct {
+	// The policy generation applied after capturing the state
+	PolicyGeneration int64 `json:"policyGeneration"`
+
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// The interfaces, routes and other entries from the node state that the
+	// desired state changes, the ones the desired state adds are marked as absent
+	State State `json:"state,omitempty"`
+
+	// When the state was captured
+	TimeStamp metav1.Time `json:"time,omitempty"`
+}
+
+// StateDiffSummary summarizes the differences between a desired state and a
+// current state
+type StateDiffSummary struct {
+	// The number of differences found
+	Total int `json:"total"`
+
+	// The differences, bounded to EnactmentDiffLimit entries
+	Differences []StateDifference `json:"differences,omitempty"`
+}
+
+// StateDifference is a desired state path that has a different value
+// or is missing at the current state
+type StateDifference struct {
+	Path    string `json:"path"`
+	Desired string `json:"desired,omitempty"`
+	Current string `json:"current,omitempty"`
+}
+
+type NodeNetworkConfigurationEnactmentHistoryEntry struct {
+	// The policy generation that rendered the desired state
+	PolicyGeneration int64 `json:"policyGeneration"`
+
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// The desired state applied at the node
+	DesiredState State `json:"desiredState,omitempty"`
+
+	// When the desired state was applied
+	TimeStamp metav1.Time `json:"time,omitempty"`
+
+	// The nmstatectl output from applying the desired state
+	Output string `json:"output,omitempty"`
 }
 
 type NodeNetworkConfigurationEnactmentCapturedState struct {
@@ -59,11 +120,15 @@ type NodeNetworkConfigurationEnactmentMetaInfo struct {
 const (
 	EnactmentPolicyLabel                                                = "nmstate.io/policy"
 	EnactmentNodeLabel                                                  = "nmstate.io/node"
+	EnactmentRevertAnnotation                                           = "nmstate.io/revert-to-generation"
+	EnactmentHistoryLimit                                               = 10
+	EnactmentDiffLimit                                                  = 20
 	NodeNetworkConfigurationEnactmentConditionAvailable   ConditionType = "Available"
 	NodeNetworkConfigurationEnactmentConditionFailing     ConditionType = "Failing"
 	NodeNetworkConfigurationEnactmentConditionPending     ConditionType = "Pending"
 	NodeNetworkConfigurationEnactmentConditionProgressing ConditionType = "Progressing"
 	NodeNetworkConfigurationEnactmentConditionAborted     ConditionType = "Aborted"
+	NodeNetworkConfigurationEnactmentConditionDrifted     ConditionType = "Drifted"
 )
 
 var NodeNetworkConfigurationEnactmentConditionTypes = [...]ConditionType{
@@ -75,13 +140,55 @@ var NodeNetworkConfigurationEnactmentConditionTypes = [...]ConditionType{
 }
 
 const (
-	NodeNetworkConfigurationEnactmentConditionFailedToConfigure          ConditionReason = "FailedToConfigure"
-	NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured     ConditionReason = "SuccessfullyConfigured"
-	NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached ConditionReason = "MaxUnavailableLimitReached"
-	NodeNetworkConfigurationEnactmentConditionConfigurationProgressing   ConditionReason = "ConfigurationProgressing"
-	NodeNetworkConfigurationEnactmentConditionConfigurationAborted       ConditionReason = "ConfigurationAborted"
+	NodeNetworkConfigurationEnactmentConditionFailedToConfigure           ConditionReason = "FailedToConfigure"
+	NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured      ConditionReason = "SuccessfullyConfigured"
+	NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached  ConditionReason = "MaxUnavailableLimitReached"
+	NodeNetworkConfigurationEnactmentConditionWaitingForDependency        ConditionReason = "WaitingForDependency"
+	NodeNetworkConfigurationEnactmentConditionWaitingForRolloutWave       ConditionReason = "WaitingForRolloutWave"
+	NodeNetworkConfigurationEnactmentConditionRolloutPaused               ConditionReason = "RolloutPaused"
+	NodeNetworkConfigurationEnactmentConditionPaused                      ConditionReason = "Paused"
+	NodeNetworkConfigurationEnactmentConditionWaitingForMaintenanceWindow ConditionReason = "WaitingForMaintenanceWindow"
+	NodeNetworkConfigurationEnactmentConditionConfigurationProgressing    ConditionReason = "ConfigurationProgressing"
+	NodeNetworkConfigurationEnactmentConditionDrainingNode                ConditionReason = "DrainingNode"
+	NodeNetworkConfigurationEnactmentConditionFailedToDrain               ConditionReason = "FailedToDrain"
+	NodeNetworkConfigurationEnactmentConditionConfigurationAborted        ConditionReason = "ConfigurationAborted"
+	NodeNetworkConfigurationEnactmentConditionDryRunSucceeded             ConditionReason = "DryRunSucceeded"
+	NodeNetworkConfigurationEnactmentConditionDryRunFailed                ConditionReason = "DryRunFailed"
+	NodeNetworkConfigurationEnactmentConditionReverted                    ConditionReason = "Reverted"
+	NodeNetworkConfigurationEnactmentConditionFailedToRevert              ConditionReason = "FailedToRevert"
+	NodeNetworkConfigurationEnactmentConditionRolledBack                  ConditionReason = "RolledBack"
+	NodeNetworkConfigurationEnactmentConditionFailedToRollBack            ConditionReason = "FailedToRollBack"
+	NodeNetworkConfigurationEnactmentConditionDriftDetected               ConditionReason = "DriftDetected"
+	NodeNetworkConfigurationEnactmentConditionNoDriftDetected             ConditionReason = "NoDriftDetected"
 )
 
+// AppendHistory adds the entry at the head of the history dropping the
+// oldest entries past EnactmentHistoryLimit. Applying again the same
+// desired state of the same generation replaces the head entry instead, so
+// the reconciles re-applying it do not push the older generations out.
+func (s *NodeNetworkConfigurationEnactmentStatus) AppendHistory(entry NodeNetworkConfigurationEnactmentHistoryEntry) {
+	if len(s.History) > 0 && s.History[0].PolicyGeneration == entry.PolicyGeneration &&
+		bytes.Equal(s.History[0].DesiredState.Raw, entry.DesiredState.Raw) {
+		s.History[0] = entry
+		return
+	}
+	s.History = append([]NodeNetworkConfigurationEnactmentHistoryEntry{entry}, s.History...)
+	if len(s.History) > EnactmentHistoryLimit {
+		s.History = s.History[:EnactmentHistoryLimit]
+	}
+}
+
+// FindHistory returns the newest history entry applied for the policy
+// generation or nil if it is not there.
+func (s NodeNetworkConfigurationEnactmentStatus) FindHistory(policyGeneration int64) *NodeNetworkConfigurationEnactmentHistoryEntry {
+	for i := range s.History {
+		if s.History[i].PolicyGeneration == policyGeneration {
+			return &s.History[i]
+		}
+	}
+	return nil
+}
+
 func EnactmentKey(node, policy string) types.NamespacedName {
 	return types.NamespacedName{Name: fmt.Sprintf("%s.%s", node, policy)}
 }
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Enactment status history", func() {
+	var status NodeNetworkConfigurationEnactmentStatus
+	BeforeEach(func() {
+		status = NodeNetworkConfigurationEnactmentStatus{}
+	})
+	Context("when appending entries", func() {
+		BeforeEach(func() {
+			for generation := int64(1); generation <= EnactmentHistoryLimit+2; generation++ {
+				status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{PolicyGeneration: generation})
+			}
+		})
+		It("should keep the newest entries first", func() {
+			Expect(status.History[0].PolicyGeneration).To(Equal(int64(EnactmentHistoryLimit + 2)))
+		})
+		It("should drop the oldest entries past the limit", func() {
+			Expect(status.History).To(HaveLen(EnactmentHistoryLimit))
+			Expect(status.History[EnactmentHistoryLimit-1].PolicyGeneration).To(Equal(int64(3)))
+		})
+	})
+	Context("when appending the same desired state of the head generation", func() {
+		BeforeEach(func() {
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{PolicyGeneration: 1, DesiredState: NewState("interfaces: []")})
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{
+				PolicyGeneration: 2, DesiredState: NewState("interfaces: []"), Output: "first",
+			})
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{
+				PolicyGeneration: 2, DesiredState: NewState("interfaces: []"), Output: "second",
+			})
+		})
+		It("should replace the head entry", func() {
+			Expect(status.History).To(HaveLen(2))
+			Expect(status.History[0].Output).To(Equal("second"))
+			Expect(status.History[1].PolicyGeneration).To(Equal(int64(1)))
+		})
+		It("should add a new entry if the desired state differs", func() {
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{PolicyGeneration: 2, DesiredState: NewState("routes: {}")})
+			Expect(status.History).To(HaveLen(3))
+		})
+	})
+	Context("when finding an entry", func() {
+		BeforeEach(func() {
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{PolicyGeneration: 1, Output: "first"})
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{PolicyGeneration: 2})
+			status.AppendHistory(NodeNetworkConfigurationEnactmentHistoryEntry{PolicyGeneration: 1, Output: "reverted"})
+		})
+		It("should return the newest entry for the generation", func() {
+			entry := status.FindHistory(1)
+			Expect(entry).ToNot(BeNil())
+			Expect(entry.Output).To(Equal("reverted"))
+		})
+		It("should return nil for unknown generations", func() {
+			Expect(status.FindHistory(3)).To(BeNil())
+		})
+	})
+})
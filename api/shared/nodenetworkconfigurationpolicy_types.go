@@ -43,6 +43,212 @@ type NodeNetworkConfigurationPolicySpec struct {
 	// of machines that can be updating at a time. Default is "50%".
 	// +optional
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// DryRun when set renders the desired state at every matching node and
+	// checks it with nmstatectl, the configuration is rolled back right
+	// away instead of being committed.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Probes configures the connectivity checks run after applying the
+	// desired state and before committing it.
+	// +optional
+	Probes *NodeNetworkConfigurationPolicyProbes `json:"probes,omitempty"`
+
+	// DependsOn contains the names of the policies that have to be
+	// available at the node before applying this one.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Remediation configures what happens when the node configuration
+	// drifts from the applied desired state, with "Enforce" the policy is
+	// applied again. Default is "None", drift is only reported.
+	// +optional
+	Remediation RemediationMode `json:"remediation,omitempty"`
+
+	// Rollout configures a staged rollout of the policy, the matching nodes
+	// are split in waves that apply it one after the other.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+
+	// MaintenanceWindow restricts when the nodes can start applying the
+	// policy, outside of it they wait until the next window opens.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// DrainBeforeApply cordons the node and evicts its pods, respecting
+	// their PodDisruptionBudgets, before applying the policy. The node is
+	// uncordoned once the desired state is applied, if the policy cordoned
+	// it. It is meant for desired
+	// states disrupting the workload traffic, like moving the primary
+	// interface under a bridge.
+	// +optional
+	DrainBeforeApply bool `json:"drainBeforeApply,omitempty"`
+
+	// DrainTimeout is how long to wait for the pods to be evicted when
+	// drainBeforeApply is set. Default is "10m".
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyPausedAnnotation set to "true" stops the nodes
+// that have not started applying the policy from doing it, removing it
+// resumes the rollout. It can be changed at any time since it does not
+// modify the policy spec.
+const NodeNetworkConfigurationPolicyPausedAnnotation = "nmstate.io/paused"
+
+// NodeCordonedByPolicyAnnotation is set at the nodes cordoned by a policy
+// with drainBeforeApply, with the policy name and generation separated by a
+// slash. The node is only uncordoned by that policy, also after a handler
+// restart.
+const NodeCordonedByPolicyAnnotation = "nmstate.io/cordoned-by-policy"
+
+// NodeNetworkConfigurationPolicyOverrideProtectedAnnotation set to "true"
+// allows the policy to modify the interfaces and routes protected at the
+// NMState spec.
+const NodeNetworkConfigurationPolicyOverrideProtectedAnnotation = "nmstate.io/override-protected"
+
+// +kubebuilder:validation:Enum=None;Enforce
+type RemediationMode string
+
+const (
+	RemediationNone    RemediationMode = "None"
+	RemediationEnforce RemediationMode = "Enforce"
+)
+
+// RolloutStrategy contains the waves a policy is rolled out with, a wave
+// starts when the nodes of the previous waves are available and the soak
+// duration has passed. Nodes not selected by any wave are part of a last
+// implicit wave.
+type RolloutStrategy struct {
+	// Waves are the groups of nodes applying the policy, in order.
+	Waves []RolloutWave `json:"waves"`
+
+	// SoakDuration is the time to wait after a wave is available before
+	// starting the next one. Default is "0s".
+	// +optional
+	SoakDuration *metav1.Duration `json:"soakDuration,omitempty"`
+}
+
+// RolloutWave selects the nodes of a wave between the ones not selected by
+// previous waves, at least one of nodeSelector or nodes has to be set.
+type RolloutWave struct {
+	// Name identifies the wave at the policy status. Default is "wave-<index>".
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// NodeSelector selects the wave nodes by their labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Nodes is the number or percentage of the policy matching nodes that
+	// are part of the wave, nodes are taken in name order.
+	// +optional
+	Nodes *intstr.IntOrString `json:"nodes,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period of time the policy can be applied at
+type MaintenanceWindow struct {
+	// Schedule is a cron expression with the minute, hour, day of month,
+	// month and day of week the window opens at, for example "0 22 * * 1-5"
+	// opens it at 22:00 from Monday to Friday.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open after it opens.
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA name of the time zone the schedule is interpreted
+	// at, for example "Europe/Madrid". Default is "UTC".
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyProbes contains the probes configuration of a policy
+type NodeNetworkConfigurationPolicyProbes struct {
+	// DisableBuiltIn contains the names of the built-in probes that will not be run.
+	// +optional
+	DisableBuiltIn []BuiltInProbeName `json:"disableBuiltIn,omitempty"`
+
+	// Custom contains extra probes that have to succeed before committing
+	// the desired state.
+	// +optional
+	Custom []CustomProbe `json:"custom,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=ping;dns;api-server;node-readiness
+type BuiltInProbeName string
+
+const (
+	BuiltInProbePing          BuiltInProbeName = "ping"
+	BuiltInProbeDNS           BuiltInProbeName = "dns"
+	BuiltInProbeAPIServer     BuiltInProbeName = "api-server"
+	BuiltInProbeNodeReadiness BuiltInProbeName = "node-readiness"
+)
+
+var BuiltInProbeNames = [...]BuiltInProbeName{
+	BuiltInProbePing,
+	BuiltInProbeDNS,
+	BuiltInProbeAPIServer,
+	BuiltInProbeNodeReadiness,
+}
+
+// CustomProbe is a user defined probe, exactly one of ping, tcp, http or dns
+// has to be set.
+type CustomProbe struct {
+	// Name identifies the probe at logs and error messages.
+	Name string `json:"name"`
+
+	// Timeout is the time the probe is retried before failing. Default is "120s".
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// +optional
+	Ping *PingProbe `json:"ping,omitempty"`
+
+	// +optional
+	TCP *TCPProbe `json:"tcp,omitempty"`
+
+	// +optional
+	HTTP *HTTPProbe `json:"http,omitempty"`
+
+	// +optional
+	DNS *DNSProbe `json:"dns,omitempty"`
+}
+
+// PingProbe sends an ICMP echo request to an address
+type PingProbe struct {
+	// Address is the IP address to ping.
+	Address string `json:"address"`
+
+	// Interface is the interface used to send the ping.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+}
+
+// TCPProbe opens a TCP connection to host:port
+type TCPProbe struct {
+	Host string `json:"host"`
+	Port int32  `json:"port"`
+}
+
+// HTTPProbe sends a GET request to an URL
+type HTTPProbe struct {
+	URL string `json:"url"`
+
+	// ExpectedStatus is the HTTP status code the response must have. Default is 200.
+	// +optional
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+}
+
+// DNSProbe resolves a name
+type DNSProbe struct {
+	// Name is the host name to resolve.
+	Name string `json:"name"`
+
+	// Server is the name server to use, if empty the running name servers
+	// from the node are used.
+	// +optional
+	Server string `json:"server,omitempty"`
 }
 
 // NodeNetworkConfigurationPolicyStatus defines the observed state of NodeNetworkConfigurationPolicy
@@ -56,6 +262,53 @@ type NodeNetworkConfigurationPolicyStatus struct {
 	// LastUnavailableNodeCountUpdate is time of the last UnavailableNodeCount update
 	// +optional
 	LastUnavailableNodeCountUpdate *metav1.Time `json:"lastUnavailableNodeCountUpdate,omitempty" optional:"true"`
+
+	// Rollout reports the progress of a staged rollout
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty" optional:"true"`
+}
+
+// RolloutStatus contains the wave that is being applied
+type RolloutStatus struct {
+	// CurrentWave is the name of the wave being applied
+	CurrentWave string `json:"currentWave"`
+
+	// CurrentWaveIndex is the position of the wave being applied, starting at 0
+	CurrentWaveIndex int `json:"currentWaveIndex"`
+
+	// Waves is the number of waves, including the implicit last one
+	Waves int `json:"waves"`
+
+	// Paused is true when a wave has failed and next waves are not applied
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// PolicyGeneration is the policy generation the waves have been planned for
+	// +optional
+	PolicyGeneration int64 `json:"policyGeneration,omitempty"`
+
+	// Plan contains the nodes of every wave, it is kept for the policy
+	// generation so nodes joining or changing labels do not move between waves
+	// +optional
+	Plan []RolloutWaveStatus `json:"plan,omitempty"`
+}
+
+// RolloutWaveStatus contains the nodes of a wave and when it was applied
+type RolloutWaveStatus struct {
+	// Name of the wave
+	Name string `json:"name"`
+
+	// Nodes applying the policy at the wave
+	Nodes []string `json:"nodes"`
+
+	// StartTime is when the first node of the wave started applying the policy
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// AvailableTime is when all the nodes of the wave were first seen available,
+	// the next wave waits for the soak duration from it
+	// +optional
+	AvailableTime *metav1.Time `json:"availableTime,omitempty"`
 }
 
 const (
@@ -75,4 +328,8 @@ const (
 	NodeNetworkConfigurationPolicyConditionSuccessfullyConfigured      ConditionReason = "SuccessfullyConfigured"
 	NodeNetworkConfigurationPolicyConditionConfigurationProgressing    ConditionReason = "ConfigurationProgressing"
 	NodeNetworkConfigurationPolicyConditionConfigurationNoMatchingNode ConditionReason = "NoMatchingNode"
+	NodeNetworkConfigurationPolicyConditionDryRunSucceeded             ConditionReason = "DryRunSucceeded"
+	NodeNetworkConfigurationPolicyConditionDryRunFailed                ConditionReason = "DryRunFailed"
+	NodeNetworkConfigurationPolicyConditionPaused                      ConditionReason = "Paused"
+	NodeNetworkConfigurationPolicyConditionWaitingForMaintenanceWindow ConditionReason = "WaitingForMaintenanceWindow"
 )
@@ -0,0 +1,85 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+// PolicyTemplateLabel is the NodeNetworkConfigurationPolicyTemplate a policy
+// is rendered from
+const PolicyTemplateLabel = "nmstate.io/policy-template"
+
+// NodeNetworkConfigurationPolicyTemplateSpec defines a parameterized capture
+// and desired state
+type NodeNetworkConfigurationPolicyTemplateSpec struct {
+	// Parameters are the values the capture and desired state are rendered
+	// with, they are referenced as "parameters.<name>" between double curly
+	// braces.
+	// +optional
+	Parameters []NodeNetworkConfigurationPolicyTemplateParameter `json:"parameters,omitempty"`
+	// Capture contains the capture expressions of the rendered policies
+	// +optional
+	Capture map[string]string `json:"capture,omitempty"`
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// The desired state of the rendered policies
+	DesiredState State `json:"desiredState,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyTemplateParameter defines a template parameter
+type NodeNetworkConfigurationPolicyTemplateParameter struct {
+	// Name is the name the parameter is referenced with
+	Name string `json:"name"`
+	// Description of the parameter
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Default is the value of the parameter if the instance does not set it,
+	// parameters without default are required.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyTemplateInstanceSpec defines the template
+// and parameters rendering a policy
+type NodeNetworkConfigurationPolicyTemplateInstanceSpec struct {
+	// TemplateRef is the NodeNetworkConfigurationPolicyTemplate to render
+	TemplateRef NodeNetworkConfigurationPolicyTemplateRef `json:"templateRef"`
+	// Parameters are the values of the template parameters
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Policy is the spec of the rendered policy, its capture and desiredState
+	// are rendered from the template and cannot be set.
+	// +optional
+	Policy NodeNetworkConfigurationPolicySpec `json:"policy,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyTemplateRef references a NodeNetworkConfigurationPolicyTemplate
+type NodeNetworkConfigurationPolicyTemplateRef struct {
+	// Name of the NodeNetworkConfigurationPolicyTemplate
+	Name string `json:"name"`
+}
+
+// NodeNetworkConfigurationPolicyTemplateInstanceStatus defines the observed
+// state of NodeNetworkConfigurationPolicyTemplateInstance
+type NodeNetworkConfigurationPolicyTemplateInstanceStatus struct {
+	// ObservedGeneration is the instance generation rendered at the policy
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// TemplateGeneration is the template generation rendered at the policy
+	// +optional
+	TemplateGeneration int64 `json:"templateGeneration,omitempty"`
+	// Error is the failure rendering the template or updating the policy
+	// +optional
+	Error string `json:"error,omitempty"`
+}
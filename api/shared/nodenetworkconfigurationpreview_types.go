@@ -0,0 +1,47 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+// NodeNetworkConfigurationPreviewSpec defines the policy to render and the node to render it at
+type NodeNetworkConfigurationPreviewSpec struct {
+	// Node is the name of the node whose current state is used to render the policy.
+	Node string `json:"node"`
+	// Policy is the policy spec to render, only its capture and desiredState
+	// are used, nothing is applied.
+	Policy NodeNetworkConfigurationPolicySpec `json:"policy"`
+}
+
+// NodeNetworkConfigurationPreviewStatus defines the observed state of NodeNetworkConfigurationPreview
+type NodeNetworkConfigurationPreviewStatus struct {
+	// ObservedGeneration is the preview generation rendered at the status
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DesiredState is the desired state the node would apply, with the
+	// captured values resolved and the defaults applied.
+	// +optional
+	DesiredState State `json:"desiredState,omitempty"`
+	// CapturedStates are the states captured from the node current state
+	// +optional
+	CapturedStates map[string]NodeNetworkConfigurationEnactmentCapturedState `json:"capturedStates,omitempty"`
+	// Features are the nmstate features used by the desired state
+	// +optional
+	Features []string `json:"features,omitempty"`
+	// Error is the failure rendering the desired state
+	// +optional
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+// NodeNetworkConfigurationRollbackSpec defines the policy generation to roll back
+type NodeNetworkConfigurationRollbackSpec struct {
+	// Policy is the name of the policy to roll back.
+	Policy string `json:"policy"`
+
+	// PolicyGeneration is the policy generation to undo, every node that
+	// applied it goes back to the state it had before. Default is the policy
+	// generation at the time the rollback is processed.
+	// +optional
+	PolicyGeneration int64 `json:"policyGeneration,omitempty"`
+}
+
+// NodeNetworkConfigurationRollbackStatus defines the observed state of NodeNetworkConfigurationRollback,
+// the progress of every node is reported at its enactment for the policy.
+type NodeNetworkConfigurationRollbackStatus struct {
+	// PolicyGeneration is the policy generation being rolled back
+	// +optional
+	PolicyGeneration int64 `json:"policyGeneration,omitempty"`
+
+	// RolledBackNodes are the nodes back at the state they had before the policy generation
+	// +optional
+	RolledBackNodes []string `json:"rolledBackNodes,omitempty"`
+
+	// FailedNodes are the nodes that failed rolling back
+	// +optional
+	FailedNodes []string `json:"failedNodes,omitempty"`
+}
@@ -1,5 +1,4 @@
 //go:build !ignore_autogenerated
-// +build !ignore_autogenerated
 
 /*
 Copyright The Kubernetes NMState Authors.
@@ -21,7 +20,10 @@ limitations under the License.
 
 package shared
 
-import ()
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
@@ -61,10 +63,249 @@ func (in ConditionList) DeepCopy() ConditionList {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomProbe) DeepCopyInto(out *CustomProbe) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Ping != nil {
+		in, out := &in.Ping, &out.Ping
+		*out = new(PingProbe)
+		**out = **in
+	}
+	if in.TCP != nil {
+		in, out := &in.TCP, &out.TCP
+		*out = new(TCPProbe)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPProbe)
+		**out = **in
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSProbe)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomProbe.
+func (in *CustomProbe) DeepCopy() *CustomProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSProbe) DeepCopyInto(out *DNSProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSProbe.
+func (in *DNSProbe) DeepCopy() *DNSProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProbe) DeepCopyInto(out *HTTPProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProbe.
+func (in *HTTPProbe) DeepCopy() *HTTPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedNodeNetworkConfigurationPolicyStatus) DeepCopyInto(out *NamespacedNodeNetworkConfigurationPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(ConditionList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedNodeNetworkConfigurationPolicyStatus.
+func (in *NamespacedNodeNetworkConfigurationPolicyStatus) DeepCopy() *NamespacedNodeNetworkConfigurationPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedNodeNetworkConfigurationPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeIPAllocationSpec) DeepCopyInto(out *NodeIPAllocationSpec) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeIPAllocationSpec.
+func (in *NodeIPAllocationSpec) DeepCopy() *NodeIPAllocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeIPAllocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeIPPoolRange) DeepCopyInto(out *NodeIPPoolRange) {
+	*out = *in
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeIPPoolRange.
+func (in *NodeIPPoolRange) DeepCopy() *NodeIPPoolRange {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeIPPoolRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeIPPoolSpec) DeepCopyInto(out *NodeIPPoolSpec) {
+	*out = *in
+	in.Range.DeepCopyInto(&out.Range)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeIPPoolSpec.
+func (in *NodeIPPoolSpec) DeepCopy() *NodeIPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeIPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationEnactmentCapturedState) DeepCopyInto(out *NodeNetworkConfigurationEnactmentCapturedState) {
+	*out = *in
+	in.State.DeepCopyInto(&out.State)
+	in.MetaInfo.DeepCopyInto(&out.MetaInfo)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationEnactmentCapturedState.
+func (in *NodeNetworkConfigurationEnactmentCapturedState) DeepCopy() *NodeNetworkConfigurationEnactmentCapturedState {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationEnactmentCapturedState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationEnactmentHistoryEntry) DeepCopyInto(out *NodeNetworkConfigurationEnactmentHistoryEntry) {
+	*out = *in
+	in.DesiredState.DeepCopyInto(&out.DesiredState)
+	in.TimeStamp.DeepCopyInto(&out.TimeStamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationEnactmentHistoryEntry.
+func (in *NodeNetworkConfigurationEnactmentHistoryEntry) DeepCopy() *NodeNetworkConfigurationEnactmentHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationEnactmentHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationEnactmentMetaInfo) DeepCopyInto(out *NodeNetworkConfigurationEnactmentMetaInfo) {
+	*out = *in
+	in.TimeStamp.DeepCopyInto(&out.TimeStamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationEnactmentMetaInfo.
+func (in *NodeNetworkConfigurationEnactmentMetaInfo) DeepCopy() *NodeNetworkConfigurationEnactmentMetaInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationEnactmentMetaInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationEnactmentStateBeforeApply) DeepCopyInto(out *NodeNetworkConfigurationEnactmentStateBeforeApply) {
+	*out = *in
+	in.State.DeepCopyInto(&out.State)
+	in.TimeStamp.DeepCopyInto(&out.TimeStamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationEnactmentStateBeforeApply.
+func (in *NodeNetworkConfigurationEnactmentStateBeforeApply) DeepCopy() *NodeNetworkConfigurationEnactmentStateBeforeApply {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationEnactmentStateBeforeApply)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkConfigurationEnactmentStatus) DeepCopyInto(out *NodeNetworkConfigurationEnactmentStatus) {
 	*out = *in
 	in.DesiredState.DeepCopyInto(&out.DesiredState)
+	in.DesiredStateMetaInfo.DeepCopyInto(&out.DesiredStateMetaInfo)
+	if in.CapturedStates != nil {
+		in, out := &in.CapturedStates, &out.CapturedStates
+		*out = make(map[string]NodeNetworkConfigurationEnactmentCapturedState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(ConditionList, len(*in))
@@ -72,6 +313,28 @@ func (in *NodeNetworkConfigurationEnactmentStatus) DeepCopyInto(out *NodeNetwork
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]NodeNetworkConfigurationEnactmentHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(StateDiffSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StateBeforeApply != nil {
+		in, out := &in.StateBeforeApply, &out.StateBeforeApply
+		*out = new(NodeNetworkConfigurationEnactmentStateBeforeApply)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationEnactmentStatus.
@@ -84,6 +347,33 @@ func (in *NodeNetworkConfigurationEnactmentStatus) DeepCopy() *NodeNetworkConfig
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyProbes) DeepCopyInto(out *NodeNetworkConfigurationPolicyProbes) {
+	*out = *in
+	if in.DisableBuiltIn != nil {
+		in, out := &in.DisableBuiltIn, &out.DisableBuiltIn
+		*out = make([]BuiltInProbeName, len(*in))
+		copy(*out, *in)
+	}
+	if in.Custom != nil {
+		in, out := &in.Custom, &out.Custom
+		*out = make([]CustomProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyProbes.
+func (in *NodeNetworkConfigurationPolicyProbes) DeepCopy() *NodeNetworkConfigurationPolicyProbes {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyProbes)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkConfigurationPolicySpec) DeepCopyInto(out *NodeNetworkConfigurationPolicySpec) {
 	*out = *in
@@ -94,7 +384,44 @@ func (in *NodeNetworkConfigurationPolicySpec) DeepCopyInto(out *NodeNetworkConfi
 			(*out)[key] = val
 		}
 	}
+	if in.Capture != nil {
+		in, out := &in.Capture, &out.Capture
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	in.DesiredState.DeepCopyInto(&out.DesiredState)
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(NodeNetworkConfigurationPolicyProbes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		**out = **in
+	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicySpec.
@@ -117,6 +444,15 @@ func (in *NodeNetworkConfigurationPolicyStatus) DeepCopyInto(out *NodeNetworkCon
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastUnavailableNodeCountUpdate != nil {
+		in, out := &in.LastUnavailableNodeCountUpdate, &out.LastUnavailableNodeCountUpdate
+		*out = (*in).DeepCopy()
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyStatus.
@@ -129,6 +465,256 @@ func (in *NodeNetworkConfigurationPolicyStatus) DeepCopy() *NodeNetworkConfigura
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyTemplateInstanceSpec) DeepCopyInto(out *NodeNetworkConfigurationPolicyTemplateInstanceSpec) {
+	*out = *in
+	out.TemplateRef = in.TemplateRef
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Policy.DeepCopyInto(&out.Policy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyTemplateInstanceSpec.
+func (in *NodeNetworkConfigurationPolicyTemplateInstanceSpec) DeepCopy() *NodeNetworkConfigurationPolicyTemplateInstanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyTemplateInstanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyTemplateInstanceStatus) DeepCopyInto(out *NodeNetworkConfigurationPolicyTemplateInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyTemplateInstanceStatus.
+func (in *NodeNetworkConfigurationPolicyTemplateInstanceStatus) DeepCopy() *NodeNetworkConfigurationPolicyTemplateInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyTemplateInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyTemplateParameter) DeepCopyInto(out *NodeNetworkConfigurationPolicyTemplateParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyTemplateParameter.
+func (in *NodeNetworkConfigurationPolicyTemplateParameter) DeepCopy() *NodeNetworkConfigurationPolicyTemplateParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyTemplateParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyTemplateRef) DeepCopyInto(out *NodeNetworkConfigurationPolicyTemplateRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyTemplateRef.
+func (in *NodeNetworkConfigurationPolicyTemplateRef) DeepCopy() *NodeNetworkConfigurationPolicyTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyTemplateSpec) DeepCopyInto(out *NodeNetworkConfigurationPolicyTemplateSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]NodeNetworkConfigurationPolicyTemplateParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Capture != nil {
+		in, out := &in.Capture, &out.Capture
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.DesiredState.DeepCopyInto(&out.DesiredState)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyTemplateSpec.
+func (in *NodeNetworkConfigurationPolicyTemplateSpec) DeepCopy() *NodeNetworkConfigurationPolicyTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPreviewSpec) DeepCopyInto(out *NodeNetworkConfigurationPreviewSpec) {
+	*out = *in
+	in.Policy.DeepCopyInto(&out.Policy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPreviewSpec.
+func (in *NodeNetworkConfigurationPreviewSpec) DeepCopy() *NodeNetworkConfigurationPreviewSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPreviewSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPreviewStatus) DeepCopyInto(out *NodeNetworkConfigurationPreviewStatus) {
+	*out = *in
+	in.DesiredState.DeepCopyInto(&out.DesiredState)
+	if in.CapturedStates != nil {
+		in, out := &in.CapturedStates, &out.CapturedStates
+		*out = make(map[string]NodeNetworkConfigurationEnactmentCapturedState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPreviewStatus.
+func (in *NodeNetworkConfigurationPreviewStatus) DeepCopy() *NodeNetworkConfigurationPreviewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPreviewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationQuotaRoutes) DeepCopyInto(out *NodeNetworkConfigurationQuotaRoutes) {
+	*out = *in
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TableIDs != nil {
+		in, out := &in.TableIDs, &out.TableIDs
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationQuotaRoutes.
+func (in *NodeNetworkConfigurationQuotaRoutes) DeepCopy() *NodeNetworkConfigurationQuotaRoutes {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationQuotaRoutes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationQuotaSpec) DeepCopyInto(out *NodeNetworkConfigurationQuotaSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = new(NodeNetworkConfigurationQuotaRoutes)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationQuotaSpec.
+func (in *NodeNetworkConfigurationQuotaSpec) DeepCopy() *NodeNetworkConfigurationQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationRollbackSpec) DeepCopyInto(out *NodeNetworkConfigurationRollbackSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationRollbackSpec.
+func (in *NodeNetworkConfigurationRollbackSpec) DeepCopy() *NodeNetworkConfigurationRollbackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationRollbackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationRollbackStatus) DeepCopyInto(out *NodeNetworkConfigurationRollbackStatus) {
+	*out = *in
+	if in.RolledBackNodes != nil {
+		in, out := &in.RolledBackNodes, &out.RolledBackNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedNodes != nil {
+		in, out := &in.FailedNodes, &out.FailedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationRollbackStatus.
+func (in *NodeNetworkConfigurationRollbackStatus) DeepCopy() *NodeNetworkConfigurationRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkStateStatus) DeepCopyInto(out *NodeNetworkStateStatus) {
 	*out = *in
@@ -153,6 +739,21 @@ func (in *NodeNetworkStateStatus) DeepCopy() *NodeNetworkStateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PingProbe) DeepCopyInto(out *PingProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PingProbe.
+func (in *PingProbe) DeepCopy() *PingProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(PingProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in RawState) DeepCopyInto(out *RawState) {
 	{
@@ -172,6 +773,110 @@ func (in RawState) DeepCopy() RawState {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = make([]RolloutWaveStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]RolloutWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SoakDuration != nil {
+		in, out := &in.SoakDuration, &out.SoakDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutWave) DeepCopyInto(out *RolloutWave) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWave.
+func (in *RolloutWave) DeepCopy() *RolloutWave {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutWaveStatus) DeepCopyInto(out *RolloutWaveStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AvailableTime != nil {
+		in, out := &in.AvailableTime, &out.AvailableTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWaveStatus.
+func (in *RolloutWaveStatus) DeepCopy() *RolloutWaveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutWaveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *State) DeepCopyInto(out *State) {
 	*out = *in
@@ -191,3 +896,53 @@ func (in *State) DeepCopy() *State {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateDiffSummary) DeepCopyInto(out *StateDiffSummary) {
+	*out = *in
+	if in.Differences != nil {
+		in, out := &in.Differences, &out.Differences
+		*out = make([]StateDifference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateDiffSummary.
+func (in *StateDiffSummary) DeepCopy() *StateDiffSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(StateDiffSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateDifference) DeepCopyInto(out *StateDifference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateDifference.
+func (in *StateDifference) DeepCopy() *StateDifference {
+	if in == nil {
+		return nil
+	}
+	out := new(StateDifference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPProbe) DeepCopyInto(out *TCPProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPProbe.
+func (in *TCPProbe) DeepCopy() *TCPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -53,6 +53,36 @@ type NMStateSpec struct {
 	InfraTolerations []corev1.Toleration `json:"infraTolerations,omitempty"`
 	// SelfSignConfiguration defines self signed certificate configuration
 	SelfSignConfiguration *SelfSignConfiguration `json:"selfSignConfiguration,omitempty"`
+	// ProtectedInterfaces are the interfaces no policy can modify, unless it has the
+	// nmstate.io/override-protected annotation set to "true". The routes through them are protected too.
+	// +optional
+	ProtectedInterfaces []ProtectedInterface `json:"protectedInterfaces,omitempty"`
+	// ProtectedRoutes are the routes no policy can modify, unless it has the
+	// nmstate.io/override-protected annotation set to "true".
+	// +optional
+	ProtectedRoutes []ProtectedRoute `json:"protectedRoutes,omitempty"`
+}
+
+// ProtectedInterface is an interface no policy can modify, use as a port or
+// route through
+type ProtectedInterface struct {
+	// Name is the name of the interface, shell patterns like "eth*" are supported
+	Name string `json:"name"`
+	// NodeSelector limits the protection to the nodes with these labels, the
+	// interface is protected at every node if empty.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// ProtectedRoute is a route no policy can modify
+type ProtectedRoute struct {
+	// Destination is the destination of the route, shell patterns like
+	// "10.0.*" are supported and "*" also matches the "/" of the prefix.
+	Destination string `json:"destination"`
+	// NodeSelector limits the protection to the nodes with these labels, the
+	// route is protected at every node if empty.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 }
 
 type SelfSignConfiguration struct {
@@ -50,6 +50,18 @@ type NodeNetworkConfigurationPolicy struct {
 	Status shared.NodeNetworkConfigurationPolicyStatus `json:"status,omitempty"`
 }
 
+// IsPaused returns true if the policy rollout has been paused with the
+// paused annotation.
+func (n *NodeNetworkConfigurationPolicy) IsPaused() bool {
+	return n.Annotations[shared.NodeNetworkConfigurationPolicyPausedAnnotation] == "true"
+}
+
+// OverridesProtected returns true if the policy can modify the protected
+// interfaces and routes because of the override annotation.
+func (n *NodeNetworkConfigurationPolicy) OverridesProtected() bool {
+	return n.Annotations[shared.NodeNetworkConfigurationPolicyOverrideProtectedAnnotation] == "true"
+}
+
 func init() {
 	SchemeBuilder.Register(&NodeNetworkConfigurationPolicy{}, &NodeNetworkConfigurationPolicyList{})
 }
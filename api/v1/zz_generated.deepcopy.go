@@ -131,6 +131,20 @@ func (in *NMStateSpec) DeepCopyInto(out *NMStateSpec) {
 		*out = new(SelfSignConfiguration)
 		**out = **in
 	}
+	if in.ProtectedInterfaces != nil {
+		in, out := &in.ProtectedInterfaces, &out.ProtectedInterfaces
+		*out = make([]ProtectedInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProtectedRoutes != nil {
+		in, out := &in.ProtectedRoutes, &out.ProtectedRoutes
+		*out = make([]ProtectedRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NMStateSpec.
@@ -224,6 +238,50 @@ func (in *NodeNetworkConfigurationPolicyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtectedInterface) DeepCopyInto(out *ProtectedInterface) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtectedInterface.
+func (in *ProtectedInterface) DeepCopy() *ProtectedInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtectedInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtectedRoute) DeepCopyInto(out *ProtectedRoute) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtectedRoute.
+func (in *ProtectedRoute) DeepCopy() *ProtectedRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtectedRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelfSignConfiguration) DeepCopyInto(out *SelfSignConfiguration) {
 	*out = *in
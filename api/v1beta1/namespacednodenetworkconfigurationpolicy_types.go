@@ -0,0 +1,85 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// +kubebuilder:object:root=true
+
+// NamespacedNodeNetworkConfigurationPolicyList contains a list of NamespacedNodeNetworkConfigurationPolicy
+type NamespacedNodeNetworkConfigurationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedNodeNetworkConfigurationPolicy `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=namespacednodenetworkconfigurationpolicies,shortName=nnncp,scope=Namespaced
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.status==\"True\")].type",description="Status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.status==\"True\")].reason",description="Reason"
+// +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.error",description="Error"
+// +kubebuilder:storageversion
+
+// NamespacedNodeNetworkConfigurationPolicy is the Schema for the
+// namespacednodenetworkconfigurationpolicies API, a policy limited by the
+// NodeNetworkConfigurationQuotas of its namespace and applied at the nodes
+// as a cluster NodeNetworkConfigurationPolicy.
+type NamespacedNodeNetworkConfigurationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   shared.NodeNetworkConfigurationPolicySpec             `json:"spec,omitempty"`
+	Status shared.NamespacedNodeNetworkConfigurationPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkConfigurationQuotaList contains a list of NodeNetworkConfigurationQuota
+type NodeNetworkConfigurationQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkConfigurationQuota `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodenetworkconfigurationquotas,shortName=nncquota,scope=Cluster
+// +kubebuilder:storageversion
+
+// NodeNetworkConfigurationQuota is the Schema for the
+// nodenetworkconfigurationquotas API, it grants the namespaced policies of
+// some namespaces the nodes and interfaces they can configure.
+type NodeNetworkConfigurationQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec shared.NodeNetworkConfigurationQuotaSpec `json:"spec,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&NamespacedNodeNetworkConfigurationPolicy{}, &NamespacedNodeNetworkConfigurationPolicyList{},
+		&NodeNetworkConfigurationQuota{}, &NodeNetworkConfigurationQuotaList{},
+	)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// +kubebuilder:object:root=true
+
+// NodeIPPoolList contains a list of NodeIPPool
+type NodeIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeIPPool `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodeippools,shortName=nip,scope=Cluster
+// +kubebuilder:printcolumn:name="CIDR",type="string",JSONPath=".spec.range.cidr",description="CIDR"
+// +kubebuilder:storageversion
+
+// NodeIPPool is the Schema for the nodeippools API, it hands out unique
+// static addresses to the nodes referencing it from their policies.
+type NodeIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec shared.NodeIPPoolSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeIPAllocationList contains a list of NodeIPAllocation
+type NodeIPAllocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeIPAllocation `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodeipallocations,shortName=nipa,scope=Cluster
+// +kubebuilder:printcolumn:name="Pool",type="string",JSONPath=".spec.pool",description="Pool"
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.node",description="Node"
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".spec.address",description="Address"
+// +kubebuilder:storageversion
+
+// NodeIPAllocation is the Schema for the nodeipallocations API, it is an
+// address of a NodeIPPool held by a node. It is named after the pool and
+// the address so an address cannot be allocated twice, even if the pool
+// range changes.
+type NodeIPAllocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec shared.NodeIPAllocationSpec `json:"spec,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeIPPool{}, &NodeIPPoolList{}, &NodeIPAllocation{}, &NodeIPAllocationList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkConfigurationPolicyTemplateList contains a list of NodeNetworkConfigurationPolicyTemplate
+type NodeNetworkConfigurationPolicyTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkConfigurationPolicyTemplate `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodenetworkconfigurationpolicytemplates,shortName=nncpt,scope=Cluster
+// +kubebuilder:storageversion
+
+// NodeNetworkConfigurationPolicyTemplate is the Schema for the nodenetworkconfigurationpolicytemplates API,
+// a parameterized policy capture and desired state rendered by NodeNetworkConfigurationPolicyTemplateInstances.
+type NodeNetworkConfigurationPolicyTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec shared.NodeNetworkConfigurationPolicyTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkConfigurationPolicyTemplateInstanceList contains a list of NodeNetworkConfigurationPolicyTemplateInstance
+type NodeNetworkConfigurationPolicyTemplateInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkConfigurationPolicyTemplateInstance `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=nodenetworkconfigurationpolicytemplateinstances,shortName=nncpti,scope=Cluster
+// +kubebuilder:printcolumn:name="Template",type="string",JSONPath=".spec.templateRef.name",description="Template"
+// +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.error",description="Error"
+// +kubebuilder:storageversion
+
+// NodeNetworkConfigurationPolicyTemplateInstance is the Schema for the
+// nodenetworkconfigurationpolicytemplateinstances API, it renders a
+// NodeNetworkConfigurationPolicy with the same name from a template.
+type NodeNetworkConfigurationPolicyTemplateInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   shared.NodeNetworkConfigurationPolicyTemplateInstanceSpec   `json:"spec,omitempty"`
+	Status shared.NodeNetworkConfigurationPolicyTemplateInstanceStatus `json:"status,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&NodeNetworkConfigurationPolicyTemplate{}, &NodeNetworkConfigurationPolicyTemplateList{},
+		&NodeNetworkConfigurationPolicyTemplateInstance{}, &NodeNetworkConfigurationPolicyTemplateInstanceList{},
+	)
+}
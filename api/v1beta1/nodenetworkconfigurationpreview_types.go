@@ -0,0 +1,55 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkConfigurationPreviewList contains a list of NodeNetworkConfigurationPreview
+type NodeNetworkConfigurationPreviewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkConfigurationPreview `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=nodenetworkconfigurationpreviews,shortName=nncpreview,scope=Cluster
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.node",description="Node"
+// +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.error",description="Error"
+// +kubebuilder:storageversion
+
+// NodeNetworkConfigurationPreview is the Schema for the nodenetworkconfigurationpreviews API,
+// the handler at the node renders the policy against the node current state without applying it.
+type NodeNetworkConfigurationPreview struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   shared.NodeNetworkConfigurationPreviewSpec   `json:"spec,omitempty"`
+	Status shared.NodeNetworkConfigurationPreviewStatus `json:"status,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeNetworkConfigurationPreview{}, &NodeNetworkConfigurationPreviewList{})
+}
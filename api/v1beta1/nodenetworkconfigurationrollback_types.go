@@ -0,0 +1,55 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkConfigurationRollbackList contains a list of NodeNetworkConfigurationRollback
+type NodeNetworkConfigurationRollbackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkConfigurationRollback `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=nodenetworkconfigurationrollbacks,shortName=nncr,scope=Cluster
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=".spec.policy",description="Policy"
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".status.policyGeneration",description="Generation"
+// +kubebuilder:storageversion
+
+// NodeNetworkConfigurationRollback is the Schema for the nodenetworkconfigurationrollbacks API,
+// it reverts every node that applied a policy generation to the state it had before.
+type NodeNetworkConfigurationRollback struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   shared.NodeNetworkConfigurationRollbackSpec   `json:"spec,omitempty"`
+	Status shared.NodeNetworkConfigurationRollbackStatus `json:"status,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeNetworkConfigurationRollback{}, &NodeNetworkConfigurationRollbackList{})
+}
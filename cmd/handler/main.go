@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -30,11 +31,13 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -54,15 +57,23 @@ import (
 	nmstatev1alpha1 "github.com/nmstate/kubernetes-nmstate/api/v1alpha1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	controllers "github.com/nmstate/kubernetes-nmstate/controllers/handler"
+	controllersipam "github.com/nmstate/kubernetes-nmstate/controllers/ipam"
 	controllersmetrics "github.com/nmstate/kubernetes-nmstate/controllers/metrics"
+	controllerstemplating "github.com/nmstate/kubernetes-nmstate/controllers/templating"
+	controllerstenancy "github.com/nmstate/kubernetes-nmstate/controllers/tenancy"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
+	"github.com/nmstate/kubernetes-nmstate/pkg/events"
 	"github.com/nmstate/kubernetes-nmstate/pkg/file"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
 	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/webhook"
 )
 
-const generalExitStatus int = 1
+const (
+	generalExitStatus           int = 1
+	driftEnforcementsBufferSize     = 10
+)
 
 type ProfilerConfig struct {
 	EnableProfiler bool   `envconfig:"ENABLE_PROFILER"`
@@ -82,7 +93,7 @@ func init() {
 	utilruntime.Must(nmstatev1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 
-	metrics.Registry.MustRegister(monitoring.AppliedFeatures)
+	metrics.Registry.MustRegister(monitoring.Collectors()...)
 }
 
 func main() {
@@ -99,8 +110,14 @@ func mainHandler() int {
 	opt.BindFlags(flag.CommandLine)
 	var logType string
 	var dumpMetricFamilies bool
+	var nmstateBackend string
+	var ipPoolStore string
 	pflag.StringVar(&logType, "v", "production", "Log type (debug/production).")
 	pflag.BoolVar(&dumpMetricFamilies, "dump-metric-families", false, "Dump the prometheus metric families and exit.")
+	pflag.StringVar(&nmstateBackend, "nmstate-backend", nmstatectl.CommandBackendName,
+		fmt.Sprintf("Backend used by the handler to call nmstate (%s).", strings.Join(nmstatectl.Backends(), "/")))
+	pflag.StringVar(&ipPoolStore, "ippool-store", ippool.CRDStoreName,
+		fmt.Sprintf("Store of the addresses allocated from NodeIPPools (%s).", strings.Join(ippool.Stores(), "/")))
 	pflag.CommandLine.MarkDeprecated("v", "please use the --zap-devel flag for debug logging instead")
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
@@ -119,6 +136,11 @@ func mainHandler() int {
 	// webhook without problems, policy status will be updated
 	// by multiple instances.
 	if environment.IsHandler() {
+		if err := nmstatectl.UseBackend(nmstateBackend); err != nil {
+			setupLog.Error(err, "Failed to select nmstate backend")
+			return generalExitStatus
+		}
+		setupLog.Info("Using nmstate backend", "backend", nmstateBackend)
 		handlerLock, err := lockHandler()
 		if err != nil {
 			setupLog.Error(err, "Failed to run lockHandler")
@@ -127,14 +149,27 @@ func mainHandler() int {
 		defer handlerLock.Unlock()
 		setupLog.Info("Successfully took nmstate exclusive lock")
 	}
+	metricsBindAddress := ":8089"
+	if environment.IsHandler() {
+		// The handler runs with host network, bind the metrics to the
+		// loopback so they are only exposed through kube-rbac-proxy.
+		metricsBindAddress = "127.0.0.1:8089"
+	}
 	ctrlOptions := ctrl.Options{
 		Scheme:             scheme,
-		MetricsBindAddress: ":8089", // Explicitly enable metrics
+		MetricsBindAddress: metricsBindAddress, // Explicitly enable metrics
 	}
 
 	if environment.IsHandler() {
 		cacheResourcesOnNodes(&ctrlOptions)
 	}
+	if environment.IsWebhook() {
+		// The webhook deployment runs several replicas, the webhook server
+		// runs at all of them but the controllers only at the leader.
+		ctrlOptions.LeaderElection = true
+		ctrlOptions.LeaderElectionID = os.Getenv("OPERATOR_NAME") + "-webhook-controllers"
+		ctrlOptions.LeaderElectionNamespace = os.Getenv("POD_NAMESPACE")
+	}
 	setupLog.Info("Creating manager")
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrlOptions)
 	if err != nil {
@@ -156,12 +191,21 @@ func mainHandler() int {
 			setupLog.Error(err, "Cannot initialize webhook")
 			return generalExitStatus
 		}
+		if err = setupIPPoolController(mgr, ipPoolStore); err != nil {
+			return generalExitStatus
+		}
+		if err = setupPolicyTemplateController(mgr); err != nil {
+			return generalExitStatus
+		}
+		if err = setupNamespacedPolicyController(mgr); err != nil {
+			return generalExitStatus
+		}
 	} else if environment.IsMetricsManager() {
 		if err = setupMetricsManager(mgr); err != nil {
 			return generalExitStatus
 		}
 	} else if environment.IsHandler() {
-		if err = setupHandlerControllers(mgr); err != nil {
+		if err = setupHandlerControllers(mgr, ipPoolStore); err != nil {
 			return generalExitStatus
 		}
 		if err = checkNmstateIsWorking(); err != nil {
@@ -202,12 +246,15 @@ func cacheResourcesOnNodes(ctrlOptions *ctrl.Options) {
 	})
 }
 
-func setupHandlerControllers(mgr manager.Manager) error {
+func setupHandlerControllers(mgr manager.Manager, ipPoolStoreName string) error {
+	driftEnforcements := make(chan event.GenericEvent, driftEnforcementsBufferSize)
+
 	setupLog.Info("Creating Node controller")
 	if err := (&controllers.NodeReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Node"),
-		Scheme: mgr.GetScheme(),
+		Client:            mgr.GetClient(),
+		Log:               ctrl.Log.WithName("controllers").WithName("Node"),
+		Scheme:            mgr.GetScheme(),
+		DriftEnforcements: driftEnforcements,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create Node controller", "controller", "NMState")
 		return err
@@ -220,13 +267,33 @@ func setupHandlerControllers(mgr manager.Manager) error {
 		return err
 	}
 
+	ipPoolStore, err := ippool.NewStore(ipPoolStoreName, apiClient)
+	if err != nil {
+		setupLog.Error(err, "failed creating ip pool store")
+		return err
+	}
+
+	setupLog.Info("Creating kubernetes clientset")
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "failed creating kubernetes clientset")
+		return err
+	}
+
 	setupLog.Info("Creating NodeNetworkConfigurationPolicy controller")
 	if err = (&controllers.NodeNetworkConfigurationPolicyReconciler{
-		Client:    mgr.GetClient(),
-		APIClient: apiClient,
-		Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
-		Scheme:    mgr.GetScheme(),
-		Recorder:  mgr.GetEventRecorderFor(fmt.Sprintf("%s.nmstate-handler", environment.NodeName())),
+		Client:     mgr.GetClient(),
+		APIClient:  apiClient,
+		KubeClient: kubeClient,
+		Log:        ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
+		Scheme:     mgr.GetScheme(),
+		Recorder: events.NewRecorder(
+			mgr.GetEventRecorderFor(fmt.Sprintf("%s.nmstate-handler", environment.NodeName())),
+			events.DefaultQPS,
+			events.DefaultBurst,
+		),
+		DriftEnforcements: driftEnforcements,
+		IPPoolStore:       ipPoolStore,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create NodeNetworkConfigurationPolicy controller", "controller", "NMState")
 		return err
@@ -234,14 +301,38 @@ func setupHandlerControllers(mgr manager.Manager) error {
 
 	setupLog.Info("Creating NodeNetworkConfigurationEnactment controller")
 	if err = (&controllers.NodeNetworkConfigurationEnactmentReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationEnactment"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		APIClient: apiClient,
+		Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationEnactment"),
+		Scheme:    mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create NodeNetworkConfigurationEnactment controller", "controller", "NMState")
 		return err
 	}
 
+	setupLog.Info("Creating NodeNetworkConfigurationRollback controller")
+	if err = (&controllers.NodeNetworkConfigurationRollbackReconciler{
+		Client:    mgr.GetClient(),
+		APIClient: apiClient,
+		Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationRollback"),
+		Scheme:    mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NodeNetworkConfigurationRollback controller", "controller", "NMState")
+		return err
+	}
+
+	setupLog.Info("Creating NodeNetworkConfigurationPreview controller")
+	if err = (&controllers.NodeNetworkConfigurationPreviewReconciler{
+		Client:      mgr.GetClient(),
+		APIClient:   apiClient,
+		Log:         ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPreview"),
+		Scheme:      mgr.GetScheme(),
+		IPPoolStore: ipPoolStore,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NodeNetworkConfigurationPreview controller", "controller", "NMState")
+		return err
+	}
+
 	return nil
 }
 
@@ -321,6 +412,60 @@ func setupCertManager(mgr manager.Manager, certManagerOpts certificate.Options)
 	return nil
 }
 
+// setupIPPoolController runs with the webhook since the handlers of deleted
+// nodes are not there to release their addresses.
+func setupIPPoolController(mgr manager.Manager, ipPoolStoreName string) error {
+	apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "failed creating non cached client")
+		return err
+	}
+	ipPoolStore, err := ippool.NewStore(ipPoolStoreName, apiClient)
+	if err != nil {
+		setupLog.Error(err, "failed creating ip pool store")
+		return err
+	}
+
+	setupLog.Info("Creating NodeIPPool controller")
+	if err := (&controllersipam.NodeIPPoolReconciler{
+		Client:    mgr.GetClient(),
+		APIClient: apiClient,
+		Log:       ctrl.Log.WithName("controllers").WithName("NodeIPPool"),
+		Scheme:    mgr.GetScheme(),
+		Store:     ipPoolStore,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NodeIPPool controller", "controller", "NMState")
+		return err
+	}
+	return nil
+}
+
+func setupPolicyTemplateController(mgr manager.Manager) error {
+	setupLog.Info("Creating NodeNetworkConfigurationPolicyTemplateInstance controller")
+	if err := (&controllerstemplating.NodeNetworkConfigurationPolicyTemplateInstanceReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicyTemplateInstance"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NodeNetworkConfigurationPolicyTemplateInstance controller", "controller", "NMState")
+		return err
+	}
+	return nil
+}
+
+func setupNamespacedPolicyController(mgr manager.Manager) error {
+	setupLog.Info("Creating NamespacedNodeNetworkConfigurationPolicy controller")
+	if err := (&controllerstenancy.NamespacedNodeNetworkConfigurationPolicyReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("NamespacedNodeNetworkConfigurationPolicy"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NamespacedNodeNetworkConfigurationPolicy controller", "controller", "NMState")
+		return err
+	}
+	return nil
+}
+
 func setupMetricsManager(mgr manager.Manager) error {
 	setupLog.Info("Creating Metrics NodeNetworkConfigurationEnactment controller")
 	if err := (&controllersmetrics.NodeNetworkConfigurationEnactmentReconciler{
@@ -331,6 +476,26 @@ func setupMetricsManager(mgr manager.Manager) error {
 		setupLog.Error(err, "unable to create NodeNetworkConfigurationEnactment metrics controller", "metrics", "NMState")
 		return err
 	}
+
+	setupLog.Info("Creating Metrics NodeNetworkConfigurationPolicy controller")
+	if err := (&controllersmetrics.NodeNetworkConfigurationPolicyReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("metrics").WithName("NodeNetworkConfigurationPolicy"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NodeNetworkConfigurationPolicy metrics controller", "metrics", "NMState")
+		return err
+	}
+
+	setupLog.Info("Creating Metrics NodeNetworkState controller")
+	if err := (&controllersmetrics.NodeNetworkStateReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("metrics").WithName("NodeNetworkState"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create NodeNetworkState metrics controller", "metrics", "NMState")
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,139 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/client"
+	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+	"github.com/nmstate/kubernetes-nmstate/pkg/rollout"
+)
+
+// applyLock serializes the desired state applies done at the node by the
+// policy, enactment and rollback controllers. Every apply starts rolling back
+// any pending nmstate checkpoint, so two of them at the same time would
+// destroy each other checkpoint.
+var applyLock sync.Mutex
+
+func applyDesiredState(
+	cli client.Client,
+	desiredState nmstateapi.State,
+	probes *nmstateapi.NodeNetworkConfigurationPolicyProbes,
+	observer nmstate.ApplyObserver,
+) (string, error) {
+	applyLock.Lock()
+	defer applyLock.Unlock()
+	return applyDesiredStateFn(cli, desiredState, probes, observer)
+}
+
+// incrementUnavailableNodeCount takes one of the policy maxUnavailable slots
+// for this node, it returns node.MaxUnavailableLimitReachedError if they are
+// all taken.
+func incrementUnavailableNodeCount(
+	cli client.Client,
+	apiClient client.Reader,
+	log logr.Logger,
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	rolloutGate *rollout.Gate,
+) error {
+	policyKey := types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}
+	return retry.OnError(retry.DefaultRetry, func(error) bool { return true }, func() error {
+		err := cli.Get(context.TODO(), policyKey, policy)
+		if err != nil {
+			return err
+		}
+		maxUnavailable, err := maxUnavailableNodeCount(apiClient, policy, rolloutGate)
+		if err != nil {
+			log.Info(
+				fmt.Sprintf("failed calculating limit of max unavailable nodes, defaulting to %d, err: %s", maxUnavailable, err.Error()),
+			)
+		}
+		if policy.Status.UnavailableNodeCount >= maxUnavailable {
+			return node.MaxUnavailableLimitReachedError{}
+		}
+		policy.Status.LastUnavailableNodeCountUpdate = &metav1.Time{Time: time.Now()}
+		policy.Status.UnavailableNodeCount += 1
+		return cli.Status().Update(context.TODO(), policy)
+	})
+}
+
+// maxUnavailableNodeCount scales the policy MaxUnavailable to the nodes at
+// the rollout wave being applied, or to all the nodes if there is no rollout.
+func maxUnavailableNodeCount(
+	apiClient client.Reader,
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	rolloutGate *rollout.Gate,
+) (int, error) {
+	if rolloutGate == nil {
+		return node.MaxUnavailableNodeCount(apiClient, policy)
+	}
+	return node.MaxUnavailableWaveNodeCount(policy, len(rolloutGate.Waves[rolloutGate.Wave].Nodes))
+}
+
+// decrementUnavailableNodeCount releases the policy maxUnavailable slot
+// taken by incrementUnavailableNodeCount.
+func decrementUnavailableNodeCount(
+	cli client.Client,
+	apiClient client.Reader,
+	log logr.Logger,
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+) {
+	policyKey := types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}
+	err := tryDecrementingUnavailableNodeCount(cli, cli, policyKey)
+	if err != nil {
+		log.Error(err, "error decrementing unavailableNodeCount with cached client, trying again with non-cached client.")
+		err = tryDecrementingUnavailableNodeCount(cli, apiClient, policyKey)
+		if err != nil {
+			log.Error(err, "error decrementing unavailableNodeCount with non-cached client")
+		}
+	}
+}
+
+func tryDecrementingUnavailableNodeCount(
+	statusWriterClient client.StatusClient,
+	readerClient client.Reader,
+	policyKey types.NamespacedName,
+) error {
+	instance := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := retry.OnError(retry.DefaultRetry, func(error) bool { return true }, func() error {
+		err := readerClient.Get(context.TODO(), policyKey, instance)
+		if err != nil {
+			return err
+		}
+		if instance.Status.UnavailableNodeCount <= 0 {
+			return fmt.Errorf("no unavailable nodes")
+		}
+		instance.Status.LastUnavailableNodeCountUpdate = &metav1.Time{Time: time.Now()}
+		instance.Status.UnavailableNodeCount -= 1
+		return statusWriterClient.Status().Update(context.TODO(), instance)
+	})
+	return err
+}
@@ -19,12 +19,14 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -34,8 +36,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/client"
+	"github.com/nmstate/kubernetes-nmstate/pkg/diff"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/node"
 	"github.com/nmstate/kubernetes-nmstate/pkg/state"
@@ -61,6 +67,10 @@ type NodeReconciler struct {
 	lastState      shared.State
 	nmstateUpdater NmstateUpdater
 	nmstatectlShow NmstatectlShow
+
+	// DriftEnforcements receives the policies with "Enforce" remediation
+	// that have drifted so they are applied again.
+	DriftEnforcements chan<- event.GenericEvent
 }
 
 // Reconcile reads that state of the cluster for a Node object and makes changes based on the state read
@@ -69,6 +79,7 @@ type NodeReconciler struct {
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	refreshStart := time.Now()
 	currentStateRaw, err := r.nmstatectlShow()
 	if err != nil {
 		// We cannot call nmstatectl show let's reconcile again
@@ -80,6 +91,8 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	r.detectDrift(request.Name, shared.NewState(currentStateRaw))
+
 	nnsInstance := &nmstatev1beta1.NodeNetworkState{}
 	err = r.Client.Get(context.TODO(), request.NamespacedName, nnsInstance)
 	if err != nil {
@@ -115,12 +128,85 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	monitoring.NNSRefreshDuration.Observe(time.Since(refreshStart).Seconds())
+
 	// Cache currentState after successfully storing it at NodeNetworkState
 	r.lastState = currentState
 
 	return ctrl.Result{RequeueAfter: node.NetworkStateRefreshWithJitter()}, nil
 }
 
+// detectDrift compares the current state with the desired state of the
+// enactments successfully configured at the node, the ones from policies
+// with "Enforce" remediation are sent to be applied again.
+func (r *NodeReconciler) detectDrift(nodeName string, currentState shared.State) {
+	log := r.Log.WithName("detectDrift")
+	enactments := nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}
+	err := r.Client.List(context.TODO(), &enactments, client.MatchingLabels{shared.EnactmentNodeLabel: nodeName})
+	if err != nil {
+		log.Error(err, "failed listing enactments to detect drift")
+		return
+	}
+	for i := range enactments.Items {
+		enactmentInstance := &enactments.Items[i]
+		availableCondition := enactmentInstance.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionAvailable)
+		if availableCondition == nil || availableCondition.Status != corev1.ConditionTrue ||
+			availableCondition.Reason != shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured {
+			continue
+		}
+		differences, err := diff.Compare(enactmentInstance.Status.DesiredState, currentState)
+		if err != nil {
+			log.Error(err, "failed detecting drift", "enactment", enactmentInstance.Name)
+			continue
+		}
+		driftedPaths := diff.Paths(differences)
+		r.updateDriftedCondition(enactmentInstance, driftedPaths)
+		if len(driftedPaths) > 0 {
+			r.enforce(enactmentInstance)
+		}
+	}
+}
+
+func (r *NodeReconciler) updateDriftedCondition(
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+	driftedPaths []string,
+) {
+	driftedCondition := enactmentInstance.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionDrifted)
+	enactmentConditions := enactmentconditions.New(r.Client, types.NamespacedName{Name: enactmentInstance.Name})
+	if len(driftedPaths) == 0 {
+		if driftedCondition == nil || driftedCondition.Status != corev1.ConditionFalse {
+			enactmentConditions.NotifyNotDrifted()
+		}
+		return
+	}
+	if driftedCondition == nil || driftedCondition.Status != corev1.ConditionTrue ||
+		driftedCondition.Message != enactmentconditions.DriftedMessage(driftedPaths) {
+		r.Log.Info("drift detected", "enactment", enactmentInstance.Name, "paths", driftedPaths)
+		enactmentConditions.NotifyDrifted(driftedPaths)
+	}
+}
+
+func (r *NodeReconciler) enforce(enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment) {
+	if r.DriftEnforcements == nil {
+		return
+	}
+	policy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: enactmentInstance.Labels[shared.EnactmentPolicyLabel]}, policy)
+	if err != nil {
+		r.Log.Error(err, "failed getting policy to enforce it", "enactment", enactmentInstance.Name)
+		return
+	}
+	if policy.Spec.Remediation != shared.RemediationEnforce {
+		return
+	}
+	r.Log.Info("enforcing drifted policy", "policy", policy.Name)
+	select {
+	case r.DriftEnforcements <- event.GenericEvent{Object: policy}:
+	default:
+		r.Log.Info("drift enforcements queue is full, policy will be enforced at next refresh", "policy", policy.Name)
+	}
+}
+
 func (r *NodeReconciler) getDependencyVersions() *nmstate.DependencyVersions {
 	handlerNmstateVersion, err := nmstate.ExecuteCommand("nmstatectl", "--version")
 	if err != nil {
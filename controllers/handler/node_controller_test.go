@@ -34,10 +34,13 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	nmstatenode "github.com/nmstate/kubernetes-nmstate/pkg/node"
 	"github.com/nmstate/kubernetes-nmstate/pkg/state"
@@ -77,6 +80,11 @@ var _ = Describe("Node controller reconcile", func() {
 		s := scheme.Scheme
 		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
 			&nmstatev1beta1.NodeNetworkState{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+		)
+		s.AddKnownTypes(nmstatev1.GroupVersion,
+			&nmstatev1.NodeNetworkConfigurationPolicy{},
 		)
 
 		objs := []runtime.Object{&node, &nodenetworkstate}
@@ -236,4 +244,86 @@ routes:
 			})
 		})
 	})
+	Context("when there are successfully configured enactments at the node", func() {
+		var (
+			request           reconcile.Request
+			driftEnforcements chan event.GenericEvent
+			policy            nmstatev1.NodeNetworkConfigurationPolicy
+			enactment         nmstatev1beta1.NodeNetworkConfigurationEnactment
+		)
+		BeforeEach(func() {
+			request.Name = existingNodeName
+			driftEnforcements = make(chan event.GenericEvent, 1)
+			reconciler.DriftEnforcements = driftEnforcements
+
+			policy = nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "policy1",
+				},
+			}
+			enactment = nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: shared.EnactmentKey(existingNodeName, policy.Name).Name,
+					Labels: map[string]string{
+						shared.EnactmentNodeLabel:   existingNodeName,
+						shared.EnactmentPolicyLabel: policy.Name,
+					},
+				},
+				Status: shared.NodeNetworkConfigurationEnactmentStatus{
+					DesiredState: shared.NewState(`
+interfaces:
+  - name: eth1
+    state: up
+    mtu: 9000
+`),
+				},
+			}
+			enactmentconditions.SetSuccess(&enactment.Status.Conditions, "")
+		})
+		JustBeforeEach(func() {
+			Expect(cl.Create(context.TODO(), &policy)).To(Succeed())
+			Expect(cl.Create(context.TODO(), &enactment)).To(Succeed())
+		})
+		obtainDriftedCondition := func() *shared.Condition {
+			obtainedEnactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			ExpectWithOffset(1, cl.Get(context.TODO(), types.NamespacedName{Name: enactment.Name}, &obtainedEnactment)).To(Succeed())
+			return obtainedEnactment.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionDrifted)
+		}
+		Context("and the current state matches the desired state", func() {
+			BeforeEach(func() {
+				enactment.Status.DesiredState = shared.NewState(observedState)
+			})
+			It("should report the enactment as not drifted", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				driftedCondition := obtainDriftedCondition()
+				Expect(driftedCondition).ToNot(BeNil())
+				Expect(driftedCondition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(driftEnforcements).To(BeEmpty())
+			})
+		})
+		Context("and the current state has drifted", func() {
+			It("should report the enactment as drifted with the differing paths", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				driftedCondition := obtainDriftedCondition()
+				Expect(driftedCondition).ToNot(BeNil())
+				Expect(driftedCondition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(driftedCondition.Message).To(ContainSubstring("interfaces[eth1].mtu"))
+				Expect(driftEnforcements).To(BeEmpty())
+			})
+			Context("and policy remediation is Enforce", func() {
+				BeforeEach(func() {
+					policy.Spec.Remediation = shared.RemediationEnforce
+				})
+				It("should send the policy to be enforced", func() {
+					_, err := reconciler.Reconcile(context.Background(), request)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(driftEnforcements).To(Receive(WithTransform(func(e event.GenericEvent) string {
+						return e.Object.GetName()
+					}, Equal(policy.Name))))
+				})
+			})
+		})
+	})
 })
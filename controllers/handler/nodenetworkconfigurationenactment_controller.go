@@ -19,6 +19,9 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -35,13 +38,21 @@ import (
 	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactment"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/node"
 )
 
+var revertRetryTime = 5 * time.Second
+
 // NodeReconciler reconciles a Node object
 type NodeNetworkConfigurationEnactmentReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	// APIClient controller-runtime client without cache, used to update
+	// the enactment status and by the probes when reverting.
+	APIClient client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
 }
 
 // Reconcile reads that state of the cluster for a NodeNetworkConfigurationEnactment object and makes cleanup
@@ -80,21 +91,101 @@ func (r *NodeNetworkConfigurationEnactmentReconciler) Reconcile(ctx context.Cont
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
+
+	if _, revertRequested := enactmentInstance.Annotations[shared.EnactmentRevertAnnotation]; revertRequested {
+		if enactmentstatus.IsProgressing(&enactmentInstance.Status.Conditions) {
+			log.Info("Enactment is progressing, postponing revert")
+			return ctrl.Result{RequeueAfter: revertRetryTime}, nil
+		}
+		err = incrementUnavailableNodeCount(r.Client, r.APIClient, r.Log, policyInstance, nil)
+		if err != nil {
+			if apierrors.IsConflict(err) || errors.Is(err, node.MaxUnavailableLimitReachedError{}) {
+				log.Info("Policy maxUnavailable limit reached, postponing revert")
+				return ctrl.Result{RequeueAfter: revertRetryTime}, nil
+			}
+			log.Error(err, "Error taking a policy maxUnavailable slot for the revert")
+			return ctrl.Result{}, err
+		}
+		r.revert(enactmentInstance, policyInstance)
+		decrementUnavailableNodeCount(r.Client, r.APIClient, r.Log, policyInstance)
+		err = r.removeRevertAnnotation(enactmentInstance)
+		if err != nil {
+			log.Error(err, "Error removing revert annotation")
+			return ctrl.Result{}, err
+		}
+	}
 	return ctrl.Result{RequeueAfter: enactment.RefreshWithJitter()}, nil
 }
 
+// revert re-applies the history entry selected by the revert annotation
+// using the same probes and rollback as the policy apply, serialized with
+// the other applies at the node.
+func (r *NodeNetworkConfigurationEnactmentReconciler) revert(
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+) {
+	log := r.Log.WithName("revert").WithValues("enactment", enactmentInstance.Name)
+	enactmentKey := types.NamespacedName{Name: enactmentInstance.Name}
+	enactmentConditions := enactmentconditions.New(r.APIClient, enactmentKey)
+
+	revertTo := enactmentInstance.Annotations[shared.EnactmentRevertAnnotation]
+	policyGeneration, err := strconv.ParseInt(revertTo, 10, 64)
+	if err != nil {
+		enactmentConditions.NotifyFailedToRevert(fmt.Errorf("invalid %s annotation %q: %v", shared.EnactmentRevertAnnotation, revertTo, err))
+		return
+	}
+
+	historyEntry := enactmentInstance.Status.FindHistory(policyGeneration)
+	if historyEntry == nil {
+		enactmentConditions.NotifyFailedToRevert(fmt.Errorf("policy generation %d not found at enactment history", policyGeneration))
+		return
+	}
+
+	log.Info("reverting desired state", "policyGeneration", policyGeneration)
+	enactmentConditions.NotifyProgressing()
+	nmstateOutput, err := applyDesiredState(r.APIClient, historyEntry.DesiredState, policy.Spec.Probes, nil)
+	if err != nil {
+		errmsg := fmt.Errorf("error reverting to policy generation %d on node %s: %q,\n %v",
+			policyGeneration, nodeName, nmstateOutput, err)
+		enactmentConditions.NotifyFailedToRevert(errmsg)
+		log.Error(errmsg, "revert failed")
+		return
+	}
+	log.Info("nmstate", "output", nmstateOutput)
+
+	enactmentConditions.NotifyReverted(policyGeneration)
+
+	err = enactmentstatus.AppendHistory(r.APIClient, enactmentKey, shared.NodeNetworkConfigurationEnactmentHistoryEntry{
+		PolicyGeneration: policyGeneration,
+		DesiredState:     historyEntry.DesiredState,
+		Output:           nmstateOutput,
+	})
+	if err != nil {
+		log.Error(err, "failed recording reverted desired state at enactment history")
+	}
+}
+
+func (r *NodeNetworkConfigurationEnactmentReconciler) removeRevertAnnotation(
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+) error {
+	patch := client.MergeFrom(enactmentInstance.DeepCopy())
+	delete(enactmentInstance.Annotations, shared.EnactmentRevertAnnotation)
+	return r.Client.Patch(context.TODO(), enactmentInstance, patch)
+}
+
 func (r *NodeNetworkConfigurationEnactmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// By default all this functors return true so controller watch all events,
-	// but we only want to watch create for current node.
-	onCreationForThisEnactment := predicate.Funcs{
+	// but we only want to watch create for current node and revert requests.
+	onCreationOrRevertForThisEnactment := predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
 			return true
 		},
 		DeleteFunc: func(event.DeleteEvent) bool {
 			return false
 		},
-		UpdateFunc: func(event.UpdateEvent) bool {
-			return false
+		UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+			revertTo, revertRequested := updateEvent.ObjectNew.GetAnnotations()[shared.EnactmentRevertAnnotation]
+			return revertRequested && updateEvent.ObjectOld.GetAnnotations()[shared.EnactmentRevertAnnotation] != revertTo
 		},
 		GenericFunc: func(event.GenericEvent) bool {
 			return false
@@ -103,7 +194,7 @@ func (r *NodeNetworkConfigurationEnactmentReconciler) SetupWithManager(mgr ctrl.
 
 	err := ctrl.NewControllerManagedBy(mgr).
 		For(&nmstatev1beta1.NodeNetworkConfigurationEnactment{}).
-		WithEventFilter(onCreationForThisEnactment).
+		WithEventFilter(onCreationOrRevertForThisEnactment).
 		Complete(r)
 	if err != nil {
 		return errors.Wrap(err, "failed to add controller to NNCE Reconciler")
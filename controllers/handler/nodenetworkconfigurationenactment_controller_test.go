@@ -19,6 +19,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -36,9 +37,23 @@ import (
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/client"
 	nmstateenactment "github.com/nmstate/kubernetes-nmstate/pkg/enactment"
 )
 
+func withRevertAnnotation(
+	enactment nmstatev1beta1.NodeNetworkConfigurationEnactment,
+	revertTo string,
+) nmstatev1beta1.NodeNetworkConfigurationEnactment {
+	enactment = *enactment.DeepCopy()
+	enactment.Annotations = map[string]string{shared.EnactmentRevertAnnotation: revertTo}
+	enactment.Status.History = []shared.NodeNetworkConfigurationEnactmentHistoryEntry{
+		{PolicyGeneration: 2, DesiredState: shared.NewState("interfaces: [{name: eth1}]")},
+		{PolicyGeneration: 1, DesiredState: shared.NewState("interfaces: [{name: eth0}]")},
+	}
+	return enactment
+}
+
 var _ = Describe("Node Network Configuration Enactment controller reconcile", func() {
 	var (
 		cl         client.Client
@@ -86,6 +101,7 @@ var _ = Describe("Node Network Configuration Enactment controller reconcile", fu
 		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
 
 		reconciler.Client = cl
+		reconciler.APIClient = cl
 		reconciler.Log = ctrl.Log.WithName("controllers").WithName("Enactment")
 		reconciler.Scheme = s
 	})
@@ -136,4 +152,126 @@ var _ = Describe("Node Network Configuration Enactment controller reconcile", fu
 			Expect(result).To(Equal(reconcile.Result{}))
 		})
 	})
+	Context("and revert is requested", func() {
+		var (
+			request      reconcile.Request
+			appliedState shared.State
+			applyErr     error
+			revertTo     string
+		)
+		BeforeEach(func() {
+			request.Name = enactment.Name
+			revertTo = "1"
+			appliedState = shared.State{}
+			applyErr = nil
+			applyDesiredStateFn = func(
+				_ client.Client, desiredState shared.State, _ *shared.NodeNetworkConfigurationPolicyProbes, _ nmstate.ApplyObserver,
+			) (string, error) {
+				appliedState = desiredState
+				return "applied", applyErr
+			}
+			DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+		})
+		JustBeforeEach(func() {
+			By("Request the revert at the enactment")
+			revertedEnactment := withRevertAnnotation(enactment, revertTo)
+			Expect(cl.Update(context.TODO(), &revertedEnactment)).To(Succeed())
+		})
+		obtainEnactment := func() nmstatev1beta1.NodeNetworkConfigurationEnactment {
+			obtainedEnactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			ExpectWithOffset(1, cl.Get(context.TODO(), types.NamespacedName{Name: enactment.Name}, &obtainedEnactment)).To(Succeed())
+			return obtainedEnactment
+		}
+		It("should apply the history entry and mark the enactment as reverted", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(appliedState.Raw)).To(MatchYAML("interfaces: [{name: eth0}]"))
+
+			obtainedEnactment := obtainEnactment()
+			Expect(obtainedEnactment.Annotations).ToNot(HaveKey(shared.EnactmentRevertAnnotation))
+			availableCondition := obtainedEnactment.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionAvailable)
+			Expect(availableCondition).ToNot(BeNil())
+			Expect(availableCondition.Reason).To(Equal(shared.NodeNetworkConfigurationEnactmentConditionReverted))
+			Expect(obtainedEnactment.Status.History).To(HaveLen(3))
+			Expect(obtainedEnactment.Status.History[0].PolicyGeneration).To(Equal(int64(1)))
+			Expect(obtainedEnactment.Status.History[0].Output).To(Equal("applied"))
+		})
+		It("should release the policy maxUnavailable slot after reverting", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			obtainedPolicy := nmstatev1.NodeNetworkConfigurationPolicy{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: policy.Name}, &obtainedPolicy)).To(Succeed())
+			Expect(obtainedPolicy.Status.UnavailableNodeCount).To(Equal(0))
+			Expect(obtainedPolicy.Status.LastUnavailableNodeCountUpdate).ToNot(BeNil())
+		})
+		Context("and the policy maxUnavailable limit is reached", func() {
+			BeforeEach(func() {
+				unavailablePolicy := nmstatev1.NodeNetworkConfigurationPolicy{}
+				Expect(cl.Get(context.TODO(), types.NamespacedName{Name: policy.Name}, &unavailablePolicy)).To(Succeed())
+				unavailablePolicy.Status.UnavailableNodeCount = 1
+				Expect(cl.Status().Update(context.TODO(), &unavailablePolicy)).To(Succeed())
+			})
+			It("should postpone the revert", func() {
+				result, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: revertRetryTime}))
+				Expect(appliedState).To(Equal(shared.State{}))
+				Expect(obtainEnactment().Annotations).To(HaveKey(shared.EnactmentRevertAnnotation))
+			})
+		})
+		Context("and another desired state is being applied at the node", func() {
+			It("should wait for it to finish before reverting", func() {
+				applyLock.Lock()
+				locked := true
+				DeferCleanup(func() {
+					if locked {
+						applyLock.Unlock()
+					}
+				})
+				reverted := make(chan error)
+				go func() {
+					defer GinkgoRecover()
+					_, err := reconciler.Reconcile(context.Background(), request)
+					reverted <- err
+				}()
+				Consistently(reverted, "200ms").ShouldNot(Receive())
+				locked = false
+				applyLock.Unlock()
+				Eventually(reverted).Should(Receive(BeNil()))
+				Expect(string(appliedState.Raw)).To(MatchYAML("interfaces: [{name: eth0}]"))
+			})
+		})
+		Context("and apply fails", func() {
+			BeforeEach(func() {
+				applyErr = fmt.Errorf("probes failed")
+			})
+			It("should mark the enactment as failed to revert", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+
+				obtainedEnactment := obtainEnactment()
+				Expect(obtainedEnactment.Annotations).ToNot(HaveKey(shared.EnactmentRevertAnnotation))
+				failingCondition := obtainedEnactment.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionFailing)
+				Expect(failingCondition).ToNot(BeNil())
+				Expect(failingCondition.Reason).To(Equal(shared.NodeNetworkConfigurationEnactmentConditionFailedToRevert))
+				Expect(obtainedEnactment.Status.History).To(HaveLen(2))
+			})
+		})
+		Context("and the generation is not at the history", func() {
+			BeforeEach(func() {
+				revertTo = "5"
+			})
+			It("should not apply anything and mark the enactment as failed to revert", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(appliedState).To(Equal(shared.State{}))
+
+				failingCondition := obtainEnactment().Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionFailing)
+				Expect(failingCondition).ToNot(BeNil())
+				Expect(failingCondition.Reason).To(Equal(shared.NodeNetworkConfigurationEnactmentConditionFailedToRevert))
+				Expect(failingCondition.Message).To(ContainSubstring("policy generation 5 not found"))
+			})
+		})
+	})
 })
@@ -21,11 +21,12 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,6 +43,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
@@ -49,25 +51,37 @@ import (
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	"github.com/nmstate/kubernetes-nmstate/pkg/bridge"
 	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/client"
+	"github.com/nmstate/kubernetes-nmstate/pkg/diff"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactment"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
 	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
+	"github.com/nmstate/kubernetes-nmstate/pkg/events"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+	"github.com/nmstate/kubernetes-nmstate/pkg/maintenance"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmpolicy"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nodetemplate"
 	"github.com/nmstate/kubernetes-nmstate/pkg/policyconditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
+	"github.com/nmstate/kubernetes-nmstate/pkg/protection"
+	"github.com/nmstate/kubernetes-nmstate/pkg/rollback"
+	"github.com/nmstate/kubernetes-nmstate/pkg/rollout"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
 	"github.com/nmstate/kubernetes-nmstate/pkg/selectors"
 )
 
 const (
-	ReconcileFailed = "ReconcileFailed"
+	ReconcileFailed = events.ReasonReconcileFailed
 )
 
 var (
 	nodeName                                        string
 	nodeRunningUpdateRetryTime                      = 5 * time.Second
+	dependencyRetryTime                             = 10 * time.Second
+	rolloutRetryTime                                = 10 * time.Second
 	onCreateOrUpdateWithDifferentGenerationOrDelete = predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
 			return true
@@ -78,7 +92,9 @@ var (
 		UpdateFunc: func(updateEvent event.UpdateEvent) bool {
 			// [1] https://blog.openshift.com/kubernetes-operators-best-practices/
 			generationIsDifferent := updateEvent.ObjectNew.GetGeneration() != updateEvent.ObjectOld.GetGeneration()
-			return generationIsDifferent
+			pausedIsDifferent := updateEvent.ObjectNew.GetAnnotations()[nmstateapi.NodeNetworkConfigurationPolicyPausedAnnotation] !=
+				updateEvent.ObjectOld.GetAnnotations()[nmstateapi.NodeNetworkConfigurationPolicyPausedAnnotation]
+			return generationIsDifferent || pausedIsDifferent
 		},
 	}
 
@@ -97,7 +113,9 @@ var (
 			return false
 		},
 	}
-	nmstatectlShowFn = nmstatectl.Show
+	nmstatectlShowFn    = nmstatectl.Show
+	applyDesiredStateFn = nmstate.ApplyDesiredState
+	drainNodeFn         = node.Drain
 )
 
 // NodeNetworkConfigurationPolicyReconciler reconciles a NodeNetworkConfigurationPolicy object
@@ -106,9 +124,19 @@ type NodeNetworkConfigurationPolicyReconciler struct {
 	// APIClient controller-runtime client without cache, it will be used at
 	// places where whole cluster resources need to be retrieved but not cached.
 	APIClient client.Client
-	Log       logr.Logger
-	Scheme    *runtime.Scheme
-	Recorder  record.EventRecorder
+	// KubeClient is used to evict the node pods when the policy has
+	// drainBeforeApply.
+	KubeClient kubernetes.Interface
+	Log        logr.Logger
+	Scheme     *runtime.Scheme
+	// Recorder emits the enactment events at the policy and the node.
+	Recorder *events.Recorder
+	// DriftEnforcements receives the drifted policies that have to be
+	// applied again.
+	DriftEnforcements <-chan event.GenericEvent
+	// IPPoolStore keeps the addresses allocated to the node from the
+	// NodeIPPools referenced by the policies.
+	IPPoolStore ippool.Store
 }
 
 func init() {
@@ -169,14 +197,52 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(_ context.Context,
 		return ctrl.Result{}, err
 	}
 
+	if instance.IsPaused() {
+		return r.pause(instance)
+	}
+
+	rolledBack, err := r.isRolledBack(instance)
+	if err != nil {
+		log.Error(err, "Error checking if policy has been rolled back")
+		return ctrl.Result{}, err
+	}
+	if rolledBack {
+		log.Info("Policy generation has been rolled back at the node, not applying it")
+		return ctrl.Result{}, nil
+	}
+
+	reverted, err := r.isReverted(instance)
+	if err != nil {
+		log.Error(err, "Error checking if policy has been reverted")
+		return ctrl.Result{}, err
+	}
+	if reverted {
+		log.Info("Policy generation has been reverted at the node, not applying it")
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Spec.MaintenanceWindow != nil && !instance.Spec.DryRun {
+		window, err := maintenance.Evaluate(instance.Spec.MaintenanceWindow, time.Now())
+		if err != nil {
+			log.Error(err, "Error evaluating policy maintenance window")
+			return ctrl.Result{}, err
+		}
+		if !window.Open {
+			return r.waitForMaintenanceWindow(instance, window)
+		}
+	}
+
 	enactmentInstance, err := r.initializeEnactment(instance)
 	previousConditions := &enactmentInstance.Status.Conditions
 	if err != nil {
 		log.Error(err, "Error initializing enactment")
 		return ctrl.Result{}, err
 	}
+	previouslyAvailable := isEnactmentAvailable(enactmentInstance, instance.Generation)
 
-	enactmentConditions := enactmentconditions.New(r.APIClient, nmstateapi.EnactmentKey(nodeName, instance.Name))
+	enactmentEvents := events.ForEnactment(r.Recorder, instance, nodeName)
+	enactmentConditions := enactmentconditions.New(r.APIClient, nmstateapi.EnactmentKey(nodeName, instance.Name)).
+		WithEvents(enactmentEvents)
 
 	err = r.fillInEnactmentStatus(instance, enactmentInstance, enactmentConditions)
 	if err != nil {
@@ -193,20 +259,56 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(_ context.Context,
 		return ctrl.Result{}, err
 	}
 
+	var rolloutGate *rollout.Gate
+	if instance.Spec.Rollout != nil && !instance.Spec.DryRun {
+		rolloutGate, err = rollout.Evaluate(r.APIClient, instance, nodeName)
+		if err != nil {
+			log.Error(err, "Error evaluating policy rollout")
+			return ctrl.Result{}, err
+		}
+		if rolloutGate.Paused {
+			enactmentConditions.NotifyRolloutPaused(rolloutGate.Message)
+			log.Info(rolloutGate.Message)
+			r.updateRolloutStatus(instance, rolloutGate)
+			return ctrl.Result{}, nil
+		}
+		if !rolloutGate.Open {
+			enactmentConditions.NotifyWaitingForRolloutWave(rolloutGate.Message)
+			log.Info(rolloutGate.Message)
+			r.updateRolloutStatus(instance, rolloutGate)
+			if rolloutGate.RequeueAfter > 0 {
+				return ctrl.Result{RequeueAfter: rolloutGate.RequeueAfter}, nil
+			}
+			return ctrl.Result{RequeueAfter: rolloutRetryTime}, nil
+		}
+	}
+
 	_, enactmentCountByCondition, err := enactment.CountByPolicy(r.APIClient, instance)
 	if err != nil {
 		log.Error(err, "Error getting enactment counts")
 		return ctrl.Result{}, err
 	}
-	if enactmentCountByCondition.Failed() > 0 {
+	if !instance.Spec.DryRun && enactmentCountByCondition.Failed() > 0 {
 		err = fmt.Errorf("policy has failing enactments, aborting")
 		log.Error(err, "")
 		enactmentConditions.NotifyAborted(err)
 		return ctrl.Result{}, nil
 	}
 
+	unavailableDependencies, err := enactment.UnavailableDependencies(r.APIClient, nodeName, instance)
+	if err != nil {
+		log.Error(err, "Error checking policy dependencies")
+		return ctrl.Result{}, err
+	}
+	if len(unavailableDependencies) > 0 {
+		message := fmt.Sprintf("Waiting for policies %s to be available at the node", strings.Join(unavailableDependencies, ", "))
+		enactmentConditions.NotifyWaitingForDependency(message)
+		log.Info(message)
+		return ctrl.Result{RequeueAfter: dependencyRetryTime}, nil
+	}
+
 	if r.shouldIncrementUnavailableNodeCount(instance, previousConditions) {
-		err = r.incrementUnavailableNodeCount(instance)
+		err = incrementUnavailableNodeCount(r.Client, r.APIClient, r.Log, instance, rolloutGate)
 		if err != nil {
 			if apierrors.IsConflict(err) || errors.Is(err, node.MaxUnavailableLimitReachedError{}) {
 				enactmentConditions.NotifyPending()
@@ -215,34 +317,324 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(_ context.Context,
 			}
 			return ctrl.Result{}, err
 		}
+		observeMaxUnavailablePending(previousConditions)
+	}
+	defer decrementUnavailableNodeCount(r.Client, r.APIClient, r.Log, instance)
+
+	if rolloutGate != nil {
+		r.updateRolloutStatus(instance, rolloutGate)
 	}
-	defer r.decrementUnavailableNodeCount(instance)
 
 	enactmentConditions.NotifyProgressing()
 	if policyconditions.IsUnknown(&instance.Status.Conditions) {
 		policyconditions.Update(r.Client, r.APIClient, request.NamespacedName)
 	}
 
-	nmstateOutput, err := nmstate.ApplyDesiredState(r.APIClient, enactmentInstance.Status.DesiredState)
+	if instance.Spec.DryRun {
+		r.validateDesiredState(enactmentInstance, enactmentConditions)
+		return ctrl.Result{}, nil
+	}
+
+	r.captureStateBeforeApply(instance, enactmentInstance)
+
+	if instance.Spec.DrainBeforeApply {
+		// The node is uncordoned if the policy cordoned it, also when it did
+		// before a handler restart.
+		defer r.uncordonNode(instance)
+		// Only drain the first time the policy generation is applied, not
+		// when it is applied again at handler restart or drift enforcement.
+		if !previouslyAvailable {
+			if err := r.drainNode(instance, enactmentConditions); err != nil {
+				enactmentConditions.NotifyFailedToDrain(err)
+				log.Error(err, "failed draining node before applying desired state")
+				return ctrl.Result{}, nil
+			}
+		}
+	}
+
+	nmstateOutput, err := applyDesiredState(r.APIClient, enactmentInstance.Status.DesiredState, instance.Spec.Probes, enactmentEvents)
 	if err != nil {
 		errmsg := fmt.Errorf("error reconciling NodeNetworkConfigurationPolicy on node %s at desired state apply: %q,\n %v",
 			nodeName, nmstateOutput, err)
 		enactmentConditions.NotifyFailedToConfigure(errmsg)
+		r.storeDiff(instance, enactmentInstance)
 		log.Error(errmsg, fmt.Sprintf("Rolling back network configuration, manual intervention needed: %s", nmstateOutput))
-		if r.Recorder != nil {
-			r.Recorder.Event(instance, corev1.EventTypeWarning, ReconcileFailed, errmsg.Error())
-		}
+		enactmentEvents.ReconcileFailed(errmsg)
 		return ctrl.Result{}, nil
 	}
 	log.Info("nmstate", "output", nmstateOutput)
 
 	enactmentConditions.NotifySuccess()
 
+	r.recordHistory(instance, enactmentInstance, nmstateOutput)
+	r.storeDiff(instance, enactmentInstance)
+
 	r.forceNNSRefresh(nodeName)
 
 	return ctrl.Result{}, nil
 }
 
+// observeMaxUnavailablePending records the time the enactment has been
+// pending on the policy maxUnavailable limit before progressing.
+func observeMaxUnavailablePending(previousConditions *nmstateapi.ConditionList) {
+	pendingCondition := previousConditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionPending)
+	if pendingCondition == nil || pendingCondition.Status != corev1.ConditionTrue ||
+		pendingCondition.Reason != nmstateapi.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached {
+		return
+	}
+	monitoring.MaxUnavailablePendingDuration.Observe(time.Since(pendingCondition.LastTransitionTime.Time).Seconds())
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) validateDesiredState(
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+	enactmentConditions enactmentconditions.EnactmentConditions,
+) {
+	log := r.Log.WithName("validateDesiredState").WithValues("enactment", enactmentInstance.Name)
+	nmstateOutput, err := nmstate.ValidateDesiredState(enactmentInstance.Status.DesiredState)
+	if err != nil {
+		errmsg := fmt.Errorf("error validating NodeNetworkConfigurationPolicy on node %s at desired state dry-run: %q,\n %v",
+			nodeName, nmstateOutput, err)
+		enactmentConditions.NotifyDryRunFailure(errmsg)
+		log.Error(errmsg, "dry-run failed")
+		return
+	}
+	log.Info("nmstate dry-run", "output", nmstateOutput)
+	enactmentConditions.NotifyDryRunSuccess(nmstateOutput)
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) recordHistory(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+	nmstateOutput string,
+) {
+	err := enactmentstatus.AppendHistory(r.APIClient, nmstateapi.EnactmentKey(nodeName, policy.Name),
+		nmstateapi.NodeNetworkConfigurationEnactmentHistoryEntry{
+			PolicyGeneration: policy.Generation,
+			DesiredState:     enactmentInstance.Status.DesiredState,
+			Output:           nmstateOutput,
+		})
+	if err != nil {
+		r.Log.Error(err, "failed recording desired state at enactment history", "enactment", enactmentInstance.Name)
+	}
+}
+
+// pause keeps the enactment as it is if the node has already applied the
+// policy, otherwise it reports it as paused.
+func (r *NodeNetworkConfigurationPolicyReconciler) pause(policy *nmstatev1.NodeNetworkConfigurationPolicy) (ctrl.Result, error) {
+	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
+	log := r.Log.WithName("pause").WithValues("policy", policy.Name, "enactment", enactmentKey.Name)
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), enactmentKey, &enactmentInstance)
+	if err == nil && isEnactmentFinished(&enactmentInstance, policy.Generation) {
+		log.Info("Policy rollout is paused, node has already applied it")
+		return ctrl.Result{}, nil
+	}
+	_, err = r.initializeEnactment(policy)
+	if err != nil {
+		log.Error(err, "Error initializing enactment")
+		return ctrl.Result{}, err
+	}
+	enactmentConditions := enactmentconditions.New(r.APIClient, enactmentKey)
+	enactmentConditions.NotifyPaused()
+	log.Info("Policy rollout is paused, not applying it")
+	return ctrl.Result{}, nil
+}
+
+// waitForMaintenanceWindow keeps the enactment as it is if the node has already
+// applied the policy, otherwise it reports it as pending until the next
+// maintenance window opens.
+func (r *NodeNetworkConfigurationPolicyReconciler) waitForMaintenanceWindow(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	window maintenance.Status,
+) (ctrl.Result, error) {
+	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
+	log := r.Log.WithName("waitForMaintenanceWindow").WithValues("policy", policy.Name, "enactment", enactmentKey.Name)
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), enactmentKey, &enactmentInstance)
+	if err == nil && isEnactmentFinished(&enactmentInstance, policy.Generation) {
+		log.Info("Policy maintenance window is closed, node has already applied it")
+		return ctrl.Result{}, nil
+	}
+	_, err = r.initializeEnactment(policy)
+	if err != nil {
+		log.Error(err, "Error initializing enactment")
+		return ctrl.Result{}, err
+	}
+	enactmentConditions := enactmentconditions.New(r.APIClient, enactmentKey)
+	if window.NextStart.IsZero() {
+		message := "Waiting for a maintenance window, the schedule does not open any"
+		enactmentConditions.NotifyWaitingForMaintenanceWindow(message)
+		log.Info(message)
+		return ctrl.Result{}, nil
+	}
+	message := fmt.Sprintf("Waiting for the maintenance window starting at %s", window.NextStart.Format(time.RFC3339))
+	enactmentConditions.NotifyWaitingForMaintenanceWindow(message)
+	log.Info(message)
+	return ctrl.Result{RequeueAfter: time.Until(window.NextStart)}, nil
+}
+
+// isRolledBack returns true if the node has been rolled back from the current
+// policy generation, so it is not applied again.
+func (r *NodeNetworkConfigurationPolicyReconciler) isRolledBack(policy *nmstatev1.NodeNetworkConfigurationPolicy) (bool, error) {
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), nmstateapi.EnactmentKey(nodeName, policy.Name), &enactmentInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed getting enactment")
+	}
+	if enactmentInstance.Status.PolicyGeneration != policy.Generation {
+		return false, nil
+	}
+	abortedCondition := enactmentInstance.Status.Conditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionAborted)
+	return abortedCondition != nil && abortedCondition.Status == corev1.ConditionTrue &&
+		abortedCondition.Reason == nmstateapi.NodeNetworkConfigurationEnactmentConditionRolledBack, nil
+}
+
+// isReverted returns true if the node has been reverted to a previous
+// desired state while at the current policy generation, so it is not applied
+// again on top of it.
+func (r *NodeNetworkConfigurationPolicyReconciler) isReverted(policy *nmstatev1.NodeNetworkConfigurationPolicy) (bool, error) {
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), nmstateapi.EnactmentKey(nodeName, policy.Name), &enactmentInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed getting enactment")
+	}
+	if enactmentInstance.Status.PolicyGeneration != policy.Generation {
+		return false, nil
+	}
+	availableCondition := enactmentInstance.Status.Conditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionAvailable)
+	return availableCondition != nil && availableCondition.Status == corev1.ConditionTrue &&
+		availableCondition.Reason == nmstateapi.NodeNetworkConfigurationEnactmentConditionReverted, nil
+}
+
+// captureStateBeforeApply stores at the enactment the node state the desired
+// state is going to change, it is captured only once per policy generation
+// so applying it again does not overwrite it.
+func (r *NodeNetworkConfigurationPolicyReconciler) captureStateBeforeApply(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+) {
+	log := r.Log.WithName("captureStateBeforeApply").WithValues("enactment", enactmentInstance.Name)
+	stateBeforeApply := enactmentInstance.Status.StateBeforeApply
+	if stateBeforeApply != nil && stateBeforeApply.PolicyGeneration == policy.Generation {
+		return
+	}
+	currentState, err := nmstatectlShowFn()
+	if err != nil {
+		log.Error(err, "failed retrieving current state, policy generation will not be possible to roll back")
+		return
+	}
+	state, err := rollback.StateBeforeApply(enactmentInstance.Status.DesiredState, nmstateapi.NewState(currentState))
+	if err != nil {
+		log.Error(err, "failed capturing state before apply, policy generation will not be possible to roll back")
+		return
+	}
+	err = enactmentstatus.Update(r.APIClient, nmstateapi.EnactmentKey(nodeName, policy.Name),
+		func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
+			status.StateBeforeApply = &nmstateapi.NodeNetworkConfigurationEnactmentStateBeforeApply{
+				PolicyGeneration: policy.Generation,
+				State:            state,
+				TimeStamp:        metav1.Now(),
+			}
+		})
+	if err != nil {
+		log.Error(err, "failed storing state before apply at enactment")
+	}
+}
+
+// isEnactmentFinished returns true if the node has already applied or
+// dry-run the policy generation, successfully or not.
+func isEnactmentFinished(enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment, generation int64) bool {
+	if enactmentInstance.Status.PolicyGeneration != generation {
+		return false
+	}
+	if enactmentstatus.IsDryRunSucceeded(&enactmentInstance.Status.Conditions) {
+		return true
+	}
+	for _, conditionType := range []nmstateapi.ConditionType{
+		nmstateapi.NodeNetworkConfigurationEnactmentConditionAvailable,
+		nmstateapi.NodeNetworkConfigurationEnactmentConditionFailing,
+		nmstateapi.NodeNetworkConfigurationEnactmentConditionAborted,
+	} {
+		condition := enactmentInstance.Status.Conditions.Find(conditionType)
+		if condition != nil && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnactmentAvailable returns true if the node has successfully applied the
+// policy generation.
+func isEnactmentAvailable(enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment, generation int64) bool {
+	if enactmentInstance.Status.PolicyGeneration != generation {
+		return false
+	}
+	return enactmentstatus.IsAvailable(&enactmentInstance.Status.Conditions)
+}
+
+// drainNode cordons the node, recording the policy at it, and evicts its
+// pods reporting the progress at the enactment.
+func (r *NodeNetworkConfigurationPolicyReconciler) drainNode(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	enactmentConditions enactmentconditions.EnactmentConditions,
+) error {
+	if _, err := node.Cordon(r.APIClient, nodeName, policy.Name, policy.Generation); err != nil {
+		return err
+	}
+	timeout := node.DefaultDrainTimeout
+	if policy.Spec.DrainTimeout != nil {
+		timeout = policy.Spec.DrainTimeout.Duration
+	}
+	enactmentConditions.NotifyDraining("Evicting pods")
+	err := drainNodeFn(r.KubeClient, nodeName, timeout, func(evicted, total int) {
+		enactmentConditions.NotifyDraining(fmt.Sprintf("Evicted %d/%d pods", evicted, total))
+	})
+	if err != nil {
+		return err
+	}
+	enactmentConditions.NotifyProgressing()
+	return nil
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) uncordonNode(policy *nmstatev1.NodeNetworkConfigurationPolicy) {
+	err := node.Uncordon(r.APIClient, nodeName, policy.Name)
+	if err != nil {
+		r.Log.Error(err, "failed uncordoning node after applying desired state")
+	}
+}
+
+// storeDiff summarizes at the enactment status the differences between the
+// desired state and the node current state after applying it.
+func (r *NodeNetworkConfigurationPolicyReconciler) storeDiff(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	enactmentInstance *nmstatev1beta1.NodeNetworkConfigurationEnactment,
+) {
+	log := r.Log.WithName("storeDiff").WithValues("enactment", enactmentInstance.Name)
+	currentState, err := nmstatectlShowFn()
+	if err != nil {
+		log.Error(err, "failed retrieving current state to diff it with desired state")
+		return
+	}
+	differences, err := diff.Compare(enactmentInstance.Status.DesiredState, nmstateapi.NewState(currentState))
+	if err != nil {
+		log.Error(err, "failed comparing desired state with current state")
+		return
+	}
+	err = enactmentstatus.Update(r.APIClient, nmstateapi.EnactmentKey(nodeName, policy.Name),
+		func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
+			status.Diff = diff.Summarize(differences, nmstateapi.EnactmentDiffLimit)
+		})
+	if err != nil {
+		log.Error(err, "failed storing desired state diff at enactment")
+	}
+}
+
 func (r *NodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	allPolicies := handler.MapFunc(
 		func(client.Object) []reconcile.Request {
@@ -292,6 +684,17 @@ func (r *NodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Man
 		return errors.Wrap(err, "failed to add watch to enqueue NNCPs reconcile on node label change")
 	}
 
+	if r.DriftEnforcements != nil {
+		// Add watch to enqueue NNCPs that have drifted from their desired state
+		err = c.Watch(
+			&source.Channel{Source: r.DriftEnforcements},
+			&handler.EnqueueRequestForObject{},
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to add watch to enqueue drifted NNCPs")
+		}
+	}
+
 	return nil
 }
 
@@ -323,7 +726,9 @@ func (r *NodeNetworkConfigurationPolicyReconciler) initializeEnactment(
 		if err != nil {
 			return nil, errors.Wrapf(err, "error waitting for NodeNetworkConfigurationEnactment: %+v", enactmentInstance)
 		}
-	} else {
+	} else if enactmentInstance.Status.PolicyGeneration != policy.Generation {
+		// The conditions of the same generation are kept so the next ones
+		// are compared with them and events are only emitted on transitions.
 		enactmentConditions := enactmentconditions.New(r.APIClient, enactmentKey)
 		enactmentConditions.Reset()
 	}
@@ -341,25 +746,33 @@ func (r *NodeNetworkConfigurationPolicyReconciler) fillInEnactmentStatus(
 		return err
 	}
 
+	policySpec := policy.Spec
+	policySpec.DesiredState, err = renderNodeTemplates(r.APIClient, policy.Spec.DesiredState)
+	if err != nil {
+		return r.notifyGenerateFailure(policy, enactmentConditions, err)
+	}
+	policySpec.DesiredState, err = ippool.Render(policySpec.DesiredState, func(pool string) (ippool.Address, error) {
+		if r.IPPoolStore == nil {
+			return ippool.Address{}, errors.New("missing ip pool store")
+		}
+		return ippool.Allocate(context.TODO(), r.APIClient, r.IPPoolStore, pool, nodeName, policy.Name)
+	})
+	if err != nil {
+		return r.notifyGenerateFailure(policy, enactmentConditions, err)
+	}
+
 	capturedStates, generatedDesiredState, err := nmpolicy.GenerateState(
-		policy.Spec.DesiredState,
-		policy.Spec,
+		policySpec.DesiredState,
+		policySpec,
 		nmstateapi.NewState(currentState),
 		enactmentInstance.Status.CapturedStates,
 	)
 	if err != nil {
-		err2 := enactmentstatus.Update(
-			r.APIClient,
-			nmstateapi.EnactmentKey(nodeName, policy.Name),
-			func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
-				status.PolicyGeneration = policy.Generation
-			},
-		)
-		if err2 != nil {
-			return err2
-		}
-		enactmentConditions.NotifyGenerateFailure(err)
-		return err
+		return r.notifyGenerateFailure(policy, enactmentConditions, err)
+	}
+
+	if err = r.validateProtected(policy, generatedDesiredState); err != nil {
+		return r.notifyGenerateFailure(policy, enactmentConditions, err)
 	}
 
 	desiredStateWithDefaults, err := bridge.ApplyDefaultVlanFiltering(generatedDesiredState)
@@ -367,14 +780,9 @@ func (r *NodeNetworkConfigurationPolicyReconciler) fillInEnactmentStatus(
 		return err
 	}
 
-	features := []string{}
-	stats, err := nmstatectl.Statistic(desiredStateWithDefaults)
+	features, err := desiredStateFeatures(desiredStateWithDefaults)
 	if err != nil {
-		log.Error(err, "failed calculating nmstate statistics")
-	} else {
-		for feature := range stats.Features {
-			features = append(features, feature)
-		}
+		log.Error(err, "failed calculating nmstate features")
 	}
 
 	return enactmentstatus.Update(
@@ -389,6 +797,70 @@ func (r *NodeNetworkConfigurationPolicyReconciler) fillInEnactmentStatus(
 	)
 }
 
+// renderNodeTemplates replaces the desired state node templates with the
+// values of this node, the node is only retrieved if there are templates.
+func renderNodeTemplates(cli client.Reader, desiredState nmstateapi.State) (nmstateapi.State, error) {
+	if !nodetemplate.HasTemplates(desiredState) {
+		return desiredState, nil
+	}
+	node := corev1.Node{}
+	if err := cli.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return desiredState, errors.Wrap(err, "failed retrieving node to render node templates")
+	}
+	return nodetemplate.Render(desiredState, &node)
+}
+
+// validateProtected refuses the desired state, once the capture and node
+// templates are resolved, if it modifies the interfaces or routes protected
+// at this node, the webhook cannot check them in advance.
+func (r *NodeNetworkConfigurationPolicyReconciler) validateProtected(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	desiredState nmstateapi.State,
+) error {
+	if policy.OverridesProtected() {
+		return nil
+	}
+	nmstateInstance, err := protection.Get(r.APIClient)
+	if err != nil {
+		return err
+	}
+	if !protection.HasProtected(nmstateInstance) {
+		return nil
+	}
+	node := corev1.Node{}
+	if err = r.APIClient.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return errors.Wrap(err, "failed retrieving node to check protected interfaces")
+	}
+	state, err := schema.FromState(desiredState)
+	if err != nil {
+		return errors.Wrap(err, "failed parsing desired state to check protected interfaces")
+	}
+	errs := protection.Validate(nmstateInstance, protection.MatchesLabels(node.Labels), state, field.NewPath("desiredState"))
+	if len(errs) > 0 {
+		return errors.Wrap(errs.ToAggregate(), "refusing to modify protected interfaces")
+	}
+	return nil
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) notifyGenerateFailure(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	enactmentConditions enactmentconditions.EnactmentConditions,
+	err error,
+) error {
+	err2 := enactmentstatus.Update(
+		r.APIClient,
+		nmstateapi.EnactmentKey(nodeName, policy.Name),
+		func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
+			status.PolicyGeneration = policy.Generation
+		},
+	)
+	if err2 != nil {
+		return err2
+	}
+	enactmentConditions.NotifyGenerateFailure(err)
+	return err
+}
+
 func (r *NodeNetworkConfigurationPolicyReconciler) enactmentForPolicy(
 	policy *nmstatev1.NodeNetworkConfigurationPolicy,
 ) (*nmstatev1beta1.NodeNetworkConfigurationEnactment, error) {
@@ -433,6 +905,7 @@ func (r *NodeNetworkConfigurationPolicyReconciler) deleteEnactmentForPolicy(poli
 	if err != nil && !apierrors.IsNotFound(err) {
 		return errors.Wrap(err, "failed deleting enactment")
 	}
+	r.Recorder.Forget(enactmentKey.Name)
 	return nil
 }
 
@@ -442,64 +915,33 @@ func (r *NodeNetworkConfigurationPolicyReconciler) shouldIncrementUnavailableNod
 ) bool {
 	return !enactmentstatus.IsProgressing(conditions) &&
 		(policy.Status.LastUnavailableNodeCountUpdate == nil ||
-			time.Since(policy.Status.LastUnavailableNodeCountUpdate.Time) < (nmstate.DesiredStateConfigurationTimeout+probe.ProbesTotalTimeout))
+			time.Since(policy.Status.LastUnavailableNodeCountUpdate.Time) <
+				(nmstate.CheckpointTimeout(policy.Spec.Probes)+probe.ProbesTotalTimeout))
 }
 
-func (r *NodeNetworkConfigurationPolicyReconciler) incrementUnavailableNodeCount(policy *nmstatev1.NodeNetworkConfigurationPolicy) error {
+func (r *NodeNetworkConfigurationPolicyReconciler) updateRolloutStatus(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	rolloutGate *rollout.Gate,
+) {
 	policyKey := types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}
-	return retry.OnError(retry.DefaultRetry, func(error) bool { return true }, func() error {
-		err := r.Client.Get(context.TODO(), policyKey, policy)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1.NodeNetworkConfigurationPolicy{}
+		err := r.APIClient.Get(context.TODO(), policyKey, instance)
 		if err != nil {
 			return err
 		}
-		maxUnavailable, err := node.MaxUnavailableNodeCount(r.APIClient, policy)
-		if err != nil {
-			r.Log.Info(
-				fmt.Sprintf("failed calculating limit of max unavailable nodes, defaulting to %d, err: %s", maxUnavailable, err.Error()),
-			)
-		}
-		if policy.Status.UnavailableNodeCount >= maxUnavailable {
-			return node.MaxUnavailableLimitReachedError{}
+		rolloutStatus := rolloutGate.Status(instance.Status.Rollout)
+		if reflect.DeepEqual(instance.Status.Rollout, rolloutStatus) {
+			return nil
 		}
-		policy.Status.LastUnavailableNodeCountUpdate = &metav1.Time{Time: time.Now()}
-		policy.Status.UnavailableNodeCount += 1
-		return r.Client.Status().Update(context.TODO(), policy)
+		instance.Status.Rollout = rolloutStatus
+		return r.Client.Status().Update(context.TODO(), instance)
 	})
-}
-
-func (r *NodeNetworkConfigurationPolicyReconciler) decrementUnavailableNodeCount(policy *nmstatev1.NodeNetworkConfigurationPolicy) {
-	policyKey := types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}
-	err := tryDecrementingUnavailableNodeCount(r.Client, r.Client, policyKey)
 	if err != nil {
-		r.Log.Error(err, "error decrementing unavailableNodeCount with cached client, trying again with non-cached client.")
-		err = tryDecrementingUnavailableNodeCount(r.Client, r.APIClient, policyKey)
-		if err != nil {
-			r.Log.Error(err, "error decrementing unavailableNodeCount with non-cached client")
-		}
+		r.Log.Error(err, "failed updating policy rollout status", "policy", policy.GetName())
 	}
 }
 
-func tryDecrementingUnavailableNodeCount(
-	statusWriterClient client.StatusClient,
-	readerClient client.Reader,
-	policyKey types.NamespacedName,
-) error {
-	instance := &nmstatev1.NodeNetworkConfigurationPolicy{}
-	err := retry.OnError(retry.DefaultRetry, func(error) bool { return true }, func() error {
-		err := readerClient.Get(context.TODO(), policyKey, instance)
-		if err != nil {
-			return err
-		}
-		if instance.Status.UnavailableNodeCount <= 0 {
-			return fmt.Errorf("no unavailable nodes")
-		}
-		instance.Status.LastUnavailableNodeCountUpdate = &metav1.Time{Time: time.Now()}
-		instance.Status.UnavailableNodeCount -= 1
-		return statusWriterClient.Status().Update(context.TODO(), instance)
-	})
-	return err
-}
-
 func (r *NodeNetworkConfigurationPolicyReconciler) forceNNSRefresh(name string) {
 	log := r.Log.WithName("forceNNSRefresh").WithValues("node", name)
 	log.Info("forcing NodeNetworkState refresh after NNCP applied")
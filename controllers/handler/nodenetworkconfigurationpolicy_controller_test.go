@@ -19,6 +19,8 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -26,33 +28,46 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/client"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/events"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+	fakebackend "github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl/fake"
+	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+	"github.com/nmstate/kubernetes-nmstate/pkg/rollout"
 )
 
 var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func() {
 	type predicateCase struct {
 		GenerationOld   int64
 		GenerationNew   int64
+		AnnotationsOld  map[string]string
+		AnnotationsNew  map[string]string
 		ReconcileUpdate bool
 	}
 	DescribeTable("testing predicates",
 		func(c predicateCase) {
 			oldNNCP := nmstatev1.NodeNetworkConfigurationPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Generation: c.GenerationOld,
+					Generation:  c.GenerationOld,
+					Annotations: c.AnnotationsOld,
 				},
 			}
 			newNNCP := nmstatev1.NodeNetworkConfigurationPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Generation: c.GenerationNew,
+					Generation:  c.GenerationNew,
+					Annotations: c.AnnotationsNew,
 				},
 			}
 
@@ -84,6 +99,27 @@ var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func()
 				GenerationNew:   2,
 				ReconcileUpdate: true,
 			}),
+		Entry("policy is paused",
+			predicateCase{
+				GenerationOld:   1,
+				GenerationNew:   1,
+				AnnotationsNew:  map[string]string{shared.NodeNetworkConfigurationPolicyPausedAnnotation: "true"},
+				ReconcileUpdate: true,
+			}),
+		Entry("policy is resumed",
+			predicateCase{
+				GenerationOld:   1,
+				GenerationNew:   1,
+				AnnotationsOld:  map[string]string{shared.NodeNetworkConfigurationPolicyPausedAnnotation: "true"},
+				ReconcileUpdate: true,
+			}),
+		Entry("other annotation is changed",
+			predicateCase{
+				GenerationOld:   1,
+				GenerationNew:   1,
+				AnnotationsNew:  map[string]string{"foo": "bar"},
+				ReconcileUpdate: false,
+			}),
 	)
 
 	type incrementUnavailableNodeCountCase struct {
@@ -105,6 +141,7 @@ var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func()
 			)
 			s.AddKnownTypes(nmstatev1.GroupVersion,
 				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
 			)
 
 			node := corev1.Node{
@@ -213,3 +250,1086 @@ var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func()
 			}),
 	)
 })
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller dependencies", func() {
+	type dependenciesCase struct {
+		dependencyEnactmentConditions func(*shared.ConditionList, string)
+		dependencyEnactmentGeneration int64
+		dependencyNodeSelector        map[string]string
+		expectedReconcileResult       ctrl.Result
+		expectedPendingReason         shared.ConditionReason
+	}
+	DescribeTable("when policy depends on another policy and",
+		func(c dependenciesCase) {
+			nmstatectlShowFn = func() (string, error) { return "", nil }
+			applyDesiredStateFn = func(
+				client.Client, shared.State, *shared.NodeNetworkConfigurationPolicyProbes, nmstate.ApplyObserver,
+			) (string, error) {
+				return "", nil
+			}
+			DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+			reconciler := NodeNetworkConfigurationPolicyReconciler{}
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
+			)
+
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+			}
+			dependency := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "bond",
+					Generation: 2,
+				},
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					NodeSelector: c.dependencyNodeSelector,
+				},
+			}
+			dependencyEnactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: shared.EnactmentKey(nodeName, dependency.Name).Name,
+				},
+				Status: shared.NodeNetworkConfigurationEnactmentStatus{
+					PolicyGeneration: c.dependencyEnactmentGeneration,
+				},
+			}
+			c.dependencyEnactmentConditions(&dependencyEnactment.Status.Conditions, "")
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "vlan",
+				},
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DependsOn: []string{dependency.Name},
+				},
+			}
+			nnce := nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: shared.EnactmentKey(nodeName, nncp.Name).Name,
+				},
+			}
+
+			objs := []runtime.Object{&nncp, &nnce, &dependency, &node}
+			// The handler removes the enactments of the policies not matching the node
+			if c.dependencyNodeSelector == nil {
+				objs = append(objs, &dependencyEnactment)
+			}
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+			reconciler.Client = cl
+			reconciler.APIClient = cl
+			reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+
+			res, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(c.expectedReconcileResult))
+
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			pendingCondition := obtainedNNCE.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionPending)
+			Expect(pendingCondition).ToNot(BeNil())
+			Expect(pendingCondition.Reason).To(Equal(c.expectedPendingReason))
+		},
+		Entry("dependency is not available, should wait for it",
+			dependenciesCase{
+				dependencyEnactmentConditions: conditions.SetProgressing,
+				dependencyEnactmentGeneration: 2,
+				expectedReconcileResult:       ctrl.Result{RequeueAfter: dependencyRetryTime},
+				expectedPendingReason:         shared.NodeNetworkConfigurationEnactmentConditionWaitingForDependency,
+			}),
+		Entry("dependency is available for a previous generation, should wait for it",
+			dependenciesCase{
+				dependencyEnactmentConditions: conditions.SetSuccess,
+				dependencyEnactmentGeneration: 1,
+				expectedReconcileResult:       ctrl.Result{RequeueAfter: dependencyRetryTime},
+				expectedPendingReason:         shared.NodeNetworkConfigurationEnactmentConditionWaitingForDependency,
+			}),
+		Entry("dependency has only been dry-run, should wait for it",
+			dependenciesCase{
+				dependencyEnactmentConditions: conditions.SetDryRunSuccess,
+				dependencyEnactmentGeneration: 2,
+				expectedReconcileResult:       ctrl.Result{RequeueAfter: dependencyRetryTime},
+				expectedPendingReason:         shared.NodeNetworkConfigurationEnactmentConditionWaitingForDependency,
+			}),
+		Entry("dependency is available, should apply the policy",
+			dependenciesCase{
+				dependencyEnactmentConditions: conditions.SetSuccess,
+				dependencyEnactmentGeneration: 2,
+				expectedReconcileResult:       ctrl.Result{},
+				expectedPendingReason:         shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+		Entry("dependency does not select the node, should apply the policy",
+			dependenciesCase{
+				dependencyEnactmentConditions: conditions.SetProgressing,
+				dependencyNodeSelector:        map[string]string{"node-role.kubernetes.io/storage": ""},
+				expectedReconcileResult:       ctrl.Result{},
+				expectedPendingReason:         shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+	)
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller diff", func() {
+	It("should store at enactment the differences between desired and current state", func() {
+		nmstatectlShowFn = func() (string, error) {
+			return `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+`, nil
+		}
+		DeferCleanup(func() { nmstatectlShowFn = func() (string, error) { return "", nil } })
+		reconciler := NodeNetworkConfigurationPolicyReconciler{}
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+		)
+
+		nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mtu",
+			},
+		}
+		nnce := nmstatev1beta1.NodeNetworkConfigurationEnactment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: shared.EnactmentKey(nodeName, nncp.Name).Name,
+			},
+			Status: shared.NodeNetworkConfigurationEnactmentStatus{
+				DesiredState: shared.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+`),
+			},
+		}
+
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nnce).Build()
+		reconciler.APIClient = cl
+		reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+
+		reconciler.storeDiff(&nncp, &nnce)
+
+		obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+		Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+		Expect(obtainedNNCE.Status.Diff).To(Equal(&shared.StateDiffSummary{
+			Total: 1,
+			Differences: []shared.StateDifference{
+				{Path: "interfaces[eth1].mtu", Desired: "9000", Current: "1500"},
+			},
+		}))
+	})
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller rollout", func() {
+	type rolloutCase struct {
+		canaryEnactmentConditions func(*shared.ConditionList, string)
+		expectedReconcileResult   ctrl.Result
+		expectedPendingReason     shared.ConditionReason
+		expectedRolloutStatus     *shared.RolloutStatus
+		expectedStartedWaves      []string
+		expectedAvailableWaves    []string
+	}
+	DescribeTable("when policy has a canary wave and node is not at it and",
+		func(c rolloutCase) {
+			nmstatectlShowFn = func() (string, error) { return "", nil }
+			applyDesiredStateFn = func(
+				client.Client, shared.State, *shared.NodeNetworkConfigurationPolicyProbes, nmstate.ApplyObserver,
+			) (string, error) {
+				return "", nil
+			}
+			DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+			reconciler := NodeNetworkConfigurationPolicyReconciler{}
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
+			)
+
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+			}
+			canaryNode := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "canary",
+					Labels: map[string]string{"canary": ""},
+				},
+			}
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "vlan",
+					Generation: 1,
+				},
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Rollout: &shared.RolloutStrategy{
+						Waves: []shared.RolloutWave{{Name: "canary", NodeSelector: canaryNode.Labels}},
+					},
+				},
+			}
+			canaryEnactment := nmstatev1beta1.NewEnactment(&canaryNode, &nncp)
+			canaryEnactment.Status.PolicyGeneration = nncp.Generation
+			c.canaryEnactmentConditions(&canaryEnactment.Status.Conditions, "")
+			nnce := nmstatev1beta1.NewEnactment(&node, &nncp)
+
+			objs := []runtime.Object{&nncp, &nnce, &canaryEnactment, &node, &canaryNode}
+			for _, n := range []string{node.Name, canaryNode.Name} {
+				objs = append(objs, &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "nmstate-handler-" + n,
+						Namespace: "nmstate",
+						Labels:    map[string]string{"component": "kubernetes-nmstate-handler"},
+					},
+					Spec: corev1.PodSpec{NodeName: n},
+				})
+			}
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+			reconciler.Client = cl
+			reconciler.APIClient = cl
+			reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+
+			res, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(c.expectedReconcileResult))
+
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			pendingCondition := obtainedNNCE.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionPending)
+			Expect(pendingCondition).ToNot(BeNil())
+			Expect(pendingCondition.Reason).To(Equal(c.expectedPendingReason))
+
+			obtainedNNCP := nmstatev1.NodeNetworkConfigurationPolicy{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nncp.Name}, &obtainedNNCP)).To(Succeed())
+			// The wave times are set to when the handler evaluated them so
+			// only the waves that have them are checked
+			startedWaves := []string{}
+			availableWaves := []string{}
+			for i := range obtainedNNCP.Status.Rollout.Plan {
+				wave := &obtainedNNCP.Status.Rollout.Plan[i]
+				if wave.StartTime != nil {
+					startedWaves = append(startedWaves, wave.Name)
+				}
+				if wave.AvailableTime != nil {
+					availableWaves = append(availableWaves, wave.Name)
+				}
+				wave.StartTime = nil
+				wave.AvailableTime = nil
+			}
+			Expect(startedWaves).To(ConsistOf(c.expectedStartedWaves))
+			Expect(availableWaves).To(ConsistOf(c.expectedAvailableWaves))
+			Expect(obtainedNNCP.Status.Rollout).To(Equal(c.expectedRolloutStatus))
+		},
+		Entry("canary wave is progressing, should wait for it",
+			rolloutCase{
+				canaryEnactmentConditions: conditions.SetProgressing,
+				expectedReconcileResult:   ctrl.Result{RequeueAfter: rolloutRetryTime},
+				expectedPendingReason:     shared.NodeNetworkConfigurationEnactmentConditionWaitingForRolloutWave,
+				expectedRolloutStatus: &shared.RolloutStatus{
+					CurrentWave:      "canary",
+					CurrentWaveIndex: 0,
+					Waves:            2,
+					PolicyGeneration: 1,
+					Plan: []shared.RolloutWaveStatus{
+						{Name: "canary", Nodes: []string{"canary"}},
+						{Name: rollout.RemainingWaveName, Nodes: []string{nodeName}},
+					},
+				},
+			}),
+		Entry("canary wave has failed, should pause the rollout",
+			rolloutCase{
+				canaryEnactmentConditions: conditions.SetFailedToConfigure,
+				expectedReconcileResult:   ctrl.Result{},
+				expectedPendingReason:     shared.NodeNetworkConfigurationEnactmentConditionRolloutPaused,
+				expectedRolloutStatus: &shared.RolloutStatus{
+					CurrentWave:      rollout.RemainingWaveName,
+					CurrentWaveIndex: 1,
+					Waves:            2,
+					Paused:           true,
+					PolicyGeneration: 1,
+					Plan: []shared.RolloutWaveStatus{
+						{Name: "canary", Nodes: []string{"canary"}},
+						{Name: rollout.RemainingWaveName, Nodes: []string{nodeName}},
+					},
+				},
+			}),
+		Entry("canary wave is available, should apply the policy",
+			rolloutCase{
+				canaryEnactmentConditions: conditions.SetSuccess,
+				expectedReconcileResult:   ctrl.Result{},
+				expectedPendingReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+				expectedRolloutStatus: &shared.RolloutStatus{
+					CurrentWave:      rollout.RemainingWaveName,
+					CurrentWaveIndex: 1,
+					Waves:            2,
+					PolicyGeneration: 1,
+					Plan: []shared.RolloutWaveStatus{
+						{Name: "canary", Nodes: []string{"canary"}},
+						{Name: rollout.RemainingWaveName, Nodes: []string{nodeName}},
+					},
+				},
+				expectedStartedWaves:   []string{rollout.RemainingWaveName},
+				expectedAvailableWaves: []string{"canary"},
+			}),
+	)
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller pause", func() {
+	type pauseCase struct {
+		previousEnactmentConditions func(*shared.ConditionList, string)
+		expectedConditionType       shared.ConditionType
+		expectedConditionReason     shared.ConditionReason
+	}
+	DescribeTable("when policy is paused and",
+		func(c pauseCase) {
+			nmstatectlShowFn = func() (string, error) { return "", nil }
+			applyDesiredStateFn = func(
+				client.Client, shared.State, *shared.NodeNetworkConfigurationPolicyProbes, nmstate.ApplyObserver,
+			) (string, error) {
+				Fail("desired state should not be applied while policy is paused")
+				return "", nil
+			}
+			DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+			reconciler := NodeNetworkConfigurationPolicyReconciler{}
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
+			)
+
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+			}
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "vlan",
+					Generation:  1,
+					Annotations: map[string]string{shared.NodeNetworkConfigurationPolicyPausedAnnotation: "true"},
+				},
+			}
+			nnce := nmstatev1beta1.NewEnactment(&node, &nncp)
+			nnce.Status.PolicyGeneration = nncp.Generation
+			c.previousEnactmentConditions(&nnce.Status.Conditions, "")
+
+			objs := []runtime.Object{&nncp, &nnce, &node}
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+			reconciler.Client = cl
+			reconciler.APIClient = cl
+			reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+
+			res, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{}))
+
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			condition := obtainedNNCE.Status.Conditions.Find(c.expectedConditionType)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(c.expectedConditionReason))
+		},
+		Entry("node has not started applying it, should report it as paused",
+			pauseCase{
+				previousEnactmentConditions: conditions.SetPending,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionPending,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionPaused,
+			}),
+		Entry("node has already applied it, should keep it available",
+			pauseCase{
+				previousEnactmentConditions: conditions.SetSuccess,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+	)
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller maintenance window", func() {
+	type maintenanceWindowCase struct {
+		previousEnactmentConditions func(*shared.ConditionList, string)
+		expectedConditionType       shared.ConditionType
+		expectedConditionReason     shared.ConditionReason
+	}
+	DescribeTable("when policy maintenance window is closed and",
+		func(c maintenanceWindowCase) {
+			nmstatectlShowFn = func() (string, error) { return "", nil }
+			applyDesiredStateFn = func(
+				client.Client, shared.State, *shared.NodeNetworkConfigurationPolicyProbes, nmstate.ApplyObserver,
+			) (string, error) {
+				Fail("desired state should not be applied outside of the maintenance window")
+				return "", nil
+			}
+			DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+			reconciler := NodeNetworkConfigurationPolicyReconciler{}
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
+			)
+
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+			}
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "vlan",
+					Generation: 1,
+				},
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					MaintenanceWindow: &shared.MaintenanceWindow{
+						// February 30th never happens so the window is always closed
+						Schedule: "0 0 30 2 *",
+						Duration: metav1.Duration{Duration: time.Hour},
+					},
+				},
+			}
+			nnce := nmstatev1beta1.NewEnactment(&node, &nncp)
+			nnce.Status.PolicyGeneration = nncp.Generation
+			c.previousEnactmentConditions(&nnce.Status.Conditions, "")
+
+			objs := []runtime.Object{&nncp, &nnce, &node}
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+			reconciler.Client = cl
+			reconciler.APIClient = cl
+			reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+
+			res, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{}))
+
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			condition := obtainedNNCE.Status.Conditions.Find(c.expectedConditionType)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(c.expectedConditionReason))
+		},
+		Entry("node has not started applying it, should report it as waiting for the window",
+			maintenanceWindowCase{
+				previousEnactmentConditions: conditions.SetPending,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionPending,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionWaitingForMaintenanceWindow,
+			}),
+		Entry("node has already applied it, should keep it available",
+			maintenanceWindowCase{
+				previousEnactmentConditions: conditions.SetSuccess,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+	)
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller drain", func() {
+	type drainCase struct {
+		previousEnactmentConditions func(*shared.ConditionList, string)
+		nodeUnschedulable           bool
+		nodeAnnotations             map[string]string
+		drainErr                    error
+		expectedDrained             bool
+		expectedApplied             bool
+		expectedConditionType       shared.ConditionType
+		expectedConditionReason     shared.ConditionReason
+		expectedNodeUnschedulable   bool
+	}
+	DescribeTable("when policy has drainBeforeApply and",
+		func(c drainCase) {
+			nmstatectlShowFn = func() (string, error) { return "", nil }
+			applied := false
+			applyDesiredStateFn = func(
+				client.Client, shared.State, *shared.NodeNetworkConfigurationPolicyProbes, nmstate.ApplyObserver,
+			) (string, error) {
+				applied = true
+				return "", nil
+			}
+			drained := false
+			drainNodeFn = func(_ kubernetes.Interface, _ string, timeout time.Duration, onProgress func(int, int)) error {
+				drained = true
+				Expect(timeout).To(Equal(node.DefaultDrainTimeout))
+				onProgress(1, 1)
+				return c.drainErr
+			}
+			DeferCleanup(func() {
+				applyDesiredStateFn = nmstate.ApplyDesiredState
+				drainNodeFn = node.Drain
+			})
+			reconciler := NodeNetworkConfigurationPolicyReconciler{}
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
+			)
+
+			nodeInstance := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        nodeName,
+					Annotations: c.nodeAnnotations,
+				},
+				Spec: corev1.NodeSpec{
+					Unschedulable: c.nodeUnschedulable,
+				},
+			}
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "br1",
+					Generation: 1,
+				},
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DrainBeforeApply: true,
+				},
+			}
+			nnce := nmstatev1beta1.NewEnactment(&nodeInstance, &nncp)
+			nnce.Status.PolicyGeneration = nncp.Generation
+			c.previousEnactmentConditions(&nnce.Status.Conditions, "")
+
+			objs := []runtime.Object{&nncp, &nnce, &nodeInstance}
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+			reconciler.Client = cl
+			reconciler.APIClient = cl
+			reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drained).To(Equal(c.expectedDrained))
+			Expect(applied).To(Equal(c.expectedApplied))
+
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			condition := obtainedNNCE.Status.Conditions.Find(c.expectedConditionType)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(c.expectedConditionReason))
+
+			obtainedNode := corev1.Node{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &obtainedNode)).To(Succeed())
+			Expect(obtainedNode.Spec.Unschedulable).To(Equal(c.expectedNodeUnschedulable))
+			if !c.expectedNodeUnschedulable {
+				Expect(obtainedNode.Annotations).ToNot(HaveKey(shared.NodeCordonedByPolicyAnnotation))
+			}
+		},
+		Entry("node has not applied it, should drain the node, apply it and uncordon the node",
+			drainCase{
+				previousEnactmentConditions: conditions.SetPending,
+				expectedDrained:             true,
+				expectedApplied:             true,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+		Entry("node was already cordoned, should keep it cordoned after applying it",
+			drainCase{
+				previousEnactmentConditions: conditions.SetPending,
+				nodeUnschedulable:           true,
+				expectedDrained:             true,
+				expectedApplied:             true,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+				expectedNodeUnschedulable:   true,
+			}),
+		Entry("drain fails, should not apply it, mark it as failed to drain and uncordon the node",
+			drainCase{
+				previousEnactmentConditions: conditions.SetPending,
+				drainErr:                    fmt.Errorf("cannot evict pod as it would violate the pod's disruption budget"),
+				expectedDrained:             true,
+				expectedApplied:             false,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionFailing,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionFailedToDrain,
+			}),
+		Entry("node was cordoned by the policy before a handler restart, should drain it, apply it and uncordon the node",
+			drainCase{
+				previousEnactmentConditions: conditions.SetPending,
+				nodeUnschedulable:           true,
+				nodeAnnotations:             map[string]string{shared.NodeCordonedByPolicyAnnotation: "br1/1"},
+				expectedDrained:             true,
+				expectedApplied:             true,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+		Entry("node has applied it but the handler restarted before uncordoning it, should uncordon the node",
+			drainCase{
+				previousEnactmentConditions: conditions.SetSuccess,
+				nodeUnschedulable:           true,
+				nodeAnnotations:             map[string]string{shared.NodeCordonedByPolicyAnnotation: "br1/1"},
+				expectedDrained:             false,
+				expectedApplied:             true,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+		Entry("node was cordoned by another policy, should keep it cordoned after applying it",
+			drainCase{
+				previousEnactmentConditions: conditions.SetPending,
+				nodeUnschedulable:           true,
+				nodeAnnotations:             map[string]string{shared.NodeCordonedByPolicyAnnotation: "br2/1"},
+				expectedDrained:             true,
+				expectedApplied:             true,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+				expectedNodeUnschedulable:   true,
+			}),
+		Entry("node has already applied it, should apply it again without draining the node",
+			drainCase{
+				previousEnactmentConditions: conditions.SetSuccess,
+				expectedDrained:             false,
+				expectedApplied:             true,
+				expectedConditionType:       shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason:     shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+	)
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller rollback", func() {
+	var (
+		cl         client.Client
+		reconciler NodeNetworkConfigurationPolicyReconciler
+		nncp       nmstatev1.NodeNetworkConfigurationPolicy
+		nnce       nmstatev1beta1.NodeNetworkConfigurationEnactment
+	)
+	BeforeEach(func() {
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+		}
+		nncp = nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "vlan",
+				Generation: 2,
+			},
+		}
+		nnce = nmstatev1beta1.NewEnactment(&node, &nncp)
+		nnce.Status.PolicyGeneration = nncp.Generation
+		nnce.Status.DesiredState = shared.NewState(`
+interfaces:
+- name: eth1.101
+  type: vlan
+  vlan:
+    base-iface: eth1
+    id: 101
+`)
+	})
+	JustBeforeEach(func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+		)
+		s.AddKnownTypes(nmstatev1.GroupVersion,
+			&nmstatev1.NodeNetworkConfigurationPolicy{},
+		)
+		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nncp, &nnce).Build()
+		reconciler = NodeNetworkConfigurationPolicyReconciler{
+			Client:    cl,
+			APIClient: cl,
+			Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
+		}
+	})
+	DescribeTable("when checking if the policy generation has been rolled back and",
+		func(enactmentGeneration int64, setConditions func(*shared.ConditionList, string), expected bool) {
+			nnce.Status.PolicyGeneration = enactmentGeneration
+			setConditions(&nnce.Status.Conditions, "")
+			reconciler.APIClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(&nncp, &nnce).Build()
+			Expect(reconciler.isRolledBack(&nncp)).To(Equal(expected))
+		},
+		Entry("enactment is rolled back at the policy generation, should be true", int64(2), conditions.SetRolledBack, true),
+		Entry("enactment is rolled back at a previous policy generation, should be false", int64(1), conditions.SetRolledBack, false),
+		Entry("enactment is aborted for other reason, should be false", int64(2), conditions.SetConfigurationAborted, false),
+		Entry("enactment is available, should be false", int64(2), conditions.SetSuccess, false),
+	)
+	DescribeTable("when checking if the policy generation has been reverted and",
+		func(enactmentGeneration int64, setConditions func(*shared.ConditionList, string), expected bool) {
+			nnce.Status.PolicyGeneration = enactmentGeneration
+			setConditions(&nnce.Status.Conditions, "")
+			reconciler.APIClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(&nncp, &nnce).Build()
+			Expect(reconciler.isReverted(&nncp)).To(Equal(expected))
+		},
+		Entry("enactment is reverted at the policy generation, should be true", int64(2), conditions.SetReverted, true),
+		Entry("enactment is reverted at a previous policy generation, should be false", int64(1), conditions.SetReverted, false),
+		Entry("enactment failed to revert, should be false", int64(2), conditions.SetFailedToRevert, false),
+		Entry("enactment is available, should be false", int64(2), conditions.SetSuccess, false),
+	)
+	Context("when capturing the state before apply", func() {
+		BeforeEach(func() {
+			nmstatectlShowFn = func() (string, error) {
+				return `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+- name: eth2
+  type: ethernet
+  state: up
+`, nil
+			}
+			DeferCleanup(func() { nmstatectlShowFn = func() (string, error) { return "", nil } })
+		})
+		obtainStateBeforeApply := func() *shared.NodeNetworkConfigurationEnactmentStateBeforeApply {
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			ExpectWithOffset(1, cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			return obtainedNNCE.Status.StateBeforeApply
+		}
+		It("should store the current state of the interfaces the policy changes", func() {
+			reconciler.captureStateBeforeApply(&nncp, &nnce)
+			stateBeforeApply := obtainStateBeforeApply()
+			Expect(stateBeforeApply).ToNot(BeNil())
+			Expect(stateBeforeApply.PolicyGeneration).To(Equal(int64(2)))
+			Expect(string(stateBeforeApply.State.Raw)).To(MatchYAML(`
+interfaces:
+- name: eth1.101
+  type: vlan
+  state: absent
+- name: eth1
+  type: ethernet
+  state: up
+`))
+		})
+		Context("and it was already captured for the policy generation", func() {
+			BeforeEach(func() {
+				nnce.Status.StateBeforeApply = &shared.NodeNetworkConfigurationEnactmentStateBeforeApply{
+					PolicyGeneration: 2,
+					State:            shared.NewState("interfaces: []"),
+				}
+			})
+			It("should keep the previously captured state", func() {
+				reconciler.captureStateBeforeApply(&nncp, &nnce)
+				Expect(string(obtainStateBeforeApply().State.Raw)).To(MatchYAML("interfaces: []"))
+			})
+		})
+	})
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller protected interfaces", func() {
+	type protectedCase struct {
+		nodeSelector            map[string]string
+		annotations             map[string]string
+		resolvedState           string
+		expectedApplied         bool
+		expectedConditionType   shared.ConditionType
+		expectedConditionReason shared.ConditionReason
+		expectedMessage         string
+	}
+	DescribeTable("when the desired state with the capture resolved modifies an interface protected",
+		func(c protectedCase) {
+			backend, err := fakebackend.New(shared.NewState(`
+interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+`))
+			Expect(err).ToNot(HaveOccurred())
+			if c.resolvedState == "" {
+				c.resolvedState = `{"interfaces":[{"name":"eth0","type":"ethernet","state":"up","mtu":9000}]}`
+			}
+			if c.expectedMessage == "" {
+				c.expectedMessage = "desiredState.interfaces[0].name: Forbidden: interface eth0 is protected by NMState nmstate"
+			}
+			backend.PolicyFn = func(_, _, _ []byte) ([]byte, []byte, error) {
+				return []byte(c.resolvedState),
+					[]byte(`{"primary-nic":{"state":{"interfaces":[{"name":"eth0","type":"ethernet","state":"up"}]}}}`),
+					nil
+			}
+			previousBackend := nmstatectl.SetBackend(backend)
+			nmstatectlShowFn = nmstatectl.Show
+			applied := false
+			applyDesiredStateFn = func(
+				client.Client, shared.State, *shared.NodeNetworkConfigurationPolicyProbes, nmstate.ApplyObserver,
+			) (string, error) {
+				applied = true
+				return "", nil
+			}
+			DeferCleanup(func() {
+				nmstatectl.SetBackend(previousBackend)
+				nmstatectlShowFn = func() (string, error) { return "", nil }
+				applyDesiredStateFn = nmstate.ApplyDesiredState
+			})
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMState{},
+				&nmstatev1.NMStateList{},
+			)
+
+			nodeInstance := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nodeName,
+					Labels: map[string]string{"role": "control-plane"},
+				},
+			}
+			nmstateInstance := nmstatev1.NMState{
+				ObjectMeta: metav1.ObjectMeta{Name: "nmstate"},
+				Spec: nmstatev1.NMStateSpec{
+					ProtectedInterfaces: []nmstatev1.ProtectedInterface{{Name: "eth0", NodeSelector: c.nodeSelector}},
+				},
+			}
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "primary-mtu",
+					Generation:  1,
+					Annotations: c.annotations,
+				},
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Capture: map[string]string{"primary-nic": `interfaces.name=="eth0"`},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: "{{ capture.primary-nic.interfaces.0.name }}"
+  type: ethernet
+  state: up
+  mtu: 9000
+`),
+				},
+			}
+			nnce := nmstatev1beta1.NewEnactment(&nodeInstance, &nncp)
+			conditions.SetPending(&nnce.Status.Conditions, "")
+
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nncp, &nnce, &nodeInstance, &nmstateInstance).Build()
+			reconciler := NodeNetworkConfigurationPolicyReconciler{
+				Client:    cl,
+				APIClient: cl,
+				Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
+			}
+
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(Equal(c.expectedApplied))
+
+			obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+			Expect(cl.Get(context.TODO(), types.NamespacedName{Name: nnce.Name}, &obtainedNNCE)).To(Succeed())
+			condition := obtainedNNCE.Status.Conditions.Find(c.expectedConditionType)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(c.expectedConditionReason))
+			if !c.expectedApplied {
+				Expect(condition.Message).To(ContainSubstring("refusing to modify protected interfaces: " + c.expectedMessage))
+			}
+		},
+		Entry("at the node, should not apply it and mark it as failed",
+			protectedCase{
+				nodeSelector:            map[string]string{"role": "control-plane"},
+				expectedApplied:         false,
+				expectedConditionType:   shared.NodeNetworkConfigurationEnactmentConditionFailing,
+				expectedConditionReason: shared.NodeNetworkConfigurationEnactmentConditionFailedToConfigure,
+			}),
+		Entry("at the node as a vrf port, should not apply it and mark it as failed",
+			protectedCase{
+				nodeSelector: map[string]string{"role": "control-plane"},
+				resolvedState: `{"interfaces":[{"name":"vrf0","type":"vrf","state":"up",` +
+					`"vrf":{"port":["eth0"],"route-table-id":100}}]}`,
+				expectedApplied:         false,
+				expectedConditionType:   shared.NodeNetworkConfigurationEnactmentConditionFailing,
+				expectedConditionReason: shared.NodeNetworkConfigurationEnactmentConditionFailedToConfigure,
+				expectedMessage:         "desiredState.interfaces[0].vrf.port[0]: Forbidden: interface eth0 is protected by NMState nmstate",
+			}),
+		Entry("at other nodes, should apply it",
+			protectedCase{
+				nodeSelector:            map[string]string{"role": "worker"},
+				expectedApplied:         true,
+				expectedConditionType:   shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason: shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+		Entry("with the override annotation, should apply it",
+			protectedCase{
+				annotations:             map[string]string{shared.NodeNetworkConfigurationPolicyOverrideProtectedAnnotation: "true"},
+				expectedApplied:         true,
+				expectedConditionType:   shared.NodeNetworkConfigurationEnactmentConditionAvailable,
+				expectedConditionReason: shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+			}),
+	)
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller events", func() {
+	type eventsCase struct {
+		apply          func(nmstate.ApplyObserver) error
+		expectedEvents []string
+	}
+	DescribeTable("when applying a policy",
+		func(c eventsCase) {
+			nmstatectlShowFn = func() (string, error) { return "", nil }
+			applyDesiredStateFn = func(
+				_ client.Client, _ shared.State, _ *shared.NodeNetworkConfigurationPolicyProbes, observer nmstate.ApplyObserver,
+			) (string, error) {
+				return "", c.apply(observer)
+			}
+			DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			s.AddKnownTypes(nmstatev1.GroupVersion,
+				&nmstatev1.NodeNetworkConfigurationPolicy{},
+				&nmstatev1.NMStateList{},
+			)
+
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+			}
+			nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "policy1",
+				},
+			}
+			nnce := nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: shared.EnactmentKey(nodeName, nncp.Name).Name,
+				},
+			}
+			cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nncp, &nnce, &node).Build()
+
+			recorder := record.NewFakeRecorder(20)
+			reconciler := NodeNetworkConfigurationPolicyReconciler{
+				Client:    cl,
+				APIClient: cl,
+				Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
+				Recorder:  events.NewRecorder(recorder, events.DefaultQPS, events.DefaultBurst),
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			close(recorder.Events)
+			obtainedEvents := []string{}
+			for e := range recorder.Events {
+				obtainedEvents = append(obtainedEvents, e)
+			}
+			// Every event is emitted at the policy and at the node
+			expectedEvents := []string{}
+			for _, e := range c.expectedEvents {
+				expectedEvents = append(expectedEvents, e, e)
+			}
+			Expect(obtainedEvents).To(HaveLen(len(expectedEvents)))
+			for i := range expectedEvents {
+				Expect(obtainedEvents[i]).To(HavePrefix(expectedEvents[i]))
+			}
+		},
+		Entry("that is committed, should emit progressing and commit succeeded events",
+			eventsCase{
+				apply: func(observer nmstate.ApplyObserver) error {
+					observer.Committed()
+					return nil
+				},
+				expectedEvents: []string{
+					"Normal EnactmentProgressing",
+					"Normal CommitSucceeded",
+				},
+			}),
+		Entry("that fails a probe, should emit probe failure and rollback events",
+			eventsCase{
+				apply: func(observer nmstate.ApplyObserver) error {
+					err := fmt.Errorf("no ping reply")
+					observer.ProbeFailed("ping", err)
+					observer.RollbackStarted(err)
+					observer.RollbackCompleted()
+					return err
+				},
+				expectedEvents: []string{
+					"Normal EnactmentProgressing",
+					"Warning ProbeFailed probe 'ping' failed",
+					"Warning RollbackStarted",
+					"Normal RollbackCompleted",
+					"Warning ReconcileFailed",
+				},
+			}),
+	)
+	It("should emit the pending event once when reconciling a pending enactment again", func() {
+		nmstatectlShowFn = func() (string, error) { return "", nil }
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkState{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+		)
+		s.AddKnownTypes(nmstatev1.GroupVersion,
+			&nmstatev1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1.NMStateList{},
+		)
+		node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		nncp := nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1", Generation: 1},
+			Status:     shared.NodeNetworkConfigurationPolicyStatus{UnavailableNodeCount: 1},
+		}
+		nnce := nmstatev1beta1.NodeNetworkConfigurationEnactment{
+			ObjectMeta: metav1.ObjectMeta{Name: shared.EnactmentKey(nodeName, nncp.Name).Name},
+		}
+		cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nncp, &nnce, &node).Build()
+
+		recorder := record.NewFakeRecorder(20)
+		reconciler := NodeNetworkConfigurationPolicyReconciler{
+			Client:    cl,
+			APIClient: cl,
+			Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
+			Recorder:  events.NewRecorder(recorder, events.DefaultQPS, events.DefaultBurst),
+		}
+		for i := 0; i < 2; i++ {
+			res, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: nncp.Name},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(nodeRunningUpdateRetryTime))
+		}
+
+		close(recorder.Events)
+		obtainedEvents := []string{}
+		for e := range recorder.Events {
+			obtainedEvents = append(obtainedEvents, e)
+		}
+		// The event is emitted at the policy and at the node
+		Expect(obtainedEvents).To(HaveLen(2))
+		for _, e := range obtainedEvents {
+			Expect(e).To(HavePrefix("Normal EnactmentPending"))
+		}
+	})
+})
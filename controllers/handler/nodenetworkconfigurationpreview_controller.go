@@ -0,0 +1,214 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/bridge"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmpolicy"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+// NodeNetworkConfigurationPreviewReconciler reconciles a NodeNetworkConfigurationPreview object
+type NodeNetworkConfigurationPreviewReconciler struct {
+	client.Client
+	// APIClient controller-runtime client without cache, used to update
+	// the preview status.
+	APIClient client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	// IPPoolStore is used to look up the addresses the node would get from
+	// the NodeIPPools referenced by the policy, nothing is allocated.
+	IPPoolStore ippool.Store
+}
+
+// Reconcile reads that state of the cluster for a NodeNetworkConfigurationPreview object and
+// renders its policy against this node current state, the result is stored at the preview
+// status and nothing is applied.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *NodeNetworkConfigurationPreviewReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("nodenetworkconfigurationpreview", request.NamespacedName)
+
+	previewInstance := &nmstatev1beta1.NodeNetworkConfigurationPreview{}
+	err := r.APIClient.Get(context.TODO(), request.NamespacedName, previewInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving preview")
+		return ctrl.Result{}, err
+	}
+
+	if previewInstance.Spec.Node != nodeName || previewInstance.Status.ObservedGeneration == previewInstance.Generation {
+		return ctrl.Result{}, nil
+	}
+
+	status := renderPreview(r.APIClient, &previewInstance.Spec.Policy, func(pool string) (ippool.Address, error) {
+		if r.IPPoolStore == nil {
+			return ippool.Address{}, errors.New("missing ip pool store")
+		}
+		return ippool.Lookup(context.TODO(), r.APIClient, r.IPPoolStore, pool, nodeName)
+	})
+	status.ObservedGeneration = previewInstance.Generation
+	if status.Error != "" {
+		log.Info("failed rendering preview", "error", status.Error)
+	}
+
+	err = r.updateStatus(request.NamespacedName, status)
+	if err != nil {
+		log.Error(err, "Error updating preview status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// renderPreview generates the desired state of the policy the same way the
+// policy controller does before applying it, resolving the node templates
+// with the node from cli and the NodeIPPool references with resolveIPPool.
+func renderPreview(
+	cli client.Reader,
+	policySpec *shared.NodeNetworkConfigurationPolicySpec,
+	resolveIPPool func(pool string) (ippool.Address, error),
+) shared.NodeNetworkConfigurationPreviewStatus {
+	status := shared.NodeNetworkConfigurationPreviewStatus{}
+	currentState, err := nmstatectlShowFn()
+	if err != nil {
+		status.Error = errors.Wrap(err, "failed retrieving current state").Error()
+		return status
+	}
+
+	renderedSpec := *policySpec
+	renderedSpec.DesiredState, err = renderNodeTemplates(cli, policySpec.DesiredState)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	renderedSpec.DesiredState, err = ippool.Render(renderedSpec.DesiredState, resolveIPPool)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	capturedStates, generatedDesiredState, err := nmpolicy.GenerateState(
+		renderedSpec.DesiredState,
+		renderedSpec,
+		shared.NewState(currentState),
+		nil,
+	)
+	if err != nil {
+		status.Error = errors.Wrap(err, "failed generating desired state").Error()
+		return status
+	}
+	status.CapturedStates = capturedStates
+
+	desiredStateWithDefaults, err := bridge.ApplyDefaultVlanFiltering(generatedDesiredState)
+	if err != nil {
+		status.Error = errors.Wrap(err, "failed applying defaults to desired state").Error()
+		return status
+	}
+	status.DesiredState = desiredStateWithDefaults
+
+	status.Features, err = desiredStateFeatures(desiredStateWithDefaults)
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// desiredStateFeatures returns the sorted nmstate features used by the
+// desired state, so the status does not change between reconciles
+func desiredStateFeatures(desiredState shared.State) ([]string, error) {
+	features := []string{}
+	stats, err := nmstatectl.Statistic(desiredState)
+	if err != nil {
+		return features, errors.Wrap(err, "failed calculating nmstate statistics")
+	}
+	for feature := range stats.Features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features, nil
+}
+
+func (r *NodeNetworkConfigurationPreviewReconciler) updateStatus(
+	previewKey types.NamespacedName,
+	status shared.NodeNetworkConfigurationPreviewStatus,
+) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeNetworkConfigurationPreview{}
+		err := r.APIClient.Get(context.TODO(), previewKey, instance)
+		if err != nil {
+			return err
+		}
+		instance.Status = status
+		return r.APIClient.Status().Update(context.TODO(), instance)
+	})
+}
+
+func (r *NodeNetworkConfigurationPreviewReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Only previews for this node are rendered, and only when their spec
+	// changes.
+	isForThisNode := func(obj client.Object) bool {
+		preview, ok := obj.(*nmstatev1beta1.NodeNetworkConfigurationPreview)
+		return ok && preview.Spec.Node == nodeName
+	}
+	onCreationOrSpecUpdateForThisNode := predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return isForThisNode(createEvent.Object)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+			return isForThisNode(updateEvent.ObjectNew) &&
+				updateEvent.ObjectNew.GetGeneration() != updateEvent.ObjectOld.GetGeneration()
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NodeNetworkConfigurationPreview{}).
+		WithEventFilter(onCreationOrSpecUpdateForThisNode).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NNCPreview Reconciler")
+	}
+
+	return nil
+}
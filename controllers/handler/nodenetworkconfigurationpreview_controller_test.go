@@ -0,0 +1,274 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+	fakebackend "github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl/fake"
+)
+
+var _ = Describe("Node Network Configuration Preview controller reconcile", func() {
+	var (
+		cl         client.Client
+		reconciler NodeNetworkConfigurationPreviewReconciler
+		request    reconcile.Request
+		backend    *fakebackend.Backend
+		preview    nmstatev1beta1.NodeNetworkConfigurationPreview
+	)
+	BeforeEach(func() {
+		var err error
+		backend, err = fakebackend.New(shared.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+`))
+		Expect(err).ToNot(HaveOccurred())
+		backend.Features = []string{"ovs-bridge", "linux-bridge", "ipv4-static"}
+		backend.PolicyFn = func(_, _, _ []byte) ([]byte, []byte, error) {
+			return []byte(`{"interfaces":[{"name":"br1","type":"linux-bridge","state":"up","bridge":{"port":[{"name":"eth1"}]}}]}`),
+				[]byte(`{"base-iface":{"state":{"interfaces":[{"name":"eth1","type":"ethernet","state":"up"}]}}}`),
+				nil
+		}
+		previousBackend := nmstatectl.SetBackend(backend)
+		nmstatectlShowFn = nmstatectl.Show
+		DeferCleanup(func() { nmstatectl.SetBackend(previousBackend) })
+
+		preview = nmstatev1beta1.NodeNetworkConfigurationPreview{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "preview1",
+				Generation: 1,
+			},
+			Spec: shared.NodeNetworkConfigurationPreviewSpec{
+				Node: "node01",
+				Policy: shared.NodeNetworkConfigurationPolicySpec{
+					Capture: map[string]string{"base-iface": `interfaces.name=="eth1"`},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  bridge:
+    port:
+    - name: "{{ capture.base-iface.interfaces.0.name }}"
+`),
+				},
+			},
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: preview.Name}}
+	})
+	JustBeforeEach(func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPreview{},
+			&nmstatev1beta1.NodeIPPool{},
+			&nmstatev1beta1.NodeIPAllocation{},
+			&nmstatev1beta1.NodeIPAllocationList{},
+		)
+
+		pool := nmstatev1beta1.NodeIPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+			Spec:       shared.NodeIPPoolSpec{Range: shared.NodeIPPoolRange{CIDR: "10.10.10.0/24"}},
+		}
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node01", Labels: map[string]string{"vlan": "101"}},
+		}
+		objs := []runtime.Object{&preview, &pool, &node}
+		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+		ipPoolStore, err := ippool.NewStore(ippool.CRDStoreName, cl)
+		Expect(err).ToNot(HaveOccurred())
+		reconciler = NodeNetworkConfigurationPreviewReconciler{
+			Client:      cl,
+			APIClient:   cl,
+			Log:         ctrl.Log.WithName("controllers").WithName("Preview"),
+			Scheme:      s,
+			IPPoolStore: ipPoolStore,
+		}
+	})
+	obtainPreviewStatus := func() shared.NodeNetworkConfigurationPreviewStatus {
+		obtainedPreview := nmstatev1beta1.NodeNetworkConfigurationPreview{}
+		ExpectWithOffset(1, cl.Get(context.TODO(), types.NamespacedName{Name: preview.Name}, &obtainedPreview)).To(Succeed())
+		return obtainedPreview.Status
+	}
+	Context("when the preview is for this node", func() {
+		It("should render the desired state, captured states and features without applying it", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status := obtainPreviewStatus()
+			Expect(status.Error).To(BeEmpty())
+			Expect(status.ObservedGeneration).To(Equal(int64(1)))
+			Expect(status.DesiredState.String()).To(ContainSubstring("vlan"), "should apply the default vlan filtering")
+			Expect(status.CapturedStates).To(HaveKey("base-iface"))
+			Expect(status.Features).To(Equal([]string{"ipv4-static", "linux-bridge", "ovs-bridge"}))
+			Expect(backend.Applied()).To(BeEmpty())
+			Expect(backend.Pending()).To(BeFalse())
+		})
+	})
+	Context("when the policy references a NodeIPPool", func() {
+		BeforeEach(func() {
+			preview.Spec.Policy = shared.NodeNetworkConfigurationPolicySpec{
+				DesiredState: shared.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - "{{ ippool.storage }}"
+`),
+			}
+			backend.PolicyFn = func(policy, _, _ []byte) ([]byte, []byte, error) {
+				nmstatePolicy := struct {
+					DesiredState json.RawMessage `json:"desiredState"`
+				}{}
+				err := json.Unmarshal(policy, &nmstatePolicy)
+				return nmstatePolicy.DesiredState, []byte("{}"), err
+			}
+		})
+		It("should render the address the node would get without allocating it", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status := obtainPreviewStatus()
+			Expect(status.Error).To(BeEmpty())
+			Expect(status.DesiredState.String()).To(MatchYAML(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.10.10.1
+      prefix-length: 24
+`))
+			allocations := nmstatev1beta1.NodeIPAllocationList{}
+			Expect(cl.List(context.TODO(), &allocations)).To(Succeed())
+			Expect(allocations.Items).To(BeEmpty())
+		})
+	})
+	Context("when the policy uses node templates", func() {
+		BeforeEach(func() {
+			preview.Spec.Policy = shared.NodeNetworkConfigurationPolicySpec{
+				DesiredState: shared.NewState(`
+interfaces:
+- name: "eth1.{{ node.labels['vlan'] }}"
+  type: vlan
+  state: up
+  vlan:
+    base-iface: eth1
+    id: "{{ node.labels['vlan'] | int }}"
+`),
+			}
+			backend.PolicyFn = func(policy, _, _ []byte) ([]byte, []byte, error) {
+				nmstatePolicy := struct {
+					DesiredState json.RawMessage `json:"desiredState"`
+				}{}
+				err := json.Unmarshal(policy, &nmstatePolicy)
+				return nmstatePolicy.DesiredState, []byte("{}"), err
+			}
+		})
+		It("should render them with the node values", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status := obtainPreviewStatus()
+			Expect(status.Error).To(BeEmpty())
+			Expect(status.DesiredState.String()).To(MatchYAML(`
+interfaces:
+- name: eth1.101
+  type: vlan
+  state: up
+  vlan:
+    base-iface: eth1
+    id: 101
+`))
+		})
+		Context("and the node misses a label", func() {
+			BeforeEach(func() {
+				preview.Spec.Policy.DesiredState = shared.NewState(`
+interfaces:
+- name: eth1
+  description: "{{ node.labels['rack'] }}"
+`)
+			})
+			It("should report the error at the status", func() {
+				_, err := reconciler.Reconcile(context.TODO(), request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obtainPreviewStatus().Error).To(Equal(`node node01 has no label "rack"`))
+			})
+		})
+	})
+	Context("when the preview was already rendered", func() {
+		BeforeEach(func() {
+			preview.Status.ObservedGeneration = preview.Generation
+		})
+		It("should not render it again", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainPreviewStatus().Features).To(BeEmpty())
+		})
+	})
+	Context("when the preview is for another node", func() {
+		BeforeEach(func() {
+			preview.Spec.Node = "node02"
+		})
+		It("should not render it", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainPreviewStatus().ObservedGeneration).To(BeZero())
+		})
+	})
+	Context("when the policy cannot be rendered", func() {
+		BeforeEach(func() {
+			backend.PolicyErr = fmt.Errorf("capture base-iface not found")
+		})
+		It("should report the error at the status", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status := obtainPreviewStatus()
+			Expect(status.ObservedGeneration).To(Equal(int64(1)))
+			Expect(status.Error).To(Equal("failed generating desired state: capture base-iface not found"))
+			Expect(status.Features).To(BeEmpty())
+		})
+	})
+})
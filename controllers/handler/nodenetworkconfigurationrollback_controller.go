@@ -0,0 +1,245 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+)
+
+var rollbackRetryTime = 5 * time.Second
+
+// NodeNetworkConfigurationRollbackReconciler reconciles a NodeNetworkConfigurationRollback object
+type NodeNetworkConfigurationRollbackReconciler struct {
+	client.Client
+	// APIClient controller-runtime client without cache, used to update
+	// the rollback and enactment status and by the probes when rolling back.
+	APIClient client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a NodeNetworkConfigurationRollback object and
+// applies at this node the state captured before the policy generation was applied.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *NodeNetworkConfigurationRollbackReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("nodenetworkconfigurationrollback", request.NamespacedName)
+
+	rollbackInstance := &nmstatev1beta1.NodeNetworkConfigurationRollback{}
+	err := r.APIClient.Get(context.TODO(), request.NamespacedName, rollbackInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving rollback")
+		return ctrl.Result{}, err
+	}
+
+	if isRollbackProcessed(rollbackInstance) {
+		return ctrl.Result{}, nil
+	}
+
+	policyInstance := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: rollbackInstance.Spec.Policy}, policyInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Policy is not found, nothing to roll back")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving policy")
+		return ctrl.Result{}, err
+	}
+
+	policyGeneration, err := r.resolvePolicyGeneration(rollbackInstance, policyInstance)
+	if err != nil {
+		log.Error(err, "Error resolving policy generation to roll back")
+		return ctrl.Result{}, err
+	}
+
+	enactmentKey := shared.EnactmentKey(nodeName, policyInstance.Name)
+	enactmentInstance := &nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err = r.APIClient.Get(context.TODO(), enactmentKey, enactmentInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Policy has not been applied at this node, nothing to roll back")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving enactment")
+		return ctrl.Result{}, err
+	}
+
+	stateBeforeApply := enactmentInstance.Status.StateBeforeApply
+	if stateBeforeApply == nil || stateBeforeApply.PolicyGeneration != policyGeneration {
+		log.Info("Missing state before applying policy generation at this node, nothing to roll back", "policyGeneration", policyGeneration)
+		return ctrl.Result{}, nil
+	}
+
+	failingCondition := enactmentInstance.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionFailing)
+	if failingCondition != nil && failingCondition.Status == corev1.ConditionTrue {
+		log.Info("Policy generation failed at this node and was already rolled back by nmstate")
+		return ctrl.Result{}, nil
+	}
+
+	if enactmentstatus.IsProgressing(&enactmentInstance.Status.Conditions) {
+		log.Info("Enactment is progressing, postponing rollback")
+		return ctrl.Result{RequeueAfter: rollbackRetryTime}, nil
+	}
+
+	err = incrementUnavailableNodeCount(r.Client, r.APIClient, r.Log, policyInstance, nil)
+	if err != nil {
+		if apierrors.IsConflict(err) || errors.Is(err, node.MaxUnavailableLimitReachedError{}) {
+			log.Info("Policy maxUnavailable limit reached, postponing rollback")
+			return ctrl.Result{RequeueAfter: rollbackRetryTime}, nil
+		}
+		log.Error(err, "Error taking a policy maxUnavailable slot for the rollback")
+		return ctrl.Result{}, err
+	}
+	rolledBack := r.rollback(enactmentKey, stateBeforeApply, policyInstance)
+	decrementUnavailableNodeCount(r.Client, r.APIClient, r.Log, policyInstance)
+
+	err = r.recordNode(request.NamespacedName, rolledBack)
+	if err != nil {
+		log.Error(err, "Error recording node at rollback status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// rollback applies the state captured before the policy generation, serialized
+// with the other applies at the node, and reports the progress at the
+// enactment, it returns true if it succeeded.
+func (r *NodeNetworkConfigurationRollbackReconciler) rollback(
+	enactmentKey types.NamespacedName,
+	stateBeforeApply *shared.NodeNetworkConfigurationEnactmentStateBeforeApply,
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+) bool {
+	log := r.Log.WithName("rollback").WithValues("enactment", enactmentKey.Name)
+	enactmentConditions := enactmentconditions.New(r.APIClient, enactmentKey)
+
+	log.Info("rolling back policy generation", "policyGeneration", stateBeforeApply.PolicyGeneration)
+	enactmentConditions.NotifyProgressing()
+	nmstateOutput, err := applyDesiredState(r.APIClient, stateBeforeApply.State, policy.Spec.Probes, nil)
+	if err != nil {
+		errmsg := fmt.Errorf("error rolling back policy %s generation %d on node %s: %q,\n %v",
+			policy.Name, stateBeforeApply.PolicyGeneration, nodeName, nmstateOutput, err)
+		enactmentConditions.NotifyFailedToRollBack(errmsg)
+		log.Error(errmsg, "rollback failed")
+		return false
+	}
+	log.Info("nmstate", "output", nmstateOutput)
+
+	enactmentConditions.NotifyRolledBack(stateBeforeApply.PolicyGeneration)
+	return true
+}
+
+// resolvePolicyGeneration returns the policy generation to roll back, the
+// first node processing the rollback stores it at the status so every node
+// rolls back the same one even if the policy changes meanwhile.
+func (r *NodeNetworkConfigurationRollbackReconciler) resolvePolicyGeneration(
+	rollbackInstance *nmstatev1beta1.NodeNetworkConfigurationRollback,
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+) (int64, error) {
+	var policyGeneration int64
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeNetworkConfigurationRollback{}
+		err := r.APIClient.Get(context.TODO(), types.NamespacedName{Name: rollbackInstance.Name}, instance)
+		if err != nil {
+			return err
+		}
+		policyGeneration = instance.Spec.PolicyGeneration
+		if policyGeneration == 0 {
+			policyGeneration = instance.Status.PolicyGeneration
+		}
+		if policyGeneration == 0 {
+			policyGeneration = policy.Generation
+		}
+		if instance.Status.PolicyGeneration == policyGeneration {
+			return nil
+		}
+		instance.Status.PolicyGeneration = policyGeneration
+		return r.APIClient.Status().Update(context.TODO(), instance)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed storing policy generation at rollback status")
+	}
+	return policyGeneration, nil
+}
+
+func (r *NodeNetworkConfigurationRollbackReconciler) recordNode(rollbackKey types.NamespacedName, rolledBack bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeNetworkConfigurationRollback{}
+		err := r.APIClient.Get(context.TODO(), rollbackKey, instance)
+		if err != nil {
+			return err
+		}
+		if isRollbackProcessed(instance) {
+			return nil
+		}
+		if rolledBack {
+			instance.Status.RolledBackNodes = append(instance.Status.RolledBackNodes, nodeName)
+		} else {
+			instance.Status.FailedNodes = append(instance.Status.FailedNodes, nodeName)
+		}
+		return r.APIClient.Status().Update(context.TODO(), instance)
+	})
+}
+
+func isRollbackProcessed(rollbackInstance *nmstatev1beta1.NodeNetworkConfigurationRollback) bool {
+	for _, nodes := range [][]string{rollbackInstance.Status.RolledBackNodes, rollbackInstance.Status.FailedNodes} {
+		for _, node := range nodes {
+			if node == nodeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *NodeNetworkConfigurationRollbackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NodeNetworkConfigurationRollback{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NNCR Reconciler")
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/client"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+)
+
+var _ = Describe("Node Network Configuration Rollback controller reconcile", func() {
+	var (
+		cl           client.Client
+		reconciler   NodeNetworkConfigurationRollbackReconciler
+		request      reconcile.Request
+		appliedState *shared.State
+		applyErr     error
+		policy       nmstatev1.NodeNetworkConfigurationPolicy
+		enactment    nmstatev1beta1.NodeNetworkConfigurationEnactment
+		rollback     nmstatev1beta1.NodeNetworkConfigurationRollback
+	)
+	BeforeEach(func() {
+		policy = nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "policy1",
+				Generation: 2,
+			},
+		}
+		enactment = nmstatev1beta1.NodeNetworkConfigurationEnactment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   shared.EnactmentKey("node01", policy.Name).Name,
+				Labels: map[string]string{shared.EnactmentPolicyLabel: policy.Name},
+			},
+			Status: shared.NodeNetworkConfigurationEnactmentStatus{
+				PolicyGeneration: 2,
+				StateBeforeApply: &shared.NodeNetworkConfigurationEnactmentStateBeforeApply{
+					PolicyGeneration: 2,
+					State:            shared.NewState("interfaces: [{name: eth1, type: ethernet, state: down}]"),
+				},
+			},
+		}
+		conditions.SetSuccess(&enactment.Status.Conditions, "")
+		rollback = nmstatev1beta1.NodeNetworkConfigurationRollback{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "rollback1",
+			},
+			Spec: shared.NodeNetworkConfigurationRollbackSpec{
+				Policy: policy.Name,
+			},
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: rollback.Name}}
+
+		appliedState = nil
+		applyErr = nil
+		applyDesiredStateFn = func(
+			_ client.Client, desiredState shared.State, _ *shared.NodeNetworkConfigurationPolicyProbes, _ nmstate.ApplyObserver,
+		) (string, error) {
+			appliedState = &desiredState
+			return "applied", applyErr
+		}
+		DeferCleanup(func() { applyDesiredStateFn = nmstate.ApplyDesiredState })
+	})
+	JustBeforeEach(func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationRollback{},
+		)
+		s.AddKnownTypes(nmstatev1.GroupVersion,
+			&nmstatev1.NodeNetworkConfigurationPolicy{},
+		)
+
+		objs := []runtime.Object{&policy, &enactment, &rollback}
+		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+		reconciler = NodeNetworkConfigurationRollbackReconciler{
+			Client:    cl,
+			APIClient: cl,
+			Log:       ctrl.Log.WithName("controllers").WithName("Rollback"),
+			Scheme:    s,
+		}
+	})
+	obtainRollback := func() nmstatev1beta1.NodeNetworkConfigurationRollback {
+		obtainedRollback := nmstatev1beta1.NodeNetworkConfigurationRollback{}
+		ExpectWithOffset(1, cl.Get(context.TODO(), types.NamespacedName{Name: rollback.Name}, &obtainedRollback)).To(Succeed())
+		return obtainedRollback
+	}
+	obtainEnactmentCondition := func(conditionType shared.ConditionType) *shared.Condition {
+		obtainedEnactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+		ExpectWithOffset(1, cl.Get(context.TODO(), types.NamespacedName{Name: enactment.Name}, &obtainedEnactment)).To(Succeed())
+		return obtainedEnactment.Status.Conditions.Find(conditionType)
+	}
+	It("should apply the state before the policy generation and record the node as rolled back", func() {
+		result, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{}))
+		Expect(appliedState).ToNot(BeNil())
+		Expect(string(appliedState.Raw)).To(MatchYAML("interfaces: [{name: eth1, type: ethernet, state: down}]"))
+
+		obtainedRollback := obtainRollback()
+		Expect(obtainedRollback.Status.PolicyGeneration).To(Equal(int64(2)))
+		Expect(obtainedRollback.Status.RolledBackNodes).To(ConsistOf("node01"))
+		Expect(obtainedRollback.Status.FailedNodes).To(BeEmpty())
+
+		abortedCondition := obtainEnactmentCondition(shared.NodeNetworkConfigurationEnactmentConditionAborted)
+		Expect(abortedCondition).ToNot(BeNil())
+		Expect(abortedCondition.Status).To(Equal(corev1.ConditionTrue))
+		Expect(abortedCondition.Reason).To(Equal(shared.NodeNetworkConfigurationEnactmentConditionRolledBack))
+	})
+	It("should release the policy maxUnavailable slot after rolling back", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+
+		obtainedPolicy := nmstatev1.NodeNetworkConfigurationPolicy{}
+		Expect(cl.Get(context.TODO(), types.NamespacedName{Name: policy.Name}, &obtainedPolicy)).To(Succeed())
+		Expect(obtainedPolicy.Status.UnavailableNodeCount).To(Equal(0))
+		Expect(obtainedPolicy.Status.LastUnavailableNodeCountUpdate).ToNot(BeNil())
+	})
+	Context("and the policy maxUnavailable limit is reached", func() {
+		BeforeEach(func() {
+			policy.Status.UnavailableNodeCount = 1
+		})
+		It("should postpone the rollback", func() {
+			result, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(rollbackRetryTime))
+			Expect(appliedState).To(BeNil())
+			Expect(obtainRollback().Status.RolledBackNodes).To(BeEmpty())
+		})
+	})
+	Context("and another desired state is being applied at the node", func() {
+		It("should wait for it to finish before rolling back", func() {
+			applyLock.Lock()
+			locked := true
+			DeferCleanup(func() {
+				if locked {
+					applyLock.Unlock()
+				}
+			})
+			rolledBack := make(chan error)
+			go func() {
+				defer GinkgoRecover()
+				_, err := reconciler.Reconcile(context.Background(), request)
+				rolledBack <- err
+			}()
+			Consistently(rolledBack, "200ms").ShouldNot(Receive())
+			locked = false
+			applyLock.Unlock()
+			Eventually(rolledBack).Should(Receive(BeNil()))
+			Expect(appliedState).ToNot(BeNil())
+		})
+	})
+	Context("and the node is already recorded at the rollback", func() {
+		BeforeEach(func() {
+			rollback.Status.RolledBackNodes = []string{"node01"}
+		})
+		It("should not apply anything", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(appliedState).To(BeNil())
+		})
+	})
+	Context("and apply fails", func() {
+		BeforeEach(func() {
+			applyErr = fmt.Errorf("probes failed")
+		})
+		It("should record the node as failed and mark the enactment as failed to roll back", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			obtainedRollback := obtainRollback()
+			Expect(obtainedRollback.Status.RolledBackNodes).To(BeEmpty())
+			Expect(obtainedRollback.Status.FailedNodes).To(ConsistOf("node01"))
+
+			failingCondition := obtainEnactmentCondition(shared.NodeNetworkConfigurationEnactmentConditionFailing)
+			Expect(failingCondition).ToNot(BeNil())
+			Expect(failingCondition.Reason).To(Equal(shared.NodeNetworkConfigurationEnactmentConditionFailedToRollBack))
+		})
+	})
+	Context("and the requested generation was not captured at the node", func() {
+		BeforeEach(func() {
+			rollback.Spec.PolicyGeneration = 1
+		})
+		It("should not apply anything", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(appliedState).To(BeNil())
+			Expect(obtainRollback().Status.PolicyGeneration).To(Equal(int64(1)))
+		})
+	})
+	Context("and the policy generation failed at the node", func() {
+		BeforeEach(func() {
+			conditions.SetFailedToConfigure(&enactment.Status.Conditions, "failed")
+		})
+		It("should not apply anything", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(appliedState).To(BeNil())
+		})
+	})
+	Context("and the enactment is progressing", func() {
+		BeforeEach(func() {
+			conditions.SetProgressing(&enactment.Status.Conditions, "")
+		})
+		It("should postpone the rollback", func() {
+			result, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(rollbackRetryTime))
+			Expect(appliedState).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,171 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+)
+
+// NodeIPPoolReconciler releases the NodeIPPool addresses of deleted nodes
+// and of policies that are deleted or do not reference the pool anymore.
+// The handler of a deleted node is not running anymore, so it runs with the
+// webhook.
+type NodeIPPoolReconciler struct {
+	client.Client
+	// APIClient controller-runtime client without cache, used to check
+	// that nodes and policies are gone before releasing their addresses.
+	APIClient client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Store     ippool.Store
+}
+
+// Reconcile releases the unused allocations of a NodeIPPool, or all of them
+// if the pool has been deleted.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *NodeIPPoolReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("nodeippool", request.NamespacedName)
+
+	poolInstance := &nmstatev1beta1.NodeIPPool{}
+	err := r.APIClient.Get(ctx, request.NamespacedName, poolInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("NodeIPPool is deleted, releasing its addresses")
+			return ctrl.Result{}, ippool.ReleasePool(ctx, r.Store, request.Name)
+		}
+		log.Error(err, "Error retrieving NodeIPPool")
+		return ctrl.Result{}, err
+	}
+
+	isUsed := r.isUsedFn(ctx, request.Name)
+	if err := ippool.Release(ctx, r.Store, request.Name, isUsed); err != nil {
+		log.Error(err, "Error releasing NodeIPPool addresses")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// isUsedFn returns a function telling if the node still uses the pool
+// addresses for the policy, the nodes and policies are only retrieved once.
+func (r *NodeIPPoolReconciler) isUsedFn(ctx context.Context, pool string) func(node, policy string) (bool, error) {
+	nodeExists := map[string]bool{}
+	policyUsesPool := map[string]bool{}
+	return func(node, policy string) (bool, error) {
+		exists, found := nodeExists[node]
+		if !found {
+			err := r.APIClient.Get(ctx, types.NamespacedName{Name: node}, &corev1.Node{})
+			if client.IgnoreNotFound(err) != nil {
+				return false, errors.Wrapf(err, "failed getting node %s", node)
+			}
+			exists = err == nil
+			nodeExists[node] = exists
+		}
+		if !exists {
+			return false, nil
+		}
+
+		usesPool, found := policyUsesPool[policy]
+		if !found {
+			policyInstance := &nmstatev1.NodeNetworkConfigurationPolicy{}
+			err := r.APIClient.Get(ctx, types.NamespacedName{Name: policy}, policyInstance)
+			if client.IgnoreNotFound(err) != nil {
+				return false, errors.Wrapf(err, "failed getting policy %s", policy)
+			}
+			if err == nil {
+				pools, err := ippool.References(policyInstance.Spec.DesiredState)
+				if err != nil {
+					// keep the address until the desired state is fixed
+					return true, nil
+				}
+				usesPool = contains(pools, pool)
+			}
+			policyUsesPool[policy] = usesPool
+		}
+		return usesPool, nil
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *NodeIPPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	allPools := handler.EnqueueRequestsFromMapFunc(
+		func(client.Object) []reconcile.Request {
+			log := r.Log.WithName("allPools")
+			allPoolsAsRequest := []reconcile.Request{}
+			poolList := nmstatev1beta1.NodeIPPoolList{}
+			err := r.Client.List(context.TODO(), &poolList)
+			if err != nil {
+				log.Error(err, "failed listing all NodeIPPools to release their addresses")
+				return allPoolsAsRequest
+			}
+			for i := range poolList.Items {
+				allPoolsAsRequest = append(allPoolsAsRequest, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: poolList.Items[i].Name},
+				})
+			}
+			return allPoolsAsRequest
+		})
+
+	onDelete := predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	onDeleteOrGenerationChange := predicate.Or(onDelete, predicate.GenerationChangedPredicate{})
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NodeIPPool{}).
+		Watches(&source.Kind{Type: &corev1.Node{}}, allPools, builder.WithPredicates(onDelete)).
+		Watches(&source.Kind{Type: &nmstatev1.NodeNetworkConfigurationPolicy{}}, allPools, builder.WithPredicates(onDeleteOrGenerationChange)).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NodeIPPool Reconciler")
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+)
+
+var _ = Describe("NodeIPPool controller reconcile", func() {
+	const storageDesiredState = `
+interfaces:
+- name: eth1
+  ipv4:
+    address:
+    - "{{ ippool.storage }}"
+`
+	var (
+		cl         client.Client
+		store      ippool.Store
+		reconciler NodeIPPoolReconciler
+		request    = reconcile.Request{NamespacedName: types.NamespacedName{Name: "storage"}}
+		ctx        = context.TODO()
+		objs       []runtime.Object
+	)
+	policy := func(name, desiredState string) *nmstatev1.NodeNetworkConfigurationPolicy {
+		return &nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       shared.NodeNetworkConfigurationPolicySpec{DesiredState: shared.NewState(desiredState)},
+		}
+	}
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	BeforeEach(func() {
+		objs = []runtime.Object{
+			&nmstatev1beta1.NodeIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+				Spec:       shared.NodeIPPoolSpec{Range: shared.NodeIPPoolRange{CIDR: "10.10.10.0/24"}},
+			},
+			node("node01"),
+			node("node02"),
+			policy("storage", storageDesiredState),
+			policy("storage-unreferenced", "interfaces: []"),
+		}
+	})
+	JustBeforeEach(func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{})
+		s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NodeNetworkConfigurationPolicy{})
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeIPPool{},
+			&nmstatev1beta1.NodeIPAllocation{},
+			&nmstatev1beta1.NodeIPAllocationList{},
+		)
+		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+		var err error
+		store, err = ippool.NewStore(ippool.CRDStoreName, cl)
+		Expect(err).ToNot(HaveOccurred())
+		reconciler = NodeIPPoolReconciler{
+			Client:    cl,
+			APIClient: cl,
+			Log:       ctrl.Log.WithName("controllers").WithName("NodeIPPool"),
+			Scheme:    s,
+			Store:     store,
+		}
+
+		for _, allocation := range []ippool.Allocation{
+			{Pool: "storage", Node: "node01", Address: "10.10.10.1/24", Policies: []string{"storage"}},
+			{Pool: "storage", Node: "node02", Address: "10.10.10.2/24", Policies: []string{"storage", "storage-unreferenced"}},
+			{Pool: "storage", Node: "node03", Address: "10.10.10.3/24", Policies: []string{"storage"}},
+			{Pool: "storage", Node: "node01", Address: "10.10.10.4/24", Policies: []string{"deleted"}},
+		} {
+			Expect(store.Create(ctx, allocation)).To(Succeed())
+		}
+	})
+	allocatedAddresses := func() map[string][]string {
+		allocations, err := store.List(ctx, "storage")
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		addresses := map[string][]string{}
+		for _, allocation := range allocations {
+			addresses[allocation.Address] = allocation.Policies
+		}
+		return addresses
+	}
+	It("should release the addresses of deleted nodes and policies not using them", func() {
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allocatedAddresses()).To(Equal(map[string][]string{
+			"10.10.10.1/24": {"storage"},
+			"10.10.10.2/24": {"storage"},
+		}))
+	})
+	Context("when the pool is deleted", func() {
+		BeforeEach(func() {
+			objs = objs[1:]
+		})
+		It("should release all its addresses", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allocatedAddresses()).To(BeEmpty())
+		})
+	})
+})
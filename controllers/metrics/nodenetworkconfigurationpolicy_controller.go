@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
+)
+
+var policyConditionTypes = []shared.ConditionType{
+	shared.NodeNetworkConfigurationPolicyConditionAvailable,
+	shared.NodeNetworkConfigurationPolicyConditionDegraded,
+	shared.NodeNetworkConfigurationPolicyConditionProgressing,
+}
+
+// NodeNetworkConfigurationPolicyReconciler reports the conditions of the
+// NodeNetworkConfigurationPolicies as metrics
+type NodeNetworkConfigurationPolicyReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("metrics.nodenetworkconfigurationpolicy", request.NamespacedName)
+
+	policy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(ctx, request.NamespacedName, policy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			for _, conditionType := range policyConditionTypes {
+				monitoring.PolicyCondition.DeleteLabelValues(request.Name, string(conditionType))
+			}
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving policy")
+		return ctrl.Result{}, err
+	}
+
+	for _, conditionType := range policyConditionTypes {
+		value := 0.0
+		condition := policy.Status.Conditions.Find(conditionType)
+		if condition != nil && condition.Status == corev1.ConditionTrue {
+			value = 1.0
+		}
+		monitoring.PolicyCondition.WithLabelValues(policy.Name, string(conditionType)).Set(value)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1.NodeNetworkConfigurationPolicy{}).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NNCP metrics Reconciler")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+	"github.com/nmstate/kubernetes-nmstate/pkg/state"
+)
+
+// NodeNetworkStateReconciler reports the network inventory of the nodes
+// from their NodeNetworkStates as metrics
+type NodeNetworkStateReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *NodeNetworkStateReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("metrics.nodenetworkstate", request.NamespacedName)
+
+	nns := &nmstatev1beta1.NodeNetworkState{}
+	err := r.Client.Get(ctx, request.NamespacedName, nns)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteInventory(request.Name)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving NodeNetworkState")
+		return ctrl.Result{}, err
+	}
+
+	inventory, err := state.NewInventory(nns.Status.CurrentState)
+	if err != nil {
+		log.Error(err, "failed building network inventory from current state")
+		deleteInventory(nns.Name)
+		return ctrl.Result{}, nil
+	}
+	reportInventory(nns.Name, inventory)
+	return ctrl.Result{}, nil
+}
+
+func (r *NodeNetworkStateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NodeNetworkState{}).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NNS metrics Reconciler")
+	}
+	return nil
+}
+
+func deleteInventory(nodeName string) {
+	for _, gauge := range monitoring.InventoryGauges() {
+		gauge.DeletePartialMatch(prometheus.Labels{"node": nodeName})
+	}
+}
+
+// reportInventory replaces the node metrics so the ones from removed
+// interfaces are not reported anymore.
+func reportInventory(nodeName string, inventory *state.Inventory) {
+	deleteInventory(nodeName)
+	for i := range inventory.Interfaces {
+		iface := &inventory.Interfaces[i]
+		monitoring.InterfaceUp.WithLabelValues(nodeName, iface.Name, iface.Type).Set(boolToFloat(iface.Up))
+		if iface.MTU != nil {
+			monitoring.InterfaceMTU.WithLabelValues(nodeName, iface.Name).Set(float64(*iface.MTU))
+		}
+		monitoring.InterfaceAddresses.WithLabelValues(nodeName, iface.Name, monitoring.FamilyIPv4).Set(float64(iface.IPv4Addresses))
+		monitoring.InterfaceAddresses.WithLabelValues(nodeName, iface.Name, monitoring.FamilyIPv6).Set(float64(iface.IPv6Addresses))
+		if iface.Type == string(schema.InterfaceTypeBond) {
+			monitoring.BondPorts.WithLabelValues(nodeName, iface.Name).Set(float64(iface.BondPorts))
+		}
+	}
+	monitoring.DefaultRoute.WithLabelValues(nodeName, monitoring.FamilyIPv4).Set(boolToFloat(inventory.DefaultRouteIPv4))
+	monitoring.DefaultRoute.WithLabelValues(nodeName, monitoring.FamilyIPv6).Set(boolToFloat(inventory.DefaultRouteIPv6))
+	monitoring.DNSServers.WithLabelValues(nodeName).Set(float64(inventory.DNSServers))
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
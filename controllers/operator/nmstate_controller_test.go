@@ -518,14 +518,22 @@ func copyManifest(src, dst string) error {
 
 func copyManifests(manifestsDir string) error {
 	srcToDest := map[string]string{
-		"../../deploy/crds/nmstate.io_nodenetworkconfigurationenactments.yaml": "kubernetes-nmstate/crds/",
-		"../../deploy/crds/nmstate.io_nodenetworkconfigurationpolicies.yaml":   "kubernetes-nmstate/crds/",
-		"../../deploy/crds/nmstate.io_nodenetworkstates.yaml":                  "kubernetes-nmstate/crds/",
-		"../../deploy/handler/namespace.yaml":                                  "kubernetes-nmstate/namespace/",
-		"../../deploy/handler/operator.yaml":                                   "kubernetes-nmstate/handler/handler.yaml",
-		"../../deploy/handler/service_account.yaml":                            "kubernetes-nmstate/rbac/",
-		"../../deploy/handler/role.yaml":                                       "kubernetes-nmstate/rbac/",
-		"../../deploy/handler/role_binding.yaml":                               "kubernetes-nmstate/rbac/",
+		"../../deploy/crds/nmstate.io_namespacednodenetworkconfigurationpolicies.yaml":      "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodeipallocations.yaml":                               "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodeippools.yaml":                                     "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationenactments.yaml":              "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationpolicies.yaml":                "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationpolicytemplateinstances.yaml": "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationpolicytemplates.yaml":         "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationquotas.yaml":                  "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationpreviews.yaml":                "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkconfigurationrollbacks.yaml":               "kubernetes-nmstate/crds/",
+		"../../deploy/crds/nmstate.io_nodenetworkstates.yaml":                               "kubernetes-nmstate/crds/",
+		"../../deploy/handler/namespace.yaml":                                               "kubernetes-nmstate/namespace/",
+		"../../deploy/handler/operator.yaml":                                                "kubernetes-nmstate/handler/handler.yaml",
+		"../../deploy/handler/service_account.yaml":                                         "kubernetes-nmstate/rbac/",
+		"../../deploy/handler/role.yaml":                                                    "kubernetes-nmstate/rbac/",
+		"../../deploy/handler/role_binding.yaml":                                            "kubernetes-nmstate/rbac/",
 	}
 
 	for src, dest := range srcToDest {
@@ -0,0 +1,256 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policytemplate"
+)
+
+// NodeNetworkConfigurationPolicyTemplateInstanceReconciler renders the
+// NodeNetworkConfigurationPolicy of every instance from its template, and
+// renders it again when the instance or the template change. The policy
+// changes are rolled out to the nodes as any other policy update.
+type NodeNetworkConfigurationPolicyTemplateInstanceReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile creates or updates the policy rendered by the instance and
+// reports the failures at the instance status.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *NodeNetworkConfigurationPolicyTemplateInstanceReconciler) Reconcile(
+	ctx context.Context,
+	request ctrl.Request,
+) (ctrl.Result, error) {
+	log := r.Log.WithValues("nodenetworkconfigurationpolicytemplateinstance", request.NamespacedName)
+
+	instance := &nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance{}
+	err := r.Client.Get(ctx, request.NamespacedName, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The rendered policy is removed by the garbage collector
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving NodeNetworkConfigurationPolicyTemplateInstance")
+		return ctrl.Result{}, err
+	}
+
+	template := &nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: instance.Spec.TemplateRef.Name}, template)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = fmt.Errorf("NodeNetworkConfigurationPolicyTemplate %s not found", instance.Spec.TemplateRef.Name)
+			return ctrl.Result{}, r.updateStatus(ctx, instance, 0, err)
+		}
+		log.Error(err, "Error retrieving NodeNetworkConfigurationPolicyTemplate")
+		return ctrl.Result{}, err
+	}
+
+	policySpec, err := renderPolicySpec(instance, template)
+	if err != nil {
+		log.Info("failed rendering template", "error", err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, instance, template.Generation, err)
+	}
+
+	err = r.applyPolicy(ctx, instance, template, policySpec)
+	if err != nil {
+		log.Error(err, "Error applying rendered NodeNetworkConfigurationPolicy")
+	}
+	if statusErr := r.updateStatus(ctx, instance, template.Generation, err); statusErr != nil {
+		return ctrl.Result{}, statusErr
+	}
+	return ctrl.Result{}, err
+}
+
+// renderPolicySpec returns the instance policy spec with the capture and
+// desired state rendered from the template.
+func renderPolicySpec(
+	instance *nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance,
+	template *nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate,
+) (shared.NodeNetworkConfigurationPolicySpec, error) {
+	policySpec := instance.Spec.Policy
+	if len(policySpec.Capture) > 0 || !isEmptyState(policySpec.DesiredState) {
+		return policySpec, errors.New("policy capture and desiredState are rendered from the template and cannot be set")
+	}
+	capture, desiredState, err := policytemplate.Render(&template.Spec, instance.Spec.Parameters)
+	if err != nil {
+		return policySpec, errors.Wrapf(err, "failed rendering NodeNetworkConfigurationPolicyTemplate %s", template.Name)
+	}
+	policySpec.Capture = capture
+	policySpec.DesiredState = desiredState
+	return policySpec, nil
+}
+
+// applyPolicy creates the policy owned by the instance or updates it if it
+// differs from the rendered one. The webhook rejects updates while the
+// policy is in progress, they are retried.
+func (r *NodeNetworkConfigurationPolicyTemplateInstanceReconciler) applyPolicy(
+	ctx context.Context,
+	instance *nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance,
+	template *nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate,
+	policySpec shared.NodeNetworkConfigurationPolicySpec,
+) error {
+	policy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name}, policy)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed retrieving NodeNetworkConfigurationPolicy %s", instance.Name)
+		}
+		policy = &nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   instance.Name,
+				Labels: map[string]string{shared.PolicyTemplateLabel: template.Name},
+			},
+			Spec: policySpec,
+		}
+		if err = controllerutil.SetControllerReference(instance, policy, r.Scheme); err != nil {
+			return errors.Wrap(err, "failed setting NodeNetworkConfigurationPolicy owner")
+		}
+		if err = r.Client.Create(ctx, policy); err != nil {
+			return errors.Wrapf(err, "failed creating NodeNetworkConfigurationPolicy %s", policy.Name)
+		}
+		return nil
+	}
+
+	if !metav1.IsControlledBy(policy, instance) {
+		return fmt.Errorf("NodeNetworkConfigurationPolicy %s already exists and is not rendered by this instance", policy.Name)
+	}
+	if policy.Labels[shared.PolicyTemplateLabel] == template.Name && equalPolicySpecs(policy.Spec, policySpec) {
+		return nil
+	}
+	if policy.Labels == nil {
+		policy.Labels = map[string]string{}
+	}
+	policy.Labels[shared.PolicyTemplateLabel] = template.Name
+	policy.Spec = policySpec
+	if err = r.Client.Update(ctx, policy); err != nil {
+		return errors.Wrapf(err, "failed updating NodeNetworkConfigurationPolicy %s", policy.Name)
+	}
+	return nil
+}
+
+// isEmptyState returns true for missing desired states, they are stored as
+// null.
+func isEmptyState(state shared.State) bool {
+	var value interface{}
+	if err := yaml.Unmarshal(state.Raw, &value); err != nil {
+		return false
+	}
+	return value == nil
+}
+
+// equalPolicySpecs compares the desired states by content, since they are
+// formatted again when they are stored.
+func equalPolicySpecs(spec, other shared.NodeNetworkConfigurationPolicySpec) bool {
+	var state, otherState interface{}
+	if err := yaml.Unmarshal(spec.DesiredState.Raw, &state); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal(other.DesiredState.Raw, &otherState); err != nil {
+		return false
+	}
+	spec.DesiredState, other.DesiredState = shared.State{}, shared.State{}
+	return reflect.DeepEqual(state, otherState) && reflect.DeepEqual(spec, other)
+}
+
+func (r *NodeNetworkConfigurationPolicyTemplateInstanceReconciler) updateStatus(
+	ctx context.Context,
+	instance *nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance,
+	templateGeneration int64,
+	renderErr error,
+) error {
+	status := shared.NodeNetworkConfigurationPolicyTemplateInstanceStatus{
+		ObservedGeneration: instance.Generation,
+		TemplateGeneration: templateGeneration,
+	}
+	if renderErr != nil {
+		status.Error = renderErr.Error()
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name}, current); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if reflect.DeepEqual(current.Status, status) {
+			return nil
+		}
+		current.Status = status
+		return r.Client.Status().Update(ctx, current)
+	})
+}
+
+func (r *NodeNetworkConfigurationPolicyTemplateInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	templateInstances := handler.EnqueueRequestsFromMapFunc(
+		func(template client.Object) []reconcile.Request {
+			log := r.Log.WithName("templateInstances")
+			requests := []reconcile.Request{}
+			instanceList := nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstanceList{}
+			err := r.Client.List(context.TODO(), &instanceList)
+			if err != nil {
+				log.Error(err, "failed listing NodeNetworkConfigurationPolicyTemplateInstances to render them")
+				return requests
+			}
+			for i := range instanceList.Items {
+				if instanceList.Items[i].Spec.TemplateRef.Name == template.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: instanceList.Items[i].Name},
+					})
+				}
+			}
+			return requests
+		})
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&nmstatev1.NodeNetworkConfigurationPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate{}}, templateInstances).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NodeNetworkConfigurationPolicyTemplateInstance Reconciler")
+	}
+
+	return nil
+}
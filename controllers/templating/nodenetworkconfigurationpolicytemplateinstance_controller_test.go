@@ -0,0 +1,211 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templating
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var _ = Describe("NodeNetworkConfigurationPolicyTemplateInstance controller reconcile", func() {
+	var (
+		cl         client.Client
+		reconciler NodeNetworkConfigurationPolicyTemplateInstanceReconciler
+		request    = reconcile.Request{NamespacedName: types.NamespacedName{Name: "rack1-vlan"}}
+		ctx        = context.TODO()
+		template   nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate
+		instance   nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance
+		objs       []runtime.Object
+	)
+	BeforeEach(func() {
+		template = nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "bond-vlan", Generation: 1},
+			Spec: shared.NodeNetworkConfigurationPolicyTemplateSpec{
+				Parameters: []shared.NodeNetworkConfigurationPolicyTemplateParameter{{Name: "vlan"}},
+				DesiredState: shared.NewState(`
+interfaces:
+- name: bond0.{{ parameters.vlan }}
+  type: vlan
+  state: up
+  vlan:
+    base-iface: bond0
+    id: "{{ parameters.vlan }}"
+`),
+			},
+		}
+		instance = nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "rack1-vlan", Generation: 1, UID: "instance-uid"},
+			Spec: shared.NodeNetworkConfigurationPolicyTemplateInstanceSpec{
+				TemplateRef: shared.NodeNetworkConfigurationPolicyTemplateRef{Name: "bond-vlan"},
+				Parameters:  map[string]string{"vlan": "100"},
+				Policy: shared.NodeNetworkConfigurationPolicySpec{
+					NodeSelector: map[string]string{"rack": "r1"},
+				},
+			},
+		}
+		objs = []runtime.Object{}
+	})
+	JustBeforeEach(func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NodeNetworkConfigurationPolicy{})
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance{},
+		)
+		objs = append(objs, &template, &instance)
+		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+		reconciler = NodeNetworkConfigurationPolicyTemplateInstanceReconciler{
+			Client: cl,
+			Log:    ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicyTemplateInstance"),
+			Scheme: s,
+		}
+	})
+	obtainPolicy := func() *nmstatev1.NodeNetworkConfigurationPolicy {
+		policy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+		ExpectWithOffset(1, cl.Get(ctx, types.NamespacedName{Name: "rack1-vlan"}, policy)).To(Succeed())
+		return policy
+	}
+	obtainInstanceStatus := func() shared.NodeNetworkConfigurationPolicyTemplateInstanceStatus {
+		obtainedInstance := &nmstatev1beta1.NodeNetworkConfigurationPolicyTemplateInstance{}
+		ExpectWithOffset(1, cl.Get(ctx, types.NamespacedName{Name: "rack1-vlan"}, obtainedInstance)).To(Succeed())
+		return obtainedInstance.Status
+	}
+	It("should create the policy rendered from the template", func() {
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		policy := obtainPolicy()
+		Expect(policy.Labels).To(HaveKeyWithValue(shared.PolicyTemplateLabel, "bond-vlan"))
+		Expect(policy.OwnerReferences).To(HaveLen(1))
+		Expect(policy.OwnerReferences[0].UID).To(Equal(instance.UID))
+		Expect(policy.Spec.NodeSelector).To(Equal(map[string]string{"rack": "r1"}))
+		Expect(policy.Spec.DesiredState.String()).To(MatchYAML(`
+interfaces:
+- name: bond0.100
+  type: vlan
+  state: up
+  vlan:
+    base-iface: bond0
+    id: 100
+`))
+		Expect(obtainInstanceStatus()).To(Equal(shared.NodeNetworkConfigurationPolicyTemplateInstanceStatus{
+			ObservedGeneration: 1,
+			TemplateGeneration: 1,
+		}))
+	})
+	Context("when the template changes", func() {
+		JustBeforeEach(func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedTemplate := &nmstatev1beta1.NodeNetworkConfigurationPolicyTemplate{}
+			Expect(cl.Get(ctx, types.NamespacedName{Name: template.Name}, updatedTemplate)).To(Succeed())
+			updatedTemplate.Generation = 2
+			updatedTemplate.Spec.DesiredState = shared.NewState(`
+interfaces:
+- name: bond0.{{ parameters.vlan }}
+  type: vlan
+  state: up
+  mtu: 9000
+  vlan:
+    base-iface: bond0
+    id: "{{ parameters.vlan }}"
+`)
+			Expect(cl.Update(ctx, updatedTemplate)).To(Succeed())
+		})
+		It("should render the policy again", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainPolicy().Spec.DesiredState.String()).To(ContainSubstring("mtu: 9000"))
+			Expect(obtainInstanceStatus().TemplateGeneration).To(Equal(int64(2)))
+		})
+	})
+	Context("when the policy is already rendered", func() {
+		It("should not update it", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			resourceVersion := obtainPolicy().ResourceVersion
+
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainPolicy().ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+	Context("when a parameter is missing", func() {
+		BeforeEach(func() {
+			instance.Spec.Parameters = nil
+		})
+		It("should report it at the instance status without creating the policy", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainInstanceStatus().Error).To(Equal(
+				`failed rendering NodeNetworkConfigurationPolicyTemplate bond-vlan: missing parameters "vlan"`))
+			Expect(cl.Get(ctx, types.NamespacedName{Name: "rack1-vlan"}, &nmstatev1.NodeNetworkConfigurationPolicy{})).ToNot(Succeed())
+		})
+	})
+	Context("when the instance sets the desired state", func() {
+		BeforeEach(func() {
+			instance.Spec.Policy.DesiredState = shared.NewState("interfaces: []")
+		})
+		It("should report it at the instance status", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainInstanceStatus().Error).To(Equal(
+				"policy capture and desiredState are rendered from the template and cannot be set"))
+		})
+	})
+	Context("when the template does not exist", func() {
+		BeforeEach(func() {
+			instance.Spec.TemplateRef.Name = "missing"
+		})
+		It("should report it at the instance status", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainInstanceStatus().Error).To(Equal("NodeNetworkConfigurationPolicyTemplate missing not found"))
+		})
+	})
+	Context("when a policy with the same name is not rendered by the instance", func() {
+		BeforeEach(func() {
+			objs = append(objs, &nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "rack1-vlan"},
+				Spec:       shared.NodeNetworkConfigurationPolicySpec{DesiredState: shared.NewState("interfaces: []")},
+			})
+		})
+		It("should not modify it and report it at the instance status", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).To(HaveOccurred())
+			Expect(obtainInstanceStatus().Error).To(Equal(
+				"NodeNetworkConfigurationPolicy rack1-vlan already exists and is not rendered by this instance"))
+			Expect(obtainPolicy().Spec.DesiredState.String()).To(MatchYAML("interfaces: []"))
+		})
+	})
+})
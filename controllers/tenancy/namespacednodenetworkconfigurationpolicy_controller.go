@@ -0,0 +1,314 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/tenancy"
+)
+
+// clusterPolicyFinalizer removes the cluster policy of a namespaced policy
+// before it is deleted, namespaced objects cannot own cluster ones.
+const clusterPolicyFinalizer = "nmstate.io/cluster-policy"
+
+// NamespacedNodeNetworkConfigurationPolicyReconciler applies every
+// namespaced policy allowed by the namespace quotas as a cluster
+// NodeNetworkConfigurationPolicy, so the handlers reconcile it as any other
+// policy, and reports the cluster policy conditions at the namespaced
+// policy status.
+type NamespacedNodeNetworkConfigurationPolicyReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile creates, updates or deletes the cluster policy of the namespaced
+// policy. If the namespace quotas no longer allow the namespaced policy the
+// cluster policy is kept as it is and the failure is reported at the
+// namespaced policy status.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *NamespacedNodeNetworkConfigurationPolicyReconciler) Reconcile(
+	ctx context.Context,
+	request ctrl.Request,
+) (ctrl.Result, error) {
+	log := r.Log.WithValues("namespacednodenetworkconfigurationpolicy", request.NamespacedName)
+
+	policy := &nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(ctx, request.NamespacedName, policy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Error retrieving NamespacedNodeNetworkConfigurationPolicy")
+		return ctrl.Result{}, err
+	}
+
+	if !policy.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.deleteClusterPolicy(ctx, policy)
+	}
+
+	if !controllerutil.ContainsFinalizer(policy, clusterPolicyFinalizer) {
+		controllerutil.AddFinalizer(policy, clusterPolicyFinalizer)
+		if err = r.Client.Update(ctx, policy); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed adding finalizer to NamespacedNodeNetworkConfigurationPolicy")
+		}
+	}
+
+	quotaList := nmstatev1beta1.NodeNetworkConfigurationQuotaList{}
+	if err = r.Client.List(ctx, &quotaList); err != nil {
+		log.Error(err, "Error listing NodeNetworkConfigurationQuotas")
+		return ctrl.Result{}, err
+	}
+
+	if errs := tenancy.Validate(quotaList.Items, policy.Namespace, &policy.Spec, field.NewPath("spec")); len(errs) > 0 {
+		log.Info("namespaced policy is not allowed by the namespace quotas", "error", errs.ToAggregate().Error())
+		clusterPolicy, err := r.clusterPolicy(ctx, policy)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.updateStatus(ctx, policy, clusterPolicy, errs.ToAggregate())
+	}
+
+	clusterPolicy, err := r.applyClusterPolicy(ctx, policy)
+	if err != nil {
+		log.Error(err, "Error applying cluster NodeNetworkConfigurationPolicy")
+	}
+	if statusErr := r.updateStatus(ctx, policy, clusterPolicy, err); statusErr != nil {
+		return ctrl.Result{}, statusErr
+	}
+	return ctrl.Result{}, err
+}
+
+// clusterPolicy returns the cluster policy applying the namespaced policy or
+// nil if there is none.
+func (r *NamespacedNodeNetworkConfigurationPolicyReconciler) clusterPolicy(
+	ctx context.Context,
+	policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy,
+) (*nmstatev1.NodeNetworkConfigurationPolicy, error) {
+	name := tenancy.ClusterPolicyName(policy.Namespace, policy.Name)
+	clusterPolicy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name}, clusterPolicy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed retrieving NodeNetworkConfigurationPolicy %s", name)
+	}
+	if !appliesPolicy(clusterPolicy, policy) {
+		return nil, nil
+	}
+	return clusterPolicy, nil
+}
+
+// applyClusterPolicy creates the cluster policy of the namespaced policy or
+// updates it if it differs. The webhook rejects updates while the policy is
+// in progress, they are retried.
+func (r *NamespacedNodeNetworkConfigurationPolicyReconciler) applyClusterPolicy(
+	ctx context.Context,
+	policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy,
+) (*nmstatev1.NodeNetworkConfigurationPolicy, error) {
+	name := tenancy.ClusterPolicyName(policy.Namespace, policy.Name)
+	clusterSpec := tenancy.ClusterPolicySpec(policy.Namespace, &policy.Spec)
+
+	clusterPolicy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name}, clusterPolicy)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed retrieving NodeNetworkConfigurationPolicy %s", name)
+		}
+		clusterPolicy = &nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					shared.NamespacedPolicyNamespaceLabel: policy.Namespace,
+					shared.NamespacedPolicyNameLabel:      policy.Name,
+				},
+			},
+			Spec: clusterSpec,
+		}
+		if err = r.Client.Create(ctx, clusterPolicy); err != nil {
+			return nil, errors.Wrapf(err, "failed creating NodeNetworkConfigurationPolicy %s", name)
+		}
+		return clusterPolicy, nil
+	}
+
+	if !appliesPolicy(clusterPolicy, policy) {
+		return nil, fmt.Errorf("NodeNetworkConfigurationPolicy %s already exists and does not apply this namespaced policy", name)
+	}
+	if equalPolicySpecs(clusterPolicy.Spec, clusterSpec) {
+		return clusterPolicy, nil
+	}
+	clusterPolicy.Spec = clusterSpec
+	if err = r.Client.Update(ctx, clusterPolicy); err != nil {
+		return clusterPolicy, errors.Wrapf(err, "failed updating NodeNetworkConfigurationPolicy %s", name)
+	}
+	return clusterPolicy, nil
+}
+
+// deleteClusterPolicy deletes the cluster policy of a namespaced policy being
+// deleted and removes the finalizer. The interfaces stay configured at the
+// nodes as with any other deleted policy.
+func (r *NamespacedNodeNetworkConfigurationPolicyReconciler) deleteClusterPolicy(
+	ctx context.Context,
+	policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy,
+) error {
+	if !controllerutil.ContainsFinalizer(policy, clusterPolicyFinalizer) {
+		return nil
+	}
+	clusterPolicy, err := r.clusterPolicy(ctx, policy)
+	if err != nil {
+		return err
+	}
+	if clusterPolicy != nil {
+		if err = r.Client.Delete(ctx, clusterPolicy); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "failed deleting NodeNetworkConfigurationPolicy %s", clusterPolicy.Name)
+		}
+	}
+	controllerutil.RemoveFinalizer(policy, clusterPolicyFinalizer)
+	if err = r.Client.Update(ctx, policy); err != nil {
+		return errors.Wrap(err, "failed removing finalizer from NamespacedNodeNetworkConfigurationPolicy")
+	}
+	return nil
+}
+
+// appliesPolicy returns true if the cluster policy was created for the
+// namespaced policy.
+func appliesPolicy(
+	clusterPolicy *nmstatev1.NodeNetworkConfigurationPolicy,
+	policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy,
+) bool {
+	return clusterPolicy.Labels[shared.NamespacedPolicyNamespaceLabel] == policy.Namespace &&
+		clusterPolicy.Labels[shared.NamespacedPolicyNameLabel] == policy.Name
+}
+
+// equalPolicySpecs compares the desired states by content, since they are
+// formatted again when they are stored.
+func equalPolicySpecs(spec, other shared.NodeNetworkConfigurationPolicySpec) bool {
+	var state, otherState interface{}
+	if err := yaml.Unmarshal(spec.DesiredState.Raw, &state); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal(other.DesiredState.Raw, &otherState); err != nil {
+		return false
+	}
+	spec.DesiredState, other.DesiredState = shared.State{}, shared.State{}
+	return reflect.DeepEqual(state, otherState) && reflect.DeepEqual(spec, other)
+}
+
+func (r *NamespacedNodeNetworkConfigurationPolicyReconciler) updateStatus(
+	ctx context.Context,
+	policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy,
+	clusterPolicy *nmstatev1.NodeNetworkConfigurationPolicy,
+	policyErr error,
+) error {
+	status := shared.NamespacedNodeNetworkConfigurationPolicyStatus{}
+	if clusterPolicy != nil {
+		status.Policy = clusterPolicy.Name
+		status.Conditions = clusterPolicy.Status.Conditions
+	}
+	if policyErr != nil {
+		status.Error = policyErr.Error()
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}, current); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if reflect.DeepEqual(current.Status, status) {
+			return nil
+		}
+		current.Status = status
+		return r.Client.Status().Update(ctx, current)
+	})
+}
+
+func (r *NamespacedNodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	clusterPolicyNamespacedPolicy := handler.EnqueueRequestsFromMapFunc(
+		func(clusterPolicy client.Object) []reconcile.Request {
+			namespace, hasNamespace := clusterPolicy.GetLabels()[shared.NamespacedPolicyNamespaceLabel]
+			name, hasName := clusterPolicy.GetLabels()[shared.NamespacedPolicyNameLabel]
+			if !hasNamespace || !hasName {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+		})
+
+	// Both the old and the new quota are mapped at updates, so the
+	// namespaces removed from a quota are reconciled too.
+	quotaNamespacedPolicies := handler.EnqueueRequestsFromMapFunc(
+		func(quota client.Object) []reconcile.Request {
+			log := r.Log.WithName("quotaNamespacedPolicies")
+			requests := []reconcile.Request{}
+			nodeNetworkConfigurationQuota, ok := quota.(*nmstatev1beta1.NodeNetworkConfigurationQuota)
+			if !ok {
+				return requests
+			}
+			for _, namespace := range nodeNetworkConfigurationQuota.Spec.Namespaces {
+				policyList := nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicyList{}
+				err := r.Client.List(context.TODO(), &policyList, client.InNamespace(namespace))
+				if err != nil {
+					log.Error(err, "failed listing NamespacedNodeNetworkConfigurationPolicies to check them against the quota")
+					continue
+				}
+				for i := range policyList.Items {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Namespace: namespace, Name: policyList.Items[i].Name},
+					})
+				}
+			}
+			return requests
+		})
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &nmstatev1.NodeNetworkConfigurationPolicy{}}, clusterPolicyNamespacedPolicy).
+		Watches(&source.Kind{Type: &nmstatev1beta1.NodeNetworkConfigurationQuota{}}, quotaNamespacedPolicies).
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to add controller to NamespacedNodeNetworkConfigurationPolicy Reconciler")
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var _ = Describe("NamespacedNodeNetworkConfigurationPolicy controller reconcile", func() {
+	var (
+		cl                client.Client
+		reconciler        NamespacedNodeNetworkConfigurationPolicyReconciler
+		request           = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "vlan100"}}
+		clusterPolicyName = types.NamespacedName{Name: "team-a.vlan100"}
+		ctx               = context.TODO()
+		quota             nmstatev1beta1.NodeNetworkConfigurationQuota
+		policy            nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy
+		objs              []runtime.Object
+	)
+	BeforeEach(func() {
+		quota = nmstatev1beta1.NodeNetworkConfigurationQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+			Spec: shared.NodeNetworkConfigurationQuotaSpec{
+				Namespaces:   []string{"team-a"},
+				NodeSelector: map[string]string{"pool": "team-a"},
+				Interfaces:   []string{"eth1", "eth1.*"},
+			},
+		}
+		policy = nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "vlan100"},
+			Spec: shared.NodeNetworkConfigurationPolicySpec{
+				NodeSelector: map[string]string{"pool": "team-a"},
+				DependsOn:    []string{"bridge"},
+				DesiredState: shared.NewState(`
+interfaces:
+- name: eth1.100
+  type: vlan
+  state: up
+  vlan:
+    base-iface: eth1
+    id: 100
+`),
+			},
+		}
+		objs = []runtime.Object{}
+	})
+	JustBeforeEach(func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NodeNetworkConfigurationPolicy{})
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationQuota{},
+			&nmstatev1beta1.NodeNetworkConfigurationQuotaList{},
+		)
+		objs = append(objs, &quota, &policy)
+		cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+		reconciler = NamespacedNodeNetworkConfigurationPolicyReconciler{
+			Client: cl,
+			Log:    ctrl.Log.WithName("controllers").WithName("NamespacedNodeNetworkConfigurationPolicy"),
+			Scheme: s,
+		}
+	})
+	obtainClusterPolicy := func() *nmstatev1.NodeNetworkConfigurationPolicy {
+		clusterPolicy := &nmstatev1.NodeNetworkConfigurationPolicy{}
+		ExpectWithOffset(1, cl.Get(ctx, clusterPolicyName, clusterPolicy)).To(Succeed())
+		return clusterPolicy
+	}
+	obtainPolicy := func() *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy {
+		obtainedPolicy := &nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{}
+		ExpectWithOffset(1, cl.Get(ctx, request.NamespacedName, obtainedPolicy)).To(Succeed())
+		return obtainedPolicy
+	}
+	It("should create the cluster policy applying it", func() {
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		clusterPolicy := obtainClusterPolicy()
+		Expect(clusterPolicy.Labels).To(HaveKeyWithValue(shared.NamespacedPolicyNamespaceLabel, "team-a"))
+		Expect(clusterPolicy.Labels).To(HaveKeyWithValue(shared.NamespacedPolicyNameLabel, "vlan100"))
+		Expect(clusterPolicy.Spec.NodeSelector).To(Equal(map[string]string{"pool": "team-a"}))
+		Expect(clusterPolicy.Spec.DependsOn).To(Equal([]string{"team-a.bridge"}))
+		Expect(clusterPolicy.Spec.DesiredState.String()).To(MatchYAML(policy.Spec.DesiredState.String()))
+
+		obtainedPolicy := obtainPolicy()
+		Expect(obtainedPolicy.Finalizers).To(ConsistOf(clusterPolicyFinalizer))
+		Expect(obtainedPolicy.Status.Policy).To(Equal("team-a.vlan100"))
+		Expect(obtainedPolicy.Status.Error).To(BeEmpty())
+	})
+	Context("when the cluster policy has conditions", func() {
+		JustBeforeEach(func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterPolicy := obtainClusterPolicy()
+			clusterPolicy.Status.Conditions.Set(
+				shared.NodeNetworkConfigurationPolicyConditionAvailable,
+				corev1.ConditionTrue,
+				shared.NodeNetworkConfigurationPolicyConditionSuccessfullyConfigured,
+				"1/1 nodes successfully configured",
+			)
+			Expect(cl.Status().Update(ctx, clusterPolicy)).To(Succeed())
+		})
+		It("should report them at the namespaced policy status", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			condition := obtainPolicy().Status.Conditions.Find(shared.NodeNetworkConfigurationPolicyConditionAvailable)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+		})
+	})
+	Context("when the namespaced policy changes", func() {
+		JustBeforeEach(func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedPolicy := obtainPolicy()
+			updatedPolicy.Spec.DesiredState = shared.NewState(`
+interfaces:
+- name: eth1.100
+  type: vlan
+  state: up
+  mtu: 9000
+  vlan:
+    base-iface: eth1
+    id: 100
+`)
+			Expect(cl.Update(ctx, updatedPolicy)).To(Succeed())
+		})
+		It("should update the cluster policy", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainClusterPolicy().Spec.DesiredState.String()).To(ContainSubstring("mtu: 9000"))
+		})
+	})
+	Context("when the quota no longer allows the namespaced policy", func() {
+		JustBeforeEach(func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedQuota := &nmstatev1beta1.NodeNetworkConfigurationQuota{}
+			Expect(cl.Get(ctx, types.NamespacedName{Name: quota.Name}, updatedQuota)).To(Succeed())
+			updatedQuota.Spec.Interfaces = []string{"eth2.*"}
+			Expect(cl.Update(ctx, updatedQuota)).To(Succeed())
+
+			updatedPolicy := obtainPolicy()
+			updatedPolicy.Spec.DesiredState = shared.NewState(`
+interfaces:
+- name: eth1.100
+  type: vlan
+  state: absent
+`)
+			Expect(cl.Update(ctx, updatedPolicy)).To(Succeed())
+		})
+		It("should keep the cluster policy and report it at the namespaced policy status", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainClusterPolicy().Spec.DesiredState.String()).ToNot(ContainSubstring("absent"))
+			obtainedPolicy := obtainPolicy()
+			Expect(obtainedPolicy.Status.Policy).To(Equal("team-a.vlan100"))
+			Expect(obtainedPolicy.Status.Error).To(Equal(
+				`spec.desiredState.interfaces[0].name: Forbidden: interface eth1.100 is not granted by NodeNetworkConfigurationQuota team-a`))
+		})
+	})
+	Context("when a cluster policy with the same name does not apply the namespaced policy", func() {
+		BeforeEach(func() {
+			objs = append(objs, &nmstatev1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a.vlan100"},
+				Spec:       shared.NodeNetworkConfigurationPolicySpec{DesiredState: shared.NewState("interfaces: []")},
+			})
+		})
+		It("should not update it and report it at the namespaced policy status", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).To(HaveOccurred())
+			Expect(obtainClusterPolicy().Spec.DesiredState.String()).To(MatchYAML("interfaces: []"))
+			Expect(obtainPolicy().Status.Error).To(Equal(
+				"NodeNetworkConfigurationPolicy team-a.vlan100 already exists and does not apply this namespaced policy"))
+		})
+	})
+	Context("when the namespaced policy is deleted", func() {
+		JustBeforeEach(func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cl.Delete(ctx, obtainPolicy())).To(Succeed())
+		})
+		It("should delete the cluster policy and remove the finalizer", func() {
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cl.Get(ctx, clusterPolicyName, &nmstatev1.NodeNetworkConfigurationPolicy{})).ToNot(Succeed())
+			Expect(cl.Get(ctx, request.NamespacedName, &nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{})).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,16 @@
+#!/bin/bash -xe
+
+# Builds and vets the handler with the libnmstate build tag, it needs cgo
+# and the nmstate C library headers so it runs at a CentOS Stream container
+# with nmstate-devel installed.
+
+IMAGE_BUILDER=${IMAGE_BUILDER:-podman}
+GO_VERSION=${GO_VERSION:-$(hack/go-version.sh)}
+
+${IMAGE_BUILDER} run --rm -v "$(pwd)":/src:Z -w /src quay.io/centos/centos:stream9 bash -xec "
+    dnf install -y --enablerepo=crb gcc pkgconf-pkg-config nmstate-devel
+    ./build/install-go.sh ${GO_VERSION}
+    export PATH=/usr/local/go/bin:\$PATH GOFLAGS=-mod=vendor CGO_ENABLED=1
+    go vet -tags libnmstate ./pkg/nmstatectl/... ./cmd/handler/...
+    go build -tags libnmstate -o /dev/null ./cmd/handler
+"
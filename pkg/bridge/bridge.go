@@ -20,66 +20,58 @@ package bridge
 import (
 	"fmt"
 
-	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
-
-	yaml "sigs.k8s.io/yaml"
-
 	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
 )
 
 const minVlanID = 2
 const maxVlanID = 4094
 
-var defaultVlanFiltering = map[string]interface{}{
-	"mode": "trunk",
-	"trunk-tags": []map[string]interface{}{
-		{
-			"id-range": map[string]interface{}{
-				"min": minVlanID,
-				"max": maxVlanID,
+func defaultVlanFiltering() *schema.BridgePortVlan {
+	return &schema.BridgePortVlan{
+		Mode: "trunk",
+		TrunkTags: []schema.TrunkTag{
+			{
+				IDRange: &schema.VlanIDRange{
+					Min: schema.NewNumber(minVlanID),
+					Max: schema.NewNumber(maxVlanID),
+				},
 			},
 		},
-	},
+	}
 }
 
 func ApplyDefaultVlanFiltering(desiredState nmstate.State) (nmstate.State, error) {
-	result, err := yaml.YAMLToJSON(desiredState.Raw)
+	state, err := schema.FromState(desiredState)
 	if err != nil {
-		return desiredState, fmt.Errorf("error converting desiredState to JSON: %v", err)
+		return desiredState, fmt.Errorf("error decoding desiredState: %v", err)
 	}
 
-	ifaces := gjson.ParseBytes(result).Get("interfaces").Array()
-	for ifaceIndex, iface := range ifaces {
-		if !isLinuxBridgeUp(iface) {
+	defaulted := false
+	for ifaceIndex := range state.Interfaces {
+		iface := &state.Interfaces[ifaceIndex]
+		if !isLinuxBridgeUp(iface) || iface.Bridge == nil {
 			continue
 		}
-		for portIndex, port := range iface.Get("bridge.port").Array() {
+		for portIndex := range iface.Bridge.Port {
+			port := &iface.Bridge.Port[portIndex]
 			if hasVlanConfiguration(port) {
 				continue
 			}
-			result, err = sjson.SetBytes(
-				result,
-				fmt.Sprintf("interfaces.%d.bridge.port.%d.vlan", ifaceIndex, portIndex),
-				defaultVlanFiltering,
-			)
-			if err != nil {
-				return desiredState, err
-			}
+			port.Vlan = defaultVlanFiltering()
+			defaulted = true
 		}
 	}
-
-	resultYaml, err := yaml.JSONToYAML(result)
-	if err != nil {
-		return desiredState, err
+	if !defaulted {
+		return desiredState, nil
 	}
-	return nmstate.State{Raw: resultYaml}, nil
+	return state.ToState()
 }
 
-func isLinuxBridgeUp(iface gjson.Result) bool {
-	return iface.Get("type").String() == "linux-bridge" && iface.Get("state").String() == "up"
+func isLinuxBridgeUp(iface *schema.Interface) bool {
+	return iface.Type == schema.InterfaceTypeLinuxBridge && iface.State == schema.InterfaceStateUp
 }
 
-func hasVlanConfiguration(port gjson.Result) bool {
-	return port.Get("vlan").Exists()
+func hasVlanConfiguration(port *schema.BridgePort) bool {
+	return port.Vlan != nil
 }
@@ -34,6 +34,7 @@ import (
 	"github.com/nmstate/kubernetes-nmstate/api/names"
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
 )
@@ -51,6 +52,13 @@ const (
 	DesiredStateConfigurationTimeout = (defaultGwProbeTimeout + apiServerProbeTimeout) * 2
 )
 
+// CheckpointTimeout extends DesiredStateConfigurationTimeout with the time
+// the custom probes of the policy can take, so the checkpoint is still alive
+// to roll it back if the last of them fails.
+func CheckpointTimeout(probesConfig *shared.NodeNetworkConfigurationPolicyProbes) time.Duration {
+	return DesiredStateConfigurationTimeout + probe.CustomProbesTotalTimeout(probesConfig)
+}
+
 type DependencyVersions struct {
 	HandlerNmstateVersion string
 	HostNmstateVersion    string
@@ -134,49 +142,125 @@ func ExecuteCommand(command string, arguments ...string) (string, error) {
 	return string(bytes.Trim(stdout.Bytes(), "\n")), nil
 }
 
-func rollback(cli client.Client, probes []probe.Probe, cause error) error {
+// ApplyObserver is notified about the steps taken by ApplyDesiredState after
+// setting the desired state, so they can be reported while they happen.
+type ApplyObserver interface {
+	ProbeFailed(probeName string, err error)
+	RollbackStarted(cause error)
+	RollbackCompleted()
+	RollbackFailed(err error)
+	Committed()
+}
+
+type noopApplyObserver struct{}
+
+func (noopApplyObserver) ProbeFailed(string, error) {}
+func (noopApplyObserver) RollbackStarted(error)     {}
+func (noopApplyObserver) RollbackCompleted()        {}
+func (noopApplyObserver) RollbackFailed(error)      {}
+func (noopApplyObserver) Committed()                {}
+
+func observeNmstatectlDuration(operation string, start time.Time) {
+	monitoring.NmstatectlDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+func rollback(cli client.Client, probes []probe.Probe, cause error, observer ApplyObserver) error {
 	message := fmt.Sprintf("rolling back desired state configuration: %s", cause)
+	observer.RollbackStarted(cause)
+	monitoring.Rollbacks.Inc()
+	start := time.Now()
 	err := nmstatectl.Rollback()
+	observeNmstatectlDuration(monitoring.NmstatectlOperationRollback, start)
 	if err != nil {
+		observer.RollbackFailed(err)
 		return errors.Wrap(err, message)
 	}
 
 	// wait for system to settle after rollback
 	probesErr := probe.Run(cli, probes)
 	if probesErr != nil {
-		return errors.Wrap(errors.Wrap(probesErr, "failed running probes after rollback"), message)
+		err = errors.Wrap(probesErr, "failed running probes after rollback")
+		observer.RollbackFailed(err)
+		return errors.Wrap(err, message)
 	}
+	observer.RollbackCompleted()
 	return errors.New(message)
 }
 
-func ApplyDesiredState(cli client.Client, desiredState shared.State) (string, error) {
+// ValidateDesiredState applies the desired state without committing it and
+// rolls it back right away, this way nmstatectl verifies it against the node
+// without keeping any of the changes.
+func ValidateDesiredState(desiredState shared.State) (string, error) {
 	if string(desiredState.Raw) == "" {
 		return "Ignoring empty desired state", nil
 	}
 
+	// Rollback before Apply to remove pending checkpoints (for example handler pod restarted
+	// before Commit)
+	nmstatectl.Rollback()
+
+	setOutput, err := nmstatectl.Set(desiredState, DesiredStateConfigurationTimeout)
+	if err != nil {
+		// nmstatectl reverts the changes by itself if verification fails,
+		// rollback anyway in case the checkpoint is still there.
+		nmstatectl.Rollback()
+		return setOutput, err
+	}
+
+	err = nmstatectl.Rollback()
+	if err != nil {
+		return setOutput, errors.Wrap(err, "failed rolling back dry-run desired state")
+	}
+
+	commandOutput := fmt.Sprintf("setOutput: %s \n", setOutput)
+	return commandOutput, nil
+}
+
+func ApplyDesiredState(
+	cli client.Client,
+	desiredState shared.State,
+	probesConfig *shared.NodeNetworkConfigurationPolicyProbes,
+	observer ApplyObserver,
+) (string, error) {
+	if string(desiredState.Raw) == "" {
+		return "Ignoring empty desired state", nil
+	}
+	if observer == nil {
+		observer = noopApplyObserver{}
+	}
+
 	// Before apply we get the probes that are working fine, they should be
 	// working fine after apply
-	probes := probe.Select(cli)
+	probes := probe.Select(cli, probesConfig)
 
 	// Rollback before Apply to remove pending checkpoints (for example handler pod restarted
 	// before Commit)
 	nmstatectl.Rollback()
 
-	setOutput, err := nmstatectl.Set(desiredState, DesiredStateConfigurationTimeout)
+	start := time.Now()
+	setOutput, err := nmstatectl.Set(desiredState, CheckpointTimeout(probesConfig))
+	observeNmstatectlDuration(monitoring.NmstatectlOperationApply, start)
 	if err != nil {
 		return setOutput, err
 	}
 
 	err = probe.Run(cli, probes)
 	if err != nil {
-		return "", rollback(cli, probes, errors.Wrap(err, "failed runnig probes after network changes"))
+		var failure *probe.Failure
+		if errors.As(err, &failure) {
+			observer.ProbeFailed(failure.Name, err)
+		}
+		return "", rollback(cli, probes, errors.Wrap(err, "failed runnig probes after network changes"), observer)
 	}
 
+	start = time.Now()
 	commitOutput, err := nmstatectl.Commit()
+	observeNmstatectlDuration(monitoring.NmstatectlOperationCommit, start)
 	if err != nil {
 		// We cannot rollback if commit fails, just return the error
 		return commitOutput, err
 	}
+	observer.Committed()
 
 	commandOutput := fmt.Sprintf("setOutput: %s \n", setOutput)
 	return commandOutput, nil
@@ -0,0 +1,314 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const (
+	absentState = "absent"
+	downState   = "down"
+	// mainRouteTable is the table used by nmstate when the route has no table-id
+	mainRouteTable = "254"
+)
+
+// Difference is a path from the desired state that has a different value
+// or is missing at the current state.
+type Difference struct {
+	Path    string
+	Desired interface{}
+	Current interface{}
+}
+
+// Compare returns the differences between the desired state and the current
+// state. The desired state is checked as a subset of the current one so
+// attributes only present at the current state are not reported, interfaces
+// and other named list items are matched by name, routes by destination, next
+// hop and table and the rest of list items by content.
+func Compare(desiredState, currentState shared.State) ([]Difference, error) {
+	desired := map[string]interface{}{}
+	err := yaml.Unmarshal(desiredState.Raw, &desired)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed unmarshaling desired state")
+	}
+	current := map[string]interface{}{}
+	err = yaml.Unmarshal(currentState.Raw, &current)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed unmarshaling current state")
+	}
+	return compare("", desired, current), nil
+}
+
+// Paths returns the path of every difference.
+func Paths(differences []Difference) []string {
+	paths := []string{}
+	for _, difference := range differences {
+		paths = append(paths, difference.Path)
+	}
+	return paths
+}
+
+// Summarize converts the differences into the enactment status summary
+// keeping at most limit of them.
+func Summarize(differences []Difference, limit int) *shared.StateDiffSummary {
+	summary := &shared.StateDiffSummary{
+		Total:       len(differences),
+		Differences: []shared.StateDifference{},
+	}
+	for i, difference := range differences {
+		if i == limit {
+			break
+		}
+		summary.Differences = append(summary.Differences, shared.StateDifference{
+			Path:    difference.Path,
+			Desired: format(difference.Desired),
+			Current: format(difference.Current),
+		})
+	}
+	return summary
+}
+
+func format(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		formatted, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(formatted)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func compare(path string, desired, current interface{}) []Difference {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		currentValue, ok := current.(map[string]interface{})
+		if !ok {
+			return []Difference{{Path: path, Desired: desired, Current: current}}
+		}
+		return compareMaps(path, desiredValue, currentValue)
+	case []interface{}:
+		currentValue, ok := current.([]interface{})
+		if !ok {
+			return []Difference{{Path: path, Desired: desired, Current: current}}
+		}
+		return compareLists(path, desiredValue, currentValue)
+	case string:
+		currentValue, ok := current.(string)
+		if !ok || !strings.EqualFold(desiredValue, currentValue) {
+			return []Difference{{Path: path, Desired: desired, Current: current}}
+		}
+		return nil
+	default:
+		if fmt.Sprint(desired) != fmt.Sprint(current) {
+			return []Difference{{Path: path, Desired: desired, Current: current}}
+		}
+		return nil
+	}
+}
+
+func compareMaps(path string, desired, current map[string]interface{}) []Difference {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	differences := []Difference{}
+	for _, key := range keys {
+		keyPath := joinPath(path, key)
+		currentValue, ok := current[key]
+		if !ok {
+			differences = append(differences, Difference{Path: keyPath, Desired: desired[key]})
+			continue
+		}
+		differences = append(differences, compare(keyPath, desired[key], currentValue)...)
+	}
+	return differences
+}
+
+func compareLists(path string, desired, current []interface{}) []Difference {
+	differences := []Difference{}
+	for i, desiredItem := range desired {
+		key, hasKey := itemKey(desiredItem)
+		if !hasKey {
+			if !containsItem(desiredItem, current) {
+				differences = append(differences, Difference{Path: fmt.Sprintf("%s[%d]", path, i), Desired: desiredItem})
+			}
+			continue
+		}
+		itemPath := fmt.Sprintf("%s[%s]", path, key)
+		currentItem := findSameItem(desiredItem, current)
+		if isAbsent(desiredItem) {
+			// Absent items, and virtual interfaces set down, have to be gone
+			// from the current state
+			if currentItem != nil {
+				differences = append(differences, Difference{Path: itemPath, Desired: desiredItem, Current: currentItem})
+			}
+			continue
+		}
+		if currentItem == nil {
+			differences = append(differences, Difference{Path: itemPath, Desired: desiredItem})
+			continue
+		}
+		differences = append(differences, compare(itemPath, desiredItem, currentItem)...)
+	}
+	return differences
+}
+
+// FindItem returns the item from items with the same identity as item, named
+// items are matched by name, routes by destination, next hop and table and
+// the rest of items by content.
+func FindItem(item interface{}, items []interface{}) interface{} {
+	if _, hasKey := itemKey(item); hasKey {
+		return findSameItem(item, items)
+	}
+	for _, currentItem := range items {
+		if len(compare("", item, currentItem)) == 0 {
+			return currentItem
+		}
+	}
+	return nil
+}
+
+// itemKey returns the identity of named items like interfaces or bridge
+// ports and of routes, the rest of items are matched by content.
+func itemKey(item interface{}) (string, bool) {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	if name, ok := itemMap["name"].(string); ok {
+		return name, true
+	}
+	if destination, ok := itemMap["destination"].(string); ok {
+		return routeKey(destination, itemMap), true
+	}
+	return "", false
+}
+
+func routeKey(destination string, route map[string]interface{}) string {
+	key := []string{destination}
+	if nextHopAddress, ok := route["next-hop-address"]; ok {
+		key = append(key, fmt.Sprintf("via %v", nextHopAddress))
+	}
+	if nextHopInterface, ok := route["next-hop-interface"]; ok {
+		key = append(key, fmt.Sprintf("dev %v", nextHopInterface))
+	}
+	key = append(key, "table "+routeTable(route))
+	return strings.Join(key, " ")
+}
+
+// physicalInterfaceTypes are kept by nmstate when set down, the rest of
+// the interfaces are removed like the absent ones.
+var physicalInterfaceTypes = map[interface{}]bool{
+	"ethernet":   true,
+	"infiniband": true,
+	"loopback":   true,
+	"unknown":    true,
+}
+
+func isAbsent(item interface{}) bool {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if itemMap["state"] == absentState {
+		return true
+	}
+	interfaceType, hasType := itemMap["type"]
+	return itemMap["state"] == downState && hasType && !physicalInterfaceTypes[interfaceType]
+}
+
+// findSameItem looks for the current item with the same identity as the
+// desired one, named items are matched by name and type if both have it set,
+// like an OVS bridge and its internal interface, for routes only the next hop
+// and table set at the desired route are taken into account.
+func findSameItem(desiredItem interface{}, items []interface{}) interface{} {
+	desiredItemMap := desiredItem.(map[string]interface{})
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := desiredItemMap["name"]; ok {
+			if itemMap["name"] == name && isSameType(desiredItemMap, itemMap) {
+				return item
+			}
+			continue
+		}
+		if isSameRoute(desiredItemMap, itemMap) {
+			return item
+		}
+	}
+	return nil
+}
+
+func isSameType(desired, current map[string]interface{}) bool {
+	desiredType, hasDesiredType := desired["type"]
+	currentType, hasCurrentType := current["type"]
+	return !hasDesiredType || !hasCurrentType || desiredType == currentType
+}
+
+func isSameRoute(desired, current map[string]interface{}) bool {
+	if desired["destination"] != current["destination"] || routeTable(desired) != routeTable(current) {
+		return false
+	}
+	for _, nextHopKey := range []string{"next-hop-address", "next-hop-interface"} {
+		if nextHop, ok := desired[nextHopKey]; ok && nextHop != current[nextHopKey] {
+			return false
+		}
+	}
+	return true
+}
+
+func routeTable(route map[string]interface{}) string {
+	if tableID, ok := route["table-id"]; ok {
+		return fmt.Sprint(tableID)
+	}
+	return mainRouteTable
+}
+
+func containsItem(desiredItem interface{}, current []interface{}) bool {
+	for _, currentItem := range current {
+		if len(compare("", desiredItem, currentItem)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
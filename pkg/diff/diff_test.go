@@ -0,0 +1,229 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("State diff", func() {
+	currentState := shared.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+  mac-address: 02:00:00:AA:BB:CC
+  ipv4:
+    enabled: true
+    dhcp: false
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+    - ip: 192.168.2.10
+      prefix-length: 24
+- name: br1
+  type: linux-bridge
+  state: up
+  bridge:
+    port:
+    - name: eth2
+      vlan: {}
+- name: br0
+  type: ovs-bridge
+  state: up
+  bridge:
+    port:
+    - name: br0
+- name: br0
+  type: ovs-interface
+  state: up
+  mtu: 1400
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+    table-id: 254
+  - destination: 10.0.0.0/8
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+    table-id: 100
+  running: []
+`)
+	type diffCase struct {
+		desiredState        string
+		expectedDifferences []Difference
+	}
+	DescribeTable("when comparing desired state with current state",
+		func(c diffCase) {
+			differences, err := Compare(shared.NewState(c.desiredState), currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(differences).To(Equal(c.expectedDifferences))
+		},
+		Entry("desired state is applied", diffCase{
+			desiredState: `
+interfaces:
+- name: eth1
+  state: up
+  mac-address: 02:00:00:aa:bb:cc
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.2.10
+      prefix-length: 24
+- name: br1
+  bridge:
+    port:
+    - name: eth2
+- name: eth3
+  state: absent
+- name: br0
+  type: ovs-interface
+  mtu: 1400
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth1
+    table-id: 100
+  - destination: 172.16.0.0/12
+    next-hop-interface: eth1
+    state: absent
+`,
+			expectedDifferences: []Difference{},
+		}),
+		Entry("desired state differs", diffCase{
+			desiredState: `
+interfaces:
+- name: eth1
+  state: up
+  mtu: 9000
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.3.10
+      prefix-length: 24
+- name: br1
+  state: absent
+- name: bond0
+  state: up
+- name: br0
+  type: ovs-interface
+  mtu: 1500
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.254
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth1
+    state: absent
+    table-id: 100
+`,
+			expectedDifferences: []Difference{
+				{
+					Path:    "interfaces[eth1].ipv4.address[0]",
+					Desired: map[string]interface{}{"ip": "192.168.3.10", "prefix-length": float64(24)},
+				},
+				{Path: "interfaces[eth1].mtu", Desired: float64(9000), Current: float64(1500)},
+				{
+					Path:    "interfaces[br1]",
+					Desired: map[string]interface{}{"name": "br1", "state": "absent"},
+					Current: map[string]interface{}{
+						"name": "br1", "type": "linux-bridge", "state": "up",
+						"bridge": map[string]interface{}{
+							"port": []interface{}{map[string]interface{}{"name": "eth2", "vlan": map[string]interface{}{}}},
+						},
+					},
+				},
+				{Path: "interfaces[bond0]", Desired: map[string]interface{}{"name": "bond0", "state": "up"}},
+				{Path: "interfaces[br0].mtu", Desired: float64(1500), Current: float64(1400)},
+				{
+					Path:    "routes.config[0.0.0.0/0 via 192.168.1.254 table 254]",
+					Desired: map[string]interface{}{"destination": "0.0.0.0/0", "next-hop-address": "192.168.1.254"},
+				},
+				{
+					Path: "routes.config[10.0.0.0/8 dev eth1 table 100]",
+					Desired: map[string]interface{}{
+						"destination": "10.0.0.0/8", "next-hop-interface": "eth1", "state": "absent", "table-id": float64(100),
+					},
+					Current: map[string]interface{}{
+						"destination": "10.0.0.0/8", "next-hop-address": "192.168.1.1", "next-hop-interface": "eth1", "table-id": float64(100),
+					},
+				},
+			},
+		}),
+		Entry("virtual interfaces set down are removed", diffCase{
+			desiredState: `
+interfaces:
+- name: bond0
+  type: bond
+  state: down
+- name: br1
+  type: linux-bridge
+  state: down
+`,
+			expectedDifferences: []Difference{
+				{
+					Path:    "interfaces[br1]",
+					Desired: map[string]interface{}{"name": "br1", "type": "linux-bridge", "state": "down"},
+					Current: map[string]interface{}{
+						"name": "br1", "type": "linux-bridge", "state": "up",
+						"bridge": map[string]interface{}{
+							"port": []interface{}{map[string]interface{}{"name": "eth2", "vlan": map[string]interface{}{}}},
+						},
+					},
+				},
+			},
+		}),
+		Entry("physical interfaces set down are kept", diffCase{
+			desiredState: `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: down
+- name: eth3
+  type: ethernet
+  state: down
+`,
+			expectedDifferences: []Difference{
+				{Path: "interfaces[eth1].state", Desired: "down", Current: "up"},
+				{Path: "interfaces[eth3]", Desired: map[string]interface{}{"name": "eth3", "type": "ethernet", "state": "down"}},
+			},
+		}),
+	)
+	Context("when summarizing differences", func() {
+		differences := []Difference{
+			{Path: "interfaces[eth1].mtu", Desired: float64(9000), Current: float64(1500)},
+			{Path: "interfaces[bond0]", Desired: map[string]interface{}{"name": "bond0", "state": "up"}},
+			{Path: "interfaces[eth2].state", Desired: "up", Current: "down"},
+		}
+		It("should format the values and keep up to the limit of differences", func() {
+			summary := Summarize(differences, 2)
+			Expect(summary.Total).To(Equal(3))
+			Expect(summary.Differences).To(Equal([]shared.StateDifference{
+				{Path: "interfaces[eth1].mtu", Desired: "9000", Current: "1500"},
+				{Path: "interfaces[bond0]", Desired: `{"name":"bond0","state":"up"}`},
+			}))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enactment
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	"github.com/nmstate/kubernetes-nmstate/pkg/selectors"
+)
+
+// UnavailableDependencies returns the policy dependencies that are not
+// available yet at the node, a dependency is available when its enactment
+// for the node has been successfully configured with the current policy generation
+// or when its node selector does not match the node, so there is nothing to wait for.
+func UnavailableDependencies(cli client.Reader, nodeName string, policy *nmstatev1.NodeNetworkConfigurationPolicy) ([]string, error) {
+	unavailableDependencies := []string{}
+	for _, dependencyName := range policy.Spec.DependsOn {
+		available, err := isDependencyAvailable(cli, nodeName, dependencyName)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			unavailableDependencies = append(unavailableDependencies, dependencyName)
+		}
+	}
+	return unavailableDependencies, nil
+}
+
+func isDependencyAvailable(cli client.Reader, nodeName, dependencyName string) (bool, error) {
+	dependency := nmstatev1.NodeNetworkConfigurationPolicy{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: dependencyName}, &dependency)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed getting policy dependency %s", dependencyName)
+	}
+
+	dependencySelectors := selectors.NewFromPolicy(cli, &dependency)
+	unmatchingNodeLabels, err := dependencySelectors.UnmatchedNodeLabels(nodeName)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed checking node selectors of policy dependency %s", dependencyName)
+	}
+	if len(unmatchingNodeLabels) > 0 {
+		return true, nil
+	}
+
+	dependencyEnactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err = cli.Get(context.TODO(), nmstateapi.EnactmentKey(nodeName, dependencyName), &dependencyEnactment)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed getting enactment for policy dependency %s", dependencyName)
+	}
+
+	if dependencyEnactment.Status.PolicyGeneration != dependency.Generation {
+		return false, nil
+	}
+	return enactmentstatus.IsAvailable(&dependencyEnactment.Status.Conditions), nil
+}
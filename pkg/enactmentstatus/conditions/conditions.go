@@ -19,6 +19,7 @@ package conditions
 
 import (
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -30,12 +31,15 @@ import (
 
 	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	"github.com/nmstate/kubernetes-nmstate/pkg/events"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
 )
 
 type EnactmentConditions struct {
 	client       client.Client
 	enactmentKey types.NamespacedName
 	logger       logr.Logger
+	events       *events.Enactment
 }
 
 func New(cli client.Client, enactmentKey types.NamespacedName) EnactmentConditions {
@@ -47,6 +51,13 @@ func New(cli client.Client, enactmentKey types.NamespacedName) EnactmentConditio
 	return conditions
 }
 
+// WithEvents returns the enactment conditions emitting also the events of
+// the enactment transitions.
+func (ec EnactmentConditions) WithEvents(enactmentEvents *events.Enactment) EnactmentConditions {
+	ec.events = enactmentEvents
+	return ec
+}
+
 func (ec *EnactmentConditions) NotifyGenerateFailure(err error) {
 	ec.logger.Info("NotifyGenerateFailure")
 	message := fmt.Sprintf("failure generating desiredState and capturedStates: %v", err)
@@ -58,9 +69,29 @@ func (ec *EnactmentConditions) NotifyGenerateFailure(err error) {
 
 func (ec *EnactmentConditions) NotifyProgressing() {
 	ec.logger.Info("NotifyProgressing")
-	err := ec.updateEnactmentConditions(SetProgressing, "Applying desired state")
+	transitioned, err := ec.transitionEnactmentConditions(SetProgressing, "Applying desired state")
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state Progressing")
+		return
+	}
+	if transitioned {
+		ec.events.Progressing()
+	}
+}
+
+func (ec *EnactmentConditions) NotifyDraining(message string) {
+	ec.logger.Info("NotifyDraining")
+	err := ec.updateEnactmentConditions(SetDraining, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Draining")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyFailedToDrain(failedErr error) {
+	ec.logger.Info("NotifyFailedToDrain")
+	err := ec.updateEnactmentConditions(SetFailedToDrain, failedErr.Error())
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state FailedToDrain")
 	}
 }
 
@@ -74,9 +105,13 @@ func (ec *EnactmentConditions) NotifyFailedToConfigure(failedErr error) {
 
 func (ec *EnactmentConditions) NotifyAborted(failedErr error) {
 	ec.logger.Info("NotifyConfigurationAborted")
-	err := ec.updateEnactmentConditions(SetConfigurationAborted, failedErr.Error())
+	transitioned, err := ec.transitionEnactmentConditions(SetConfigurationAborted, failedErr.Error())
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state ConfigurationAborted")
+		return
+	}
+	if transitioned {
+		ec.events.Aborted(failedErr)
 	}
 }
 
@@ -88,11 +123,120 @@ func (ec *EnactmentConditions) NotifySuccess() {
 	}
 }
 
+func (ec *EnactmentConditions) NotifyDryRunSuccess(output string) {
+	ec.logger.Info("NotifyDryRunSuccess")
+	err := ec.updateEnactmentConditions(SetDryRunSuccess, fmt.Sprintf("dry-run succeeded: %s", output))
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state DryRunSucceeded")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyDryRunFailure(failedErr error) {
+	ec.logger.Info("NotifyDryRunFailure")
+	err := ec.updateEnactmentConditions(SetDryRunFailed, failedErr.Error())
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state DryRunFailed")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyReverted(policyGeneration int64) {
+	ec.logger.Info("NotifyReverted")
+	err := ec.updateEnactmentConditions(SetReverted, fmt.Sprintf("reverted to desired state from policy generation %d", policyGeneration))
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Reverted")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyFailedToRevert(failedErr error) {
+	ec.logger.Info("NotifyFailedToRevert")
+	err := ec.updateEnactmentConditions(SetFailedToRevert, failedErr.Error())
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state FailedToRevert")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyRolledBack(policyGeneration int64) {
+	ec.logger.Info("NotifyRolledBack")
+	err := ec.updateEnactmentConditions(SetRolledBack, fmt.Sprintf("rolled back to the state before policy generation %d", policyGeneration))
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state RolledBack")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyFailedToRollBack(failedErr error) {
+	ec.logger.Info("NotifyFailedToRollBack")
+	err := ec.updateEnactmentConditions(SetFailedToRollBack, failedErr.Error())
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state FailedToRollBack")
+	}
+}
+
 func (ec *EnactmentConditions) NotifyPending() {
 	ec.logger.Info("NotifyPending")
-	err := ec.updateEnactmentConditions(SetPending, "Waiting for progressing nodes to finish")
+	message := "Waiting for progressing nodes to finish"
+	transitioned, err := ec.transitionEnactmentConditions(SetPending, message)
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state Pending")
+		return
+	}
+	if transitioned {
+		ec.events.Pending(message)
+	}
+}
+
+func (ec *EnactmentConditions) NotifyWaitingForDependency(message string) {
+	ec.logger.Info("NotifyWaitingForDependency")
+	err := ec.updateEnactmentConditions(SetWaitingForDependency, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state WaitingForDependency")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyWaitingForRolloutWave(message string) {
+	ec.logger.Info("NotifyWaitingForRolloutWave")
+	err := ec.updateEnactmentConditions(SetWaitingForRolloutWave, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state WaitingForRolloutWave")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyWaitingForMaintenanceWindow(message string) {
+	ec.logger.Info("NotifyWaitingForMaintenanceWindow")
+	err := ec.updateEnactmentConditions(SetWaitingForMaintenanceWindow, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state WaitingForMaintenanceWindow")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyRolloutPaused(message string) {
+	ec.logger.Info("NotifyRolloutPaused")
+	err := ec.updateEnactmentConditions(SetRolloutPaused, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state RolloutPaused")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyPaused() {
+	ec.logger.Info("NotifyPaused")
+	err := ec.updateEnactmentConditions(SetPaused, "Policy rollout is paused")
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Paused")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyDrifted(paths []string) {
+	ec.logger.Info("NotifyDrifted")
+	err := ec.updateEnactmentConditions(SetDrifted, DriftedMessage(paths))
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Drifted")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyNotDrifted() {
+	ec.logger.Info("NotifyNotDrifted")
+	err := ec.updateEnactmentConditions(SetNotDrifted, "")
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state not Drifted")
 	}
 }
 
@@ -110,10 +254,79 @@ func (ec *EnactmentConditions) updateEnactmentConditions(
 	conditionsSetter func(*nmstate.ConditionList, string),
 	message string,
 ) error {
-	return enactmentstatus.Update(ec.client, ec.enactmentKey,
+	_, err := ec.transitionEnactmentConditions(conditionsSetter, message)
+	return err
+}
+
+// transitionEnactmentConditions updates the enactment conditions and returns
+// true if the enactment has transitioned to a different condition or reason,
+// so the events are only emitted once per transition and not at every
+// reconcile repeating it.
+func (ec *EnactmentConditions) transitionEnactmentConditions(
+	conditionsSetter func(*nmstate.ConditionList, string),
+	message string,
+) (bool, error) {
+	var previousOutcome, outcome nmstate.ConditionReason
+	var previousActive, active *nmstate.Condition
+	err := enactmentstatus.Update(ec.client, ec.enactmentKey,
 		func(status *nmstate.NodeNetworkConfigurationEnactmentStatus) {
+			previousOutcome = outcomeReason(status.Conditions)
+			previousActive = activeCondition(status.Conditions)
 			conditionsSetter(&status.Conditions, message)
+			outcome = outcomeReason(status.Conditions)
+			active = activeCondition(status.Conditions)
 		})
+	if err != nil {
+		return false, err
+	}
+	if outcome != "" && outcome != previousOutcome {
+		monitoring.EnactmentOutcomes.WithLabelValues(string(outcome)).Inc()
+	}
+	transitioned := previousActive == nil || active == nil ||
+		previousActive.Type != active.Type || previousActive.Reason != active.Reason
+	return transitioned, nil
+}
+
+// activeCondition returns a copy of the condition the enactment is at, or
+// nil if it has none. A successful dry-run is at the not available condition.
+func activeCondition(conditions nmstate.ConditionList) *nmstate.Condition {
+	if enactmentstatus.IsDryRunSucceeded(&conditions) {
+		active := *conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionAvailable)
+		return &active
+	}
+	for _, conditionType := range []nmstate.ConditionType{
+		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
+		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
+		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
+		nmstate.NodeNetworkConfigurationEnactmentConditionProgressing,
+		nmstate.NodeNetworkConfigurationEnactmentConditionPending,
+	} {
+		condition := conditions.Find(conditionType)
+		if condition != nil && condition.Status == corev1.ConditionTrue {
+			active := *condition
+			return &active
+		}
+	}
+	return nil
+}
+
+// outcomeReason returns the reason of the condition the enactment has
+// finished with or empty if it has not finished.
+func outcomeReason(conditions nmstate.ConditionList) nmstate.ConditionReason {
+	if enactmentstatus.IsDryRunSucceeded(&conditions) {
+		return nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded
+	}
+	for _, conditionType := range []nmstate.ConditionType{
+		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
+		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
+		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
+	} {
+		condition := conditions.Find(conditionType)
+		if condition != nil && condition.Status == corev1.ConditionTrue {
+			return condition.Reason
+		}
+	}
+	return ""
 }
 
 func SetFailedToConfigure(conditions *nmstate.ConditionList, message string) {
@@ -153,6 +366,28 @@ func SetFailed(conditions *nmstate.ConditionList, reason nmstate.ConditionReason
 	)
 }
 
+func SetDryRunFailed(conditions *nmstate.ConditionList, message string) {
+	SetFailed(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailed, message)
+}
+
+func SetFailedToRevert(conditions *nmstate.ConditionList, message string) {
+	SetFailed(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionFailedToRevert, message)
+}
+
+func SetFailedToDrain(conditions *nmstate.ConditionList, message string) {
+	SetFailed(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionFailedToDrain, message)
+}
+
+func SetFailedToRollBack(conditions *nmstate.ConditionList, message string) {
+	SetFailed(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionFailedToRollBack, message)
+}
+
+// SetRolledBack marks the enactment as aborted since the node is not at the
+// policy desired state anymore.
+func SetRolledBack(conditions *nmstate.ConditionList, message string) {
+	SetAborted(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionRolledBack, message)
+}
+
 func SetConfigurationAborted(conditions *nmstate.ConditionList, message string) {
 	SetAborted(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationAborted, message)
 }
@@ -191,100 +426,198 @@ func SetAborted(conditions *nmstate.ConditionList, reason nmstate.ConditionReaso
 }
 
 func SetSuccess(conditions *nmstate.ConditionList, message string) {
+	SetAvailable(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured, message)
+}
+
+// SetDryRunSuccess finishes the enactment without making it available, the
+// desired state has only been checked and nothing is configured at the node.
+func SetDryRunSuccess(conditions *nmstate.ConditionList, message string) {
+	reason := nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
+		corev1.ConditionFalse,
+		reason,
+		message,
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
+		corev1.ConditionFalse,
+		reason,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionProgressing,
+		corev1.ConditionFalse,
+		reason,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionPending,
+		corev1.ConditionFalse,
+		reason,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
+		corev1.ConditionFalse,
+		reason,
+		"",
+	)
+}
+
+func SetReverted(conditions *nmstate.ConditionList, message string) {
+	SetAvailable(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionReverted, message)
+}
+
+func SetAvailable(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string) {
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
 		corev1.ConditionTrue,
-		nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionProgressing,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionPending,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured,
+		reason,
 		"",
 	)
 }
 
 func SetProgressing(conditions *nmstate.ConditionList, message string) {
+	setProgressing(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing, message)
+}
+
+func SetDraining(conditions *nmstate.ConditionList, message string) {
+	setProgressing(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionDrainingNode, message)
+}
+
+func setProgressing(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string) {
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionProgressing,
 		corev1.ConditionTrue,
-		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
 		corev1.ConditionUnknown,
-		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
 		corev1.ConditionUnknown,
-		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionPending,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing,
+		reason,
 		"",
 	)
 }
 
 func SetPending(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached, message)
+}
+
+func SetWaitingForDependency(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionWaitingForDependency, message)
+}
+
+func SetWaitingForRolloutWave(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionWaitingForRolloutWave, message)
+}
+
+func SetWaitingForMaintenanceWindow(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionWaitingForMaintenanceWindow, message)
+}
+
+func SetRolloutPaused(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionRolloutPaused, message)
+}
+
+func SetPaused(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionPaused, message)
+}
+
+func setPending(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string) {
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionPending,
 		corev1.ConditionTrue,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionProgressing,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		"",
 	)
 }
+
+func DriftedMessage(paths []string) string {
+	return fmt.Sprintf("current state differs from desired state at: %s", strings.Join(paths, ", "))
+}
+
+func SetDrifted(conditions *nmstate.ConditionList, message string) {
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDrifted,
+		corev1.ConditionTrue,
+		nmstate.NodeNetworkConfigurationEnactmentConditionDriftDetected,
+		message,
+	)
+}
+
+func SetNotDrifted(conditions *nmstate.ConditionList, message string) {
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDrifted,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationEnactmentConditionNoDriftDetected,
+		message,
+	)
+}
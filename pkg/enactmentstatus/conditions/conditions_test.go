@@ -0,0 +1,130 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pgo "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/events"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
+)
+
+func enactmentOutcomes(reason nmstate.ConditionReason) float64 {
+	metric := &pgo.Metric{}
+	Expect(monitoring.EnactmentOutcomes.WithLabelValues(string(reason)).Write(metric)).To(Succeed())
+	return metric.GetCounter().GetValue()
+}
+
+var _ = Describe("Enactment conditions outcome metric", func() {
+	var enactmentConditions EnactmentConditions
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion, &nmstatev1beta1.NodeNetworkConfigurationEnactment{})
+		enactmentKey := types.NamespacedName{Name: "node01.policy1"}
+		cli := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nmstatev1beta1.NodeNetworkConfigurationEnactment{
+			ObjectMeta: metav1.ObjectMeta{Name: enactmentKey.Name},
+		}).Build()
+		enactmentConditions = New(cli, enactmentKey)
+	})
+	It("should count the enactment finishing every time it is applied", func() {
+		successes := enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured)
+		enactmentConditions.NotifyProgressing()
+		enactmentConditions.NotifySuccess()
+		enactmentConditions.NotifyProgressing()
+		enactmentConditions.NotifySuccess()
+		Expect(enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured)).To(Equal(successes + 2))
+	})
+	It("should not count the enactment notified again with the same outcome", func() {
+		abortions := enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationAborted)
+		enactmentConditions.NotifyAborted(fmt.Errorf("policy has failing enactments, aborting"))
+		enactmentConditions.NotifyAborted(fmt.Errorf("policy has failing enactments, aborting"))
+		Expect(enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationAborted)).To(Equal(abortions + 1))
+	})
+	It("should count the successful dry-run although the enactment is not available", func() {
+		dryRunSuccesses := enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded)
+		enactmentConditions.NotifyProgressing()
+		enactmentConditions.NotifyDryRunSuccess("")
+		enactmentConditions.NotifyDryRunSuccess("")
+		Expect(enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded)).To(Equal(dryRunSuccesses + 1))
+	})
+	It("should not count the enactment that has not finished", func() {
+		pendings := enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached)
+		enactmentConditions.NotifyPending()
+		Expect(enactmentOutcomes(nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached)).To(Equal(pendings))
+	})
+})
+
+var _ = Describe("Enactment conditions events", func() {
+	var (
+		enactmentConditions EnactmentConditions
+		recorder            *record.FakeRecorder
+	)
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion, &nmstatev1beta1.NodeNetworkConfigurationEnactment{})
+		enactmentKey := types.NamespacedName{Name: "node01.policy1"}
+		cli := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(&nmstatev1beta1.NodeNetworkConfigurationEnactment{
+			ObjectMeta: metav1.ObjectMeta{Name: enactmentKey.Name},
+		}).Build()
+		recorder = record.NewFakeRecorder(20)
+		policy := &nmstatev1.NodeNetworkConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1"}}
+		enactmentConditions = New(cli, enactmentKey).
+			WithEvents(events.ForEnactment(events.NewRecorder(recorder, events.DefaultQPS, events.DefaultBurst), policy, "node01"))
+	})
+	receivedReasons := func() []string {
+		close(recorder.Events)
+		reasons := []string{}
+		for e := range recorder.Events {
+			reasons = append(reasons, strings.Fields(e)[1])
+		}
+		return reasons
+	}
+	It("should emit the events only when the enactment transitions", func() {
+		enactmentConditions.NotifyPending()
+		enactmentConditions.NotifyPending()
+		enactmentConditions.NotifyPending()
+		enactmentConditions.NotifyProgressing()
+		enactmentConditions.NotifySuccess()
+		enactmentConditions.NotifyProgressing()
+		// Every event is emitted at the policy and at the node
+		Expect(receivedReasons()).To(Equal([]string{
+			events.ReasonPending, events.ReasonPending,
+			events.ReasonProgressing, events.ReasonProgressing,
+			events.ReasonProgressing, events.ReasonProgressing,
+		}))
+	})
+	It("should not emit the aborted event again while the enactment stays aborted", func() {
+		enactmentConditions.NotifyAborted(fmt.Errorf("policy has failing enactments, aborting"))
+		enactmentConditions.NotifyAborted(fmt.Errorf("policy has failing enactments, aborting"))
+		Expect(receivedReasons()).To(Equal([]string{events.ReasonAborted, events.ReasonAborted}))
+	})
+})
@@ -24,6 +24,7 @@ import (
 
 	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
 )
 
 type CountByConditionStatus map[corev1.ConditionStatus]int
@@ -52,6 +53,19 @@ func Count(enactments nmstatev1beta1.NodeNetworkConfigurationEnactmentList, poli
 	return conditionCount
 }
 
+// CountDryRunSucceeded returns how many enactments of the policy generation
+// have finished a successful dry-run, they are not counted as available.
+func CountDryRunSucceeded(enactments nmstatev1beta1.NodeNetworkConfigurationEnactmentList, policyGeneration int64) int {
+	count := 0
+	for enactmentIndex := range enactments.Items {
+		enactment := enactments.Items[enactmentIndex]
+		if enactment.Status.PolicyGeneration == policyGeneration && enactmentstatus.IsDryRunSucceeded(&enactment.Status.Conditions) {
+			count++
+		}
+	}
+	return count
+}
+
 func (c ConditionCount) failed() CountByConditionStatus {
 	return c[nmstate.NodeNetworkConfigurationEnactmentConditionFailing]
 }
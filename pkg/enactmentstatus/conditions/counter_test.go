@@ -294,4 +294,14 @@ var _ = Describe("Enactment condition counter", func() {
 			},
 		}),
 	)
+	It("should count the successful dry-runs of the policy generation apart from the available enactments", func() {
+		dryRunEnactments := enactments(
+			enactment(1, SetDryRunSuccess),
+			enactment(2, SetDryRunSuccess),
+			enactment(2, SetDryRunFailed),
+			enactment(2, SetSuccess),
+		)
+		Expect(CountDryRunSucceeded(dryRunEnactments, 2)).To(Equal(1))
+		Expect(Count(dryRunEnactments, 2).Available()).To(Equal(1))
+	})
 })
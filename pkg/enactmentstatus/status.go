@@ -25,6 +25,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -59,6 +60,31 @@ func Update(cli client.Client, key types.NamespacedName, statusSetter func(*nmst
 	})
 }
 
+// AppendHistory stores the entry at the enactment history stamping it
+// with the current time.
+func AppendHistory(cli client.Client, key types.NamespacedName, entry nmstate.NodeNetworkConfigurationEnactmentHistoryEntry) error {
+	entry.TimeStamp = metav1.Now()
+	return Update(cli, key, func(status *nmstate.NodeNetworkConfigurationEnactmentStatus) {
+		status.AppendHistory(entry)
+	})
+}
+
+// IsAvailable returns true if the desired state has been configured at the
+// node.
+func IsAvailable(conditions *nmstate.ConditionList) bool {
+	availableCondition := conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionAvailable)
+	return availableCondition != nil && availableCondition.Status == corev1.ConditionTrue
+}
+
+// IsDryRunSucceeded returns true if the desired state has been checked at the
+// node without errors, the enactment is not available since nothing has been
+// configured.
+func IsDryRunSucceeded(conditions *nmstate.ConditionList) bool {
+	availableCondition := conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionAvailable)
+	return availableCondition != nil && availableCondition.Status == corev1.ConditionFalse &&
+		availableCondition.Reason == nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded
+}
+
 func IsProgressing(conditions *nmstate.ConditionList) bool {
 	progressingCondition := conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionProgressing)
 	if progressingCondition != nil && progressingCondition.Status == corev1.ConditionTrue {
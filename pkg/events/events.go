@@ -0,0 +1,217 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const (
+	ReasonPending           = "EnactmentPending"
+	ReasonProgressing       = "EnactmentProgressing"
+	ReasonAborted           = "EnactmentAborted"
+	ReasonProbeFailed       = "ProbeFailed"
+	ReasonRollbackStarted   = "RollbackStarted"
+	ReasonRollbackCompleted = "RollbackCompleted"
+	ReasonRollbackFailed    = "RollbackFailed"
+	ReasonCommitSucceeded   = "CommitSucceeded"
+	ReasonReconcileFailed   = "ReconcileFailed"
+)
+
+const (
+	// DefaultQPS and DefaultBurst limit the events emitted for each
+	// enactment and reason, the burst covers the transitions of a few
+	// reconciles in a row and the events of a flapping enactment are dropped.
+	DefaultQPS   = 1.0 / 60
+	DefaultBurst = 5
+)
+
+// Recorder emits events through an event recorder dropping the ones over
+// the rate limit of their key and reason, this way a large rollout or a
+// policy failing at many nodes does not flood the event store. A nil
+// Recorder drops all the events.
+type Recorder struct {
+	recorder  record.EventRecorder
+	qps       float32
+	burst     int
+	clock     flowcontrol.Clock
+	lock      sync.Mutex
+	limiters  map[string]*limiter
+	lastPrune time.Time
+}
+
+type limiter struct {
+	flowcontrol.PassiveRateLimiter
+	lastUsed time.Time
+}
+
+func NewRecorder(recorder record.EventRecorder, qps float32, burst int) *Recorder {
+	return &Recorder{
+		recorder: recorder,
+		qps:      qps,
+		burst:    burst,
+		clock:    clock.RealClock{},
+		limiters: map[string]*limiter{},
+	}
+}
+
+// Event emits the event at every one of the objects, the rate limit is
+// applied to the key and reason and not to each of the objects.
+func (r *Recorder) Event(key string, objects []runtime.Object, eventType, reason, message string) {
+	if r == nil {
+		return
+	}
+	if !r.tryAccept(key, reason) {
+		return
+	}
+	for _, object := range objects {
+		r.recorder.Event(object, eventType, reason, message)
+	}
+}
+
+// Forget drops the rate limits of the key, like the ones of a deleted
+// enactment.
+func (r *Recorder) Forget(key string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for limiterKey := range r.limiters {
+		if strings.HasPrefix(limiterKey, key+"/") {
+			delete(r.limiters, limiterKey)
+		}
+	}
+}
+
+func (r *Recorder) tryAccept(key, reason string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.clock.Now()
+	r.pruneIdle(now)
+	limiterKey := key + "/" + reason
+	l, ok := r.limiters[limiterKey]
+	if !ok {
+		l = &limiter{PassiveRateLimiter: flowcontrol.NewTokenBucketPassiveRateLimiterWithClock(r.qps, r.burst, r.clock)}
+		r.limiters[limiterKey] = l
+	}
+	l.lastUsed = now
+	return l.TryAccept()
+}
+
+// pruneIdle drops the limiters not used for the time they need to refill
+// their burst, a new limiter behaves the same, so the ones of deleted
+// enactments do not pile up.
+func (r *Recorder) pruneIdle(now time.Time) {
+	if r.qps <= 0 {
+		// the limiters never refill
+		return
+	}
+	idleTimeout := time.Duration(float64(r.burst) / float64(r.qps) * float64(time.Second))
+	if now.Sub(r.lastPrune) < idleTimeout {
+		return
+	}
+	r.lastPrune = now
+	for limiterKey, l := range r.limiters {
+		if now.Sub(l.lastUsed) >= idleTimeout {
+			delete(r.limiters, limiterKey)
+		}
+	}
+}
+
+// NodeReference references the node the way the kubelet does at its events,
+// so they are shown by kubectl describe node.
+func NodeReference(nodeName string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind: "Node",
+		Name: nodeName,
+		UID:  types.UID(nodeName),
+	}
+}
+
+// Enactment emits the events of the enactment of a policy at a node, at both
+// the policy and the node. A nil Enactment emits nothing.
+type Enactment struct {
+	recorder *Recorder
+	key      string
+	objects  []runtime.Object
+}
+
+func ForEnactment(recorder *Recorder, policy client.Object, nodeName string) *Enactment {
+	return &Enactment{
+		recorder: recorder,
+		key:      shared.EnactmentKey(nodeName, policy.GetName()).Name,
+		objects:  []runtime.Object{policy, NodeReference(nodeName)},
+	}
+}
+
+func (e *Enactment) event(eventType, reason, message string) {
+	if e == nil {
+		return
+	}
+	e.recorder.Event(e.key, e.objects, eventType, reason, message)
+}
+
+func (e *Enactment) Pending(message string) {
+	e.event(corev1.EventTypeNormal, ReasonPending, message)
+}
+
+func (e *Enactment) Progressing() {
+	e.event(corev1.EventTypeNormal, ReasonProgressing, "Applying desired state")
+}
+
+func (e *Enactment) Aborted(err error) {
+	e.event(corev1.EventTypeWarning, ReasonAborted, err.Error())
+}
+
+func (e *Enactment) ProbeFailed(probeName string, err error) {
+	e.event(corev1.EventTypeWarning, ReasonProbeFailed, fmt.Sprintf("probe '%s' failed after network reconfiguration: %v", probeName, err))
+}
+
+func (e *Enactment) RollbackStarted(cause error) {
+	e.event(corev1.EventTypeWarning, ReasonRollbackStarted, fmt.Sprintf("rolling back desired state configuration: %v", cause))
+}
+
+func (e *Enactment) RollbackCompleted() {
+	e.event(corev1.EventTypeNormal, ReasonRollbackCompleted, "rolled back to the previous network configuration")
+}
+
+func (e *Enactment) RollbackFailed(err error) {
+	e.event(corev1.EventTypeWarning, ReasonRollbackFailed, fmt.Sprintf("failed rolling back desired state configuration: %v", err))
+}
+
+func (e *Enactment) Committed() {
+	e.event(corev1.EventTypeNormal, ReasonCommitSucceeded, "desired state committed")
+}
+
+func (e *Enactment) ReconcileFailed(err error) {
+	e.event(corev1.EventTypeWarning, ReasonReconcileFailed, err.Error())
+}
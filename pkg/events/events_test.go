@@ -0,0 +1,126 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+)
+
+func receivedEvents(recorder *record.FakeRecorder) []string {
+	close(recorder.Events)
+	events := []string{}
+	for e := range recorder.Events {
+		events = append(events, e)
+	}
+	return events
+}
+
+var _ = Describe("Enactment events", func() {
+	var (
+		fakeRecorder *record.FakeRecorder
+		policy       *nmstatev1.NodeNetworkConfigurationPolicy
+	)
+	BeforeEach(func() {
+		fakeRecorder = record.NewFakeRecorder(100)
+		fakeRecorder.IncludeObject = true
+		policy = &nmstatev1.NodeNetworkConfigurationPolicy{
+			TypeMeta:   metav1.TypeMeta{Kind: "NodeNetworkConfigurationPolicy"},
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1"},
+		}
+	})
+	It("should emit the event at the policy and at the node", func() {
+		enactment := ForEnactment(NewRecorder(fakeRecorder, DefaultQPS, DefaultBurst), policy, "node01")
+		enactment.ProbeFailed("ping", fmt.Errorf("no reply"))
+		message := "Warning ProbeFailed probe 'ping' failed after network reconfiguration: no reply"
+		Expect(receivedEvents(fakeRecorder)).To(ConsistOf(
+			HavePrefix(message+" involvedObject{kind=NodeNetworkConfigurationPolicy,"),
+			HavePrefix(message+" involvedObject{kind=Node,"),
+		))
+	})
+	It("should drop the events over the rate limit", func() {
+		enactment := ForEnactment(NewRecorder(fakeRecorder, 0.001, 3), policy, "node01")
+		for i := 0; i < 5; i++ {
+			enactment.Progressing()
+		}
+		Expect(receivedEvents(fakeRecorder)).To(HaveLen(6))
+	})
+	It("should rate limit every enactment and reason on its own", func() {
+		recorder := NewRecorder(fakeRecorder, 0.001, 1)
+		otherPolicy := policy.DeepCopy()
+		otherPolicy.Name = "policy2"
+		enactment := ForEnactment(recorder, policy, "node01")
+		enactment.Progressing()
+		enactment.Progressing()
+		enactment.Committed()
+		ForEnactment(recorder, otherPolicy, "node01").Progressing()
+		ForEnactment(recorder, policy, "node02").Progressing()
+		Expect(receivedEvents(fakeRecorder)).To(HaveLen(8))
+	})
+	It("should drop the Warning events over the rate limit too", func() {
+		enactment := ForEnactment(NewRecorder(fakeRecorder, 0.001, 1), policy, "node01")
+		for i := 0; i < 5; i++ {
+			enactment.ProbeFailed("ping", fmt.Errorf("no reply"))
+		}
+		Expect(receivedEvents(fakeRecorder)).To(HaveLen(2))
+	})
+	It("should drop the rate limits of idle enactments once their burst is refilled", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Now())
+		recorder := NewRecorder(fakeRecorder, 1, 2)
+		recorder.clock = fakeClock
+		ForEnactment(recorder, policy, "node01").Progressing()
+		ForEnactment(recorder, policy, "node02").Progressing()
+		Expect(recorder.limiters).To(HaveLen(2))
+
+		fakeClock.Step(time.Second)
+		ForEnactment(recorder, policy, "node02").Committed()
+		Expect(recorder.limiters).To(HaveLen(3))
+
+		fakeClock.Step(time.Second)
+		ForEnactment(recorder, policy, "node02").Committed()
+		Expect(recorder.limiters).To(HaveKey(shared.EnactmentKey("node02", policy.Name).Name + "/" + ReasonCommitSucceeded))
+		Expect(recorder.limiters).To(HaveLen(1))
+	})
+	It("should drop the rate limits of forgotten enactments", func() {
+		recorder := NewRecorder(fakeRecorder, 0.001, 1)
+		enactment := ForEnactment(recorder, policy, "node01")
+		enactment.Progressing()
+		enactment.Progressing()
+		recorder.Forget(shared.EnactmentKey("node01", policy.Name).Name)
+		Expect(recorder.limiters).To(BeEmpty())
+		enactment.Progressing()
+		Expect(receivedEvents(fakeRecorder)).To(HaveLen(4))
+	})
+	It("should drop the events without recorder", func() {
+		enactment := ForEnactment(nil, policy, "node01")
+		enactment.Committed()
+		var nilEnactment *Enactment
+		nilEnactment.Committed()
+		Expect(receivedEvents(fakeRecorder)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,240 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+// maxAllocateAttempts bounds the retries when other nodes allocate the
+// same free address at the same time
+const maxAllocateAttempts = 10
+
+// Allocate returns the address of pool held by node, allocating a free one
+// if it holds none. The policy is recorded at the allocation so it is
+// released once no policy uses it. An address the pool range does not
+// contain anymore is released and a new one is allocated.
+func Allocate(ctx context.Context, cli client.Reader, store Store, pool, node, policy string) (Address, error) {
+	poolRange, err := getRange(ctx, cli, pool)
+	if err != nil {
+		return Address{}, err
+	}
+	for attempt := 0; attempt < maxAllocateAttempts; attempt++ {
+		allocations, err := store.List(ctx, pool)
+		if err != nil {
+			return Address{}, err
+		}
+		if allocation := findNode(allocations, node); allocation != nil {
+			address, err := allocationAddress(allocation)
+			if err != nil {
+				return Address{}, err
+			}
+			if !poolRange.Contains(address) {
+				// the pool range has changed, allocate a new one
+				if err := store.Delete(ctx, pool, address.IP); err != nil {
+					return Address{}, err
+				}
+				continue
+			}
+			if hasPolicy(allocation, policy) {
+				return address, nil
+			}
+			err = store.Update(ctx, pool, address.IP, func(allocation *Allocation) {
+				if !hasPolicy(allocation, policy) {
+					allocation.Policies = append(allocation.Policies, policy)
+				}
+			})
+			if errors.Is(err, ErrNotAllocated) {
+				// released meanwhile, allocate a new one
+				continue
+			}
+			if err != nil {
+				return Address{}, err
+			}
+			return address, nil
+		}
+
+		address, found := poolRange.Free(allocatedIPs(allocations))
+		if !found {
+			return Address{}, fmt.Errorf("NodeIPPool %s is exhausted", pool)
+		}
+		err = store.Create(ctx, Allocation{
+			Pool:     pool,
+			Node:     node,
+			Address:  address.String(),
+			Policies: []string{policy},
+		})
+		if errors.Is(err, ErrAllocated) {
+			// allocated by another node meanwhile, look for the next one
+			continue
+		}
+		if err != nil {
+			return Address{}, err
+		}
+		return address, nil
+	}
+	return Address{}, fmt.Errorf("failed allocating an address of NodeIPPool %s after %d attempts", pool, maxAllocateAttempts)
+}
+
+// Lookup returns the address of pool held by node, or the one it would get
+// if it holds none or the pool range does not contain it anymore, without
+// allocating it.
+func Lookup(ctx context.Context, cli client.Reader, store Store, pool, node string) (Address, error) {
+	poolRange, err := getRange(ctx, cli, pool)
+	if err != nil {
+		return Address{}, err
+	}
+	allocations, err := store.List(ctx, pool)
+	if err != nil {
+		return Address{}, err
+	}
+	if allocation := findNode(allocations, node); allocation != nil {
+		address, err := allocationAddress(allocation)
+		if err != nil {
+			return Address{}, err
+		}
+		if poolRange.Contains(address) {
+			return address, nil
+		}
+	}
+	address, found := poolRange.Free(allocatedIPs(allocations))
+	if !found {
+		return Address{}, fmt.Errorf("NodeIPPool %s is exhausted", pool)
+	}
+	return address, nil
+}
+
+// Release removes the policies that do not use the pool anymore at the
+// node from its allocations, nodes not existing anymore release all of them.
+func Release(ctx context.Context, store Store, pool string, isUsed func(node, policy string) (bool, error)) error {
+	allocations, err := store.List(ctx, pool)
+	if err != nil {
+		return err
+	}
+	for i := range allocations {
+		allocation := &allocations[i]
+		used := []string{}
+		for _, policy := range allocation.Policies {
+			isPolicyUsed, err := isUsed(allocation.Node, policy)
+			if err != nil {
+				return err
+			}
+			if isPolicyUsed {
+				used = append(used, policy)
+			}
+		}
+		if len(used) == len(allocation.Policies) {
+			continue
+		}
+		address, err := allocationAddress(allocation)
+		if err != nil {
+			return err
+		}
+		err = store.Update(ctx, pool, address.IP, func(allocation *Allocation) {
+			policies := []string{}
+			for _, policy := range allocation.Policies {
+				if contains(used, policy) {
+					policies = append(policies, policy)
+				}
+			}
+			allocation.Policies = policies
+		})
+		if err != nil && !errors.Is(err, ErrNotAllocated) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleasePool releases every allocation of a pool that does not exist anymore
+func ReleasePool(ctx context.Context, store Store, pool string) error {
+	allocations, err := store.List(ctx, pool)
+	if err != nil {
+		return err
+	}
+	for i := range allocations {
+		address, err := allocationAddress(&allocations[i])
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(ctx, pool, address.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getRange(ctx context.Context, cli client.Reader, pool string) (*Range, error) {
+	instance := &nmstatev1beta1.NodeIPPool{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: pool}, instance); err != nil {
+		return nil, fmt.Errorf("failed getting NodeIPPool %s: %w", pool, err)
+	}
+	poolRange, err := NewRange(instance.Spec.Range)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NodeIPPool %s: %w", pool, err)
+	}
+	return poolRange, nil
+}
+
+func findNode(allocations []Allocation, node string) *Allocation {
+	for i := range allocations {
+		if allocations[i].Node == node {
+			return &allocations[i]
+		}
+	}
+	return nil
+}
+
+func allocatedIPs(allocations []Allocation) map[netip.Addr]bool {
+	ips := map[netip.Addr]bool{}
+	for i := range allocations {
+		if address, err := allocationAddress(&allocations[i]); err == nil {
+			ips[address.IP] = true
+		}
+	}
+	return ips
+}
+
+func allocationAddress(allocation *Allocation) (Address, error) {
+	prefix, err := netip.ParsePrefix(allocation.Address)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %q at NodeIPPool %s allocation: %w", allocation.Address, allocation.Pool, err)
+	}
+	return Address{IP: prefix.Addr(), PrefixLength: prefix.Bits()}, nil
+}
+
+func hasPolicy(allocation *Allocation, policy string) bool {
+	return contains(allocation.Policies, policy)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
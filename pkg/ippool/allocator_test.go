@@ -0,0 +1,146 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var _ = Describe("NodeIPPool allocator", func() {
+	var (
+		cli   client.Client
+		store Store
+		ctx   = context.TODO()
+	)
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeIPPool{},
+			&nmstatev1beta1.NodeIPAllocation{},
+			&nmstatev1beta1.NodeIPAllocationList{},
+		)
+		pool := &nmstatev1beta1.NodeIPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+			Spec: shared.NodeIPPoolSpec{
+				Range: shared.NodeIPPoolRange{
+					CIDR:    "10.10.10.0/24",
+					Start:   "10.10.10.10",
+					End:     "10.10.10.13",
+					Exclude: []string{"10.10.10.11"},
+				},
+			},
+		}
+		cli = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(pool).Build()
+		var err error
+		store, err = NewStore(CRDStoreName, cli)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	allocate := func(node, policy string) string {
+		address, err := Allocate(ctx, cli, store, "storage", node, policy)
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		return address.String()
+	}
+	listAllocations := func() []Allocation {
+		allocations, err := store.List(ctx, "storage")
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		return allocations
+	}
+	It("should allocate a unique address per node skipping the excluded ones", func() {
+		Expect(allocate("node01", "policy1")).To(Equal("10.10.10.10/24"))
+		Expect(allocate("node02", "policy1")).To(Equal("10.10.10.12/24"))
+		Expect(allocate("node01", "policy2")).To(Equal("10.10.10.10/24"))
+		Expect(listAllocations()).To(ConsistOf(
+			Allocation{Pool: "storage", Node: "node01", Address: "10.10.10.10/24", Policies: []string{"policy1", "policy2"}},
+			Allocation{Pool: "storage", Node: "node02", Address: "10.10.10.12/24", Policies: []string{"policy1"}},
+		))
+	})
+	It("should fail when the pool is exhausted", func() {
+		allocate("node01", "policy1")
+		allocate("node02", "policy1")
+		allocate("node03", "policy1")
+		_, err := Allocate(ctx, cli, store, "storage", "node04", "policy1")
+		Expect(err).To(MatchError("NodeIPPool storage is exhausted"))
+	})
+	It("should fail when the pool does not exist", func() {
+		_, err := Allocate(ctx, cli, store, "missing", "node01", "policy1")
+		Expect(err).To(MatchError(ContainSubstring("failed getting NodeIPPool missing")))
+	})
+	It("Lookup should return the address the node would get without allocating it", func() {
+		allocate("node01", "policy1")
+		address, err := Lookup(ctx, cli, store, "storage", "node01")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address.String()).To(Equal("10.10.10.10/24"))
+		address, err = Lookup(ctx, cli, store, "storage", "node02")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address.String()).To(Equal("10.10.10.12/24"))
+		Expect(listAllocations()).To(HaveLen(1))
+	})
+	It("Release should remove unused policies and release the addresses without policies", func() {
+		allocate("node01", "policy1")
+		allocate("node01", "policy2")
+		allocate("node02", "policy1")
+		err := Release(ctx, store, "storage", func(node, policy string) (bool, error) {
+			return node == "node01" && policy == "policy2", nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listAllocations()).To(ConsistOf(
+			Allocation{Pool: "storage", Node: "node01", Address: "10.10.10.10/24", Policies: []string{"policy2"}},
+		))
+		Expect(allocate("node03", "policy1")).To(Equal("10.10.10.12/24"), "should reuse the released address")
+	})
+	It("should not allocate an address twice when the pool range changes", func() {
+		allocate("node01", "policy1")
+		allocate("node02", "policy1")
+
+		pool := &nmstatev1beta1.NodeIPPool{}
+		Expect(cli.Get(ctx, types.NamespacedName{Name: "storage"}, pool)).To(Succeed())
+		pool.Spec.Range.Start = "10.10.10.11"
+		pool.Spec.Range.End = "10.10.10.14"
+		Expect(cli.Update(ctx, pool)).To(Succeed())
+
+		address, err := Lookup(ctx, cli, store, "storage", "node03")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address.String()).To(Equal("10.10.10.13/24"))
+		Expect(allocate("node03", "policy1")).To(Equal("10.10.10.13/24"))
+		Expect(allocate("node02", "policy1")).To(Equal("10.10.10.12/24"), "should keep the address still in the range")
+		Expect(allocate("node01", "policy1")).To(Equal("10.10.10.14/24"), "should replace the address out of the range")
+		Expect(listAllocations()).To(ConsistOf(
+			Allocation{Pool: "storage", Node: "node01", Address: "10.10.10.14/24", Policies: []string{"policy1"}},
+			Allocation{Pool: "storage", Node: "node02", Address: "10.10.10.12/24", Policies: []string{"policy1"}},
+			Allocation{Pool: "storage", Node: "node03", Address: "10.10.10.13/24", Policies: []string{"policy1"}},
+		))
+	})
+	It("ReleasePool should release every address of the pool", func() {
+		allocate("node01", "policy1")
+		allocate("node02", "policy1")
+		Expect(ReleasePool(ctx, store, "storage")).To(Succeed())
+		Expect(listAllocations()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,139 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+// CRDStoreName is the default store, it keeps every allocation as a
+// NodeIPAllocation
+const CRDStoreName = "crd"
+
+func init() {
+	Register(CRDStoreName, func(cli client.Client) Store { return &crdStore{cli: cli} })
+}
+
+type crdStore struct {
+	cli client.Client
+}
+
+// allocationName is unique for every pool address so the API server
+// rejects allocating it twice, the IPv6 colons are not valid at names.
+func allocationName(pool string, ip netip.Addr) string {
+	return fmt.Sprintf("%s.%s", pool, strings.ReplaceAll(ip.String(), ":", "-"))
+}
+
+func (s *crdStore) List(ctx context.Context, pool string) ([]Allocation, error) {
+	allocationList := nmstatev1beta1.NodeIPAllocationList{}
+	options := []client.ListOption{}
+	if pool != "" {
+		options = append(options, client.MatchingLabels{shared.IPAllocationPoolLabel: pool})
+	}
+	if err := s.cli.List(ctx, &allocationList, options...); err != nil {
+		return nil, fmt.Errorf("failed listing NodeIPAllocations: %w", err)
+	}
+	allocations := []Allocation{}
+	for i := range allocationList.Items {
+		allocations = append(allocations, fromNodeIPAllocation(&allocationList.Items[i]))
+	}
+	return allocations, nil
+}
+
+func (s *crdStore) Create(ctx context.Context, allocation Allocation) error {
+	address, err := allocationAddress(&allocation)
+	if err != nil {
+		return err
+	}
+	instance := &nmstatev1beta1.NodeIPAllocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: allocationName(allocation.Pool, address.IP),
+			Labels: map[string]string{
+				shared.IPAllocationPoolLabel: allocation.Pool,
+				shared.IPAllocationNodeLabel: allocation.Node,
+			},
+		},
+		Spec: shared.NodeIPAllocationSpec{
+			Pool:     allocation.Pool,
+			Node:     allocation.Node,
+			Address:  allocation.Address,
+			Policies: allocation.Policies,
+		},
+	}
+	err = s.cli.Create(ctx, instance)
+	if apierrors.IsAlreadyExists(err) {
+		return ErrAllocated
+	}
+	if err != nil {
+		return fmt.Errorf("failed creating NodeIPAllocation %s: %w", instance.Name, err)
+	}
+	return nil
+}
+
+func (s *crdStore) Update(ctx context.Context, pool string, ip netip.Addr, update func(*Allocation)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeIPAllocation{}
+		err := s.cli.Get(ctx, types.NamespacedName{Name: allocationName(pool, ip)}, instance)
+		if apierrors.IsNotFound(err) {
+			return ErrNotAllocated
+		}
+		if err != nil {
+			return err
+		}
+		allocation := fromNodeIPAllocation(instance)
+		update(&allocation)
+		if len(allocation.Policies) == 0 {
+			return client.IgnoreNotFound(s.cli.Delete(ctx, instance, client.Preconditions{ResourceVersion: &instance.ResourceVersion}))
+		}
+		instance.Spec.Policies = allocation.Policies
+		return s.cli.Update(ctx, instance)
+	})
+}
+
+func (s *crdStore) Delete(ctx context.Context, pool string, ip netip.Addr) error {
+	instance := &nmstatev1beta1.NodeIPAllocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: allocationName(pool, ip),
+		},
+	}
+	if err := s.cli.Delete(ctx, instance); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed deleting NodeIPAllocation %s: %w", instance.Name, err)
+	}
+	return nil
+}
+
+func fromNodeIPAllocation(instance *nmstatev1beta1.NodeIPAllocation) Allocation {
+	return Allocation{
+		Pool:     instance.Spec.Pool,
+		Node:     instance.Spec.Node,
+		Address:  instance.Spec.Address,
+		Policies: instance.Spec.Policies,
+	}
+}
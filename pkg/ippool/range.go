@@ -0,0 +1,211 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// Address is an address of a pool with the prefix length of its subnet
+type Address struct {
+	IP           netip.Addr
+	PrefixLength int
+}
+
+func (a Address) String() string {
+	return netip.PrefixFrom(a.IP, a.PrefixLength).String()
+}
+
+// Range is the addresses of a NodeIPPool, every address is identified by
+// its offset from the range start.
+type Range struct {
+	subnet  netip.Prefix
+	start   *big.Int
+	size    int64
+	exclude []netip.Prefix
+}
+
+// NewRange checks the NodeIPPool range and returns its addresses
+func NewRange(poolRange shared.NodeIPPoolRange) (*Range, error) {
+	subnet, err := netip.ParsePrefix(poolRange.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr %q: %v", poolRange.CIDR, err)
+	}
+	subnet = subnet.Masked()
+
+	start, end := firstHost(subnet), lastHost(subnet)
+	if poolRange.Start != "" {
+		if start, err = parseRangeAddress(subnet, poolRange.Start); err != nil {
+			return nil, err
+		}
+	}
+	if poolRange.End != "" {
+		if end, err = parseRangeAddress(subnet, poolRange.End); err != nil {
+			return nil, err
+		}
+	}
+	if end.Less(start) {
+		return nil, fmt.Errorf("range end %s is before range start %s", end, start)
+	}
+
+	exclude := []netip.Prefix{}
+	for _, excluded := range poolRange.Exclude {
+		prefix, err := parseExclude(excluded)
+		if err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, prefix)
+	}
+
+	size := new(big.Int).Sub(toInt(end), toInt(start))
+	size.Add(size, big.NewInt(1))
+	if !size.IsInt64() {
+		size.SetInt64(math.MaxInt64)
+	}
+	return &Range{
+		subnet:  subnet,
+		start:   toInt(start),
+		size:    size.Int64(),
+		exclude: exclude,
+	}, nil
+}
+
+// Size returns the number of addresses of the range, including the excluded ones
+func (r *Range) Size() int64 {
+	return r.size
+}
+
+// Address returns the address at offset
+func (r *Range) Address(offset int64) (Address, error) {
+	if offset < 0 || offset >= r.size {
+		return Address{}, fmt.Errorf("offset %d out of range with %d addresses", offset, r.size)
+	}
+	ip := fromInt(new(big.Int).Add(r.start, big.NewInt(offset)), r.subnet.Addr().Is4())
+	return Address{IP: ip, PrefixLength: r.subnet.Bits()}, nil
+}
+
+// Excluded returns true if the address at offset is not handed out
+func (r *Range) Excluded(offset int64) bool {
+	address, err := r.Address(offset)
+	if err != nil {
+		return true
+	}
+	for _, excluded := range r.exclude {
+		if excluded.Contains(address.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if the address is handed out by the range, with
+// the prefix length of its subnet
+func (r *Range) Contains(address Address) bool {
+	if address.PrefixLength != r.subnet.Bits() || address.IP.Is4() != r.subnet.Addr().Is4() {
+		return false
+	}
+	offset := new(big.Int).Sub(toInt(address.IP), r.start)
+	if offset.Sign() < 0 || !offset.IsInt64() || offset.Int64() >= r.size {
+		return false
+	}
+	return !r.Excluded(offset.Int64())
+}
+
+// Free returns the first address not excluded nor allocated
+func (r *Range) Free(allocated map[netip.Addr]bool) (Address, bool) {
+	for offset := int64(0); offset < r.size; offset++ {
+		if r.Excluded(offset) {
+			continue
+		}
+		address, err := r.Address(offset)
+		if err == nil && !allocated[address.IP] {
+			return address, true
+		}
+	}
+	return Address{}, false
+}
+
+func parseRangeAddress(subnet netip.Prefix, address string) (netip.Addr, error) {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid range address %q: %v", address, err)
+	}
+	if !subnet.Contains(ip) {
+		return netip.Addr{}, fmt.Errorf("range address %s is not at cidr %s", ip, subnet)
+	}
+	return ip, nil
+}
+
+func parseExclude(excluded string) (netip.Prefix, error) {
+	if strings.Contains(excluded, "/") {
+		prefix, err := netip.ParsePrefix(excluded)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid excluded cidr %q: %v", excluded, err)
+		}
+		return prefix.Masked(), nil
+	}
+	ip, err := netip.ParseAddr(excluded)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid excluded address %q: %v", excluded, err)
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
+
+// firstHost skips the network address of IPv4 subnets with a broadcast
+// address and the subnet router anycast address of IPv6 ones.
+func firstHost(subnet netip.Prefix) netip.Addr {
+	if subnet.Bits() >= subnet.Addr().BitLen()-1 {
+		return subnet.Addr()
+	}
+	return subnet.Addr().Next()
+}
+
+// lastHost skips the broadcast address of IPv4 subnets
+func lastHost(subnet netip.Prefix) netip.Addr {
+	last := fromInt(
+		new(big.Int).Sub(
+			new(big.Int).Add(toInt(subnet.Addr()), new(big.Int).Lsh(big.NewInt(1), uint(subnet.Addr().BitLen()-subnet.Bits()))),
+			big.NewInt(1),
+		),
+		subnet.Addr().Is4(),
+	)
+	if subnet.Addr().Is4() && subnet.Bits() < 31 {
+		return last.Prev()
+	}
+	return last
+}
+
+func toInt(ip netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(ip.AsSlice())
+}
+
+func fromInt(value *big.Int, is4 bool) netip.Addr {
+	size := 16
+	if is4 {
+		size = 4
+	}
+	bytes := value.FillBytes(make([]byte, size))
+	ip, _ := netip.AddrFromSlice(bytes)
+	return ip
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("NodeIPPool range", func() {
+	type rangeCase struct {
+		poolRange         shared.NodeIPPoolRange
+		expectedSize      int64
+		expectedAddresses map[int64]string
+		expectedExcluded  []int64
+		expectedErr       string
+	}
+	DescribeTable("NewRange",
+		func(c rangeCase) {
+			r, err := NewRange(c.poolRange)
+			if c.expectedErr != "" {
+				Expect(err).To(MatchError(c.expectedErr))
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Size()).To(Equal(c.expectedSize))
+			for offset, expectedAddress := range c.expectedAddresses {
+				address, err := r.Address(offset)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(address.String()).To(Equal(expectedAddress))
+			}
+			for _, offset := range c.expectedExcluded {
+				Expect(r.Excluded(offset)).To(BeTrue(), "offset %d should be excluded", offset)
+			}
+		},
+		Entry("with an IPv4 subnet skips the network and broadcast addresses", rangeCase{
+			poolRange:         shared.NodeIPPoolRange{CIDR: "10.10.10.0/24"},
+			expectedSize:      254,
+			expectedAddresses: map[int64]string{0: "10.10.10.1/24", 253: "10.10.10.254/24"},
+		}),
+		Entry("with an IPv4 range crossing octets and exclusions", rangeCase{
+			poolRange: shared.NodeIPPoolRange{
+				CIDR:    "10.10.0.0/16",
+				Start:   "10.10.11.4",
+				End:     "10.10.12.10",
+				Exclude: []string{"10.10.11.5", "10.10.12.0/29"},
+			},
+			expectedSize:      263,
+			expectedAddresses: map[int64]string{0: "10.10.11.4/16", 252: "10.10.12.0/16", 262: "10.10.12.10/16"},
+			expectedExcluded:  []int64{1, 252, 259},
+		}),
+		Entry("with an IPv6 range", rangeCase{
+			poolRange: shared.NodeIPPoolRange{
+				CIDR:    "2001:db8::/116",
+				Start:   "2001:db8::f",
+				End:     "2001:db8::ff",
+				Exclude: []string{"2001:db8::10"},
+			},
+			expectedSize:      241,
+			expectedAddresses: map[int64]string{0: "2001:db8::f/116", 240: "2001:db8::ff/116"},
+			expectedExcluded:  []int64{1},
+		}),
+		Entry("with an address out of the subnet", rangeCase{
+			poolRange:   shared.NodeIPPoolRange{CIDR: "10.10.10.0/24", Start: "10.10.11.1"},
+			expectedErr: "range address 10.10.11.1 is not at cidr 10.10.10.0/24",
+		}),
+		Entry("with the end before the start", rangeCase{
+			poolRange:   shared.NodeIPPoolRange{CIDR: "10.10.10.0/24", Start: "10.10.10.20", End: "10.10.10.10"},
+			expectedErr: "range end 10.10.10.10 is before range start 10.10.10.20",
+		}),
+	)
+	It("Free should skip allocated and excluded addresses", func() {
+		r, err := NewRange(shared.NodeIPPoolRange{CIDR: "10.10.10.0/29", Exclude: []string{"10.10.10.2"}})
+		Expect(err).ToNot(HaveOccurred())
+		allocated := map[netip.Addr]bool{netip.MustParseAddr("10.10.10.1"): true}
+		address, found := r.Free(allocated)
+		Expect(found).To(BeTrue())
+		Expect(address.String()).To(Equal("10.10.10.3/29"))
+		for _, ip := range []string{"10.10.10.3", "10.10.10.4", "10.10.10.5", "10.10.10.6"} {
+			allocated[netip.MustParseAddr(ip)] = true
+		}
+		_, found = r.Free(allocated)
+		Expect(found).To(BeFalse())
+	})
+	DescribeTable("Contains",
+		func(address string, expected bool) {
+			r, err := NewRange(shared.NodeIPPoolRange{
+				CIDR:    "10.10.10.0/24",
+				Start:   "10.10.10.10",
+				End:     "10.10.10.20",
+				Exclude: []string{"10.10.10.15"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			prefix := netip.MustParsePrefix(address)
+			Expect(r.Contains(Address{IP: prefix.Addr(), PrefixLength: prefix.Bits()})).To(Equal(expected))
+		},
+		Entry("an address of the range", "10.10.10.10/24", true),
+		Entry("an address before the range", "10.10.10.9/24", false),
+		Entry("an address after the range", "10.10.10.21/24", false),
+		Entry("an excluded address", "10.10.10.15/24", false),
+		Entry("an address with another prefix length", "10.10.10.10/16", false),
+		Entry("an IPv6 address", "::a0a:a0a/120", false),
+	)
+})
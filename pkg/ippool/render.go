@@ -0,0 +1,163 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// ReferencePrefix starts the desired state references to pools, like
+// "{{ ippool.storage }}"
+const ReferencePrefix = "ippool"
+
+const (
+	ipField           = "ip"
+	prefixLengthField = "prefix-length"
+)
+
+var referenceRegexp = regexp.MustCompile(`{{\s*` + ReferencePrefix + `\.([^{}]*?)\s*}}`)
+
+// reference is "ippool.<pool>" for the whole address, or
+// "ippool.<pool>.ip" and "ippool.<pool>.prefix-length" for one field
+type reference struct {
+	pool  string
+	field string
+}
+
+func parseReference(match []string) reference {
+	for _, field := range []string{ipField, prefixLengthField} {
+		if pool := strings.TrimSuffix(match[1], "."+field); pool != match[1] {
+			return reference{pool: pool, field: field}
+		}
+	}
+	return reference{pool: match[1]}
+}
+
+// value returns the reference value replacing a whole desired state value,
+// the whole address is an nmstate address entry.
+func (r reference) value(address Address) interface{} {
+	switch r.field {
+	case ipField:
+		return address.IP.String()
+	case prefixLengthField:
+		return address.PrefixLength
+	}
+	return map[string]interface{}{
+		ipField:           address.IP.String(),
+		prefixLengthField: address.PrefixLength,
+	}
+}
+
+// text returns the reference value embedded at a desired state string
+func (r reference) text(address Address) string {
+	switch r.field {
+	case ipField:
+		return address.IP.String()
+	case prefixLengthField:
+		return strconv.Itoa(address.PrefixLength)
+	}
+	return address.String()
+}
+
+// References returns the pools referenced by the desired state
+func References(desiredState shared.State) ([]string, error) {
+	pools := map[string]bool{}
+	if !strings.Contains(string(desiredState.Raw), ReferencePrefix) {
+		return []string{}, nil
+	}
+	var state interface{}
+	if err := yaml.Unmarshal(desiredState.Raw, &state); err != nil {
+		return nil, fmt.Errorf("failed decoding desired state: %w", err)
+	}
+	replaceStrings(state, func(value string) interface{} {
+		for _, match := range referenceRegexp.FindAllStringSubmatch(value, -1) {
+			pools[parseReference(match).pool] = true
+		}
+		return value
+	})
+	names := []string{}
+	for pool := range pools {
+		names = append(names, pool)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Render replaces the pool references of the desired state with the
+// addresses returned by resolve, it is called once per referenced pool.
+func Render(desiredState shared.State, resolve func(pool string) (Address, error)) (shared.State, error) {
+	pools, err := References(desiredState)
+	if err != nil || len(pools) == 0 {
+		return desiredState, err
+	}
+	addresses := map[string]Address{}
+	for _, pool := range pools {
+		address, err := resolve(pool)
+		if err != nil {
+			return desiredState, fmt.Errorf("failed resolving NodeIPPool %s address: %w", pool, err)
+		}
+		addresses[pool] = address
+	}
+
+	var state interface{}
+	if err := yaml.Unmarshal(desiredState.Raw, &state); err != nil {
+		return desiredState, fmt.Errorf("failed decoding desired state: %w", err)
+	}
+	state = replaceStrings(state, func(value string) interface{} {
+		matches := referenceRegexp.FindAllStringSubmatchIndex(value, -1)
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+			ref := parseReference(referenceRegexp.FindStringSubmatch(value))
+			return ref.value(addresses[ref.pool])
+		}
+		return referenceRegexp.ReplaceAllStringFunc(value, func(text string) string {
+			ref := parseReference(referenceRegexp.FindStringSubmatch(text))
+			return ref.text(addresses[ref.pool])
+		})
+	})
+	rendered, err := yaml.Marshal(state)
+	if err != nil {
+		return desiredState, fmt.Errorf("failed encoding desired state: %w", err)
+	}
+	return shared.NewState(string(rendered)), nil
+}
+
+// replaceStrings replaces every string value of the state with the result
+// of fn, map keys are kept.
+func replaceStrings(value interface{}, fn func(string) interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = replaceStrings(v[key], fn)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceStrings(v[i], fn)
+		}
+	}
+	return value
+}
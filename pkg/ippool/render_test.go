@@ -0,0 +1,96 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"fmt"
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("NodeIPPool references", func() {
+	addresses := map[string]Address{
+		"storage":      {IP: netip.MustParseAddr("10.10.10.5"), PrefixLength: 24},
+		"storage.ipv6": {IP: netip.MustParseAddr("2001:db8::5"), PrefixLength: 64},
+	}
+	resolve := func(pool string) (Address, error) {
+		address, found := addresses[pool]
+		if !found {
+			return Address{}, fmt.Errorf("not found")
+		}
+		return address, nil
+	}
+	It("References should return every referenced pool once", func() {
+		pools, err := References(shared.NewState(`
+interfaces:
+- name: eth1
+  ipv4:
+    address:
+    - "{{ ippool.storage }}"
+  ipv6:
+    address:
+    - ip: "{{ippool.storage.ipv6.ip}}"
+      prefix-length: "{{ ippool.storage.ipv6.prefix-length }}"
+  description: "storage {{ ippool.storage.ip }}"
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pools).To(Equal([]string{"storage", "storage.ipv6"}))
+	})
+	It("Render should replace the references with the pool addresses", func() {
+		rendered, err := Render(shared.NewState(`
+interfaces:
+- name: eth1
+  ipv4:
+    address:
+    - "{{ ippool.storage }}"
+  ipv6:
+    address:
+    - ip: "{{ippool.storage.ipv6.ip}}"
+      prefix-length: "{{ ippool.storage.ipv6.prefix-length }}"
+  description: "storage {{ ippool.storage }} at {{ ippool.storage.ip }}"
+`), resolve)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.String()).To(MatchYAML(`
+interfaces:
+- name: eth1
+  ipv4:
+    address:
+    - ip: 10.10.10.5
+      prefix-length: 24
+  ipv6:
+    address:
+    - ip: 2001:db8::5
+      prefix-length: 64
+  description: "storage 10.10.10.5/24 at 10.10.10.5"
+`))
+	})
+	It("Render should keep a desired state without references", func() {
+		desiredState := shared.NewState("interfaces: [{name: eth1, state: up}]\n")
+		rendered, err := Render(desiredState, resolve)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered).To(Equal(desiredState))
+	})
+	It("Render should fail if a pool cannot be resolved", func() {
+		_, err := Render(shared.NewState(`interfaces: [{name: eth1, ipv4: {address: ["{{ ippool.missing }}"]}}]`), resolve)
+		Expect(err).To(MatchError("failed resolving NodeIPPool missing address: not found"))
+	})
+})
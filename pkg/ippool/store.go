@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ippool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrAllocated is returned by Store.Create when the address is already
+	// allocated at the pool
+	ErrAllocated = errors.New("address already allocated")
+	// ErrNotAllocated is returned by Store.Update when the address is not
+	// allocated at the pool
+	ErrNotAllocated = errors.New("address not allocated")
+)
+
+// Allocation is an address of a pool held by a node
+type Allocation struct {
+	Pool string
+	Node string
+	// Address with the prefix length of the pool subnet
+	Address string
+	// Policies using the address at the node
+	Policies []string
+}
+
+// Store keeps the allocations of every pool, it is shared by the handlers
+// of every node so creating an allocation has to fail if another handler
+// already allocated the address. The allocations are identified by the
+// address without prefix length, so they stay unique if the pool range
+// changes.
+type Store interface {
+	// List returns the allocations of pool, or of every pool if it is empty
+	List(ctx context.Context, pool string) ([]Allocation, error)
+	// Create stores a new allocation, failing with ErrAllocated if the
+	// address is already allocated at the pool
+	Create(ctx context.Context, allocation Allocation) error
+	// Update changes the allocation of ip, failing with ErrNotAllocated if
+	// it does not exist. Allocations left without policies are released.
+	Update(ctx context.Context, pool string, ip netip.Addr, update func(*Allocation)) error
+	// Delete releases the allocation of ip
+	Delete(ctx context.Context, pool string, ip netip.Addr) error
+}
+
+var (
+	storesLock sync.RWMutex
+	stores     = map[string]func(client.Client) Store{}
+)
+
+// Register makes a store available by name, it is meant to be called from
+// init functions.
+func Register(name string, factory func(client.Client) Store) {
+	storesLock.Lock()
+	defer storesLock.Unlock()
+	stores[name] = factory
+}
+
+// Stores returns the names of the registered stores
+func Stores() []string {
+	storesLock.RLock()
+	defer storesLock.RUnlock()
+	names := []string{}
+	for name := range stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewStore returns the store registered as name using cli to reach the cluster
+func NewStore(name string, cli client.Client) (Store, error) {
+	storesLock.RLock()
+	factory, found := stores[name]
+	storesLock.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown ip pool store %q, supported stores: %v", name, Stores())
+	}
+	return factory(cli), nil
+}
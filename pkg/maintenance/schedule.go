@@ -0,0 +1,174 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleSearch bounds the search of the next schedule time so
+// expressions that never match, like "0 0 30 2 *", do not loop forever.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression, each field contains a bit per
+// allowed value.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// dayOfMonthAny and dayOfWeekAny are true when the field is "*", if both
+	// day fields are restricted a day matches any of them, as cron does.
+	dayOfMonthAny, dayOfWeekAny bool
+}
+
+// ParseSchedule parses a standard five fields cron expression, every field
+// accepts "*", values, ranges "a-b", lists "a,b" and steps "*/n" or "a-b/n".
+// Day of week is 0-7 with 0 and 7 being Sunday.
+func ParseSchedule(expression string) (*Schedule, error) {
+	tokens := strings.Fields(expression)
+	if len(tokens) != len(fields) {
+		return nil, fmt.Errorf("expected %d fields at schedule %q, found %d", len(fields), expression, len(tokens))
+	}
+	bits := make([]uint64, len(fields))
+	for i, token := range tokens {
+		var err error
+		bits[i], err = parseField(token, fields[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Sunday can be 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &Schedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dayOfMonth:    bits[2],
+		month:         bits[3],
+		dayOfWeek:     bits[4],
+		dayOfMonthAny: tokens[2] == "*",
+		dayOfWeekAny:  tokens[4] == "*",
+	}, nil
+}
+
+func parseField(token string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(token, ",") {
+		rangeAndStep := strings.SplitN(item, "/", 2)
+		start, end := f.min, f.max
+		if rangeAndStep[0] != "*" {
+			bounds := strings.SplitN(rangeAndStep[0], "-", 2)
+			var err error
+			start, err = parseValue(bounds[0], f)
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = parseValue(bounds[1], f)
+				if err != nil {
+					return 0, err
+				}
+			}
+			if end < start {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rangeAndStep[0])
+			}
+		}
+		step := 1
+		if len(rangeAndStep) == 2 {
+			var err error
+			step, err = strconv.Atoi(rangeAndStep[1])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, rangeAndStep[1])
+			}
+			if rangeAndStep[0] != "*" && !strings.Contains(rangeAndStep[0], "-") {
+				end = f.max
+			}
+		}
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", f.name, value)
+	}
+	if parsed < f.min || parsed > f.max {
+		return 0, fmt.Errorf("%s %d out of range [%d-%d]", f.name, parsed, f.min, f.max)
+	}
+	return parsed, nil
+}
+
+// Next returns the first time after t matching the schedule, at the location
+// of t, or the zero time if there is none.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := has(s.dayOfMonth, t.Day())
+	dayOfWeek := has(s.dayOfWeek, int(t.Weekday()))
+	if s.dayOfMonthAny || s.dayOfWeekAny {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
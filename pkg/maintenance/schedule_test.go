@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	return t
+}
+
+var _ = Describe("Maintenance schedule", func() {
+	DescribeTable("when calculating the next time",
+		func(expression, from, expectedNext string) {
+			schedule, err := ParseSchedule(expression)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Next(parseTime(from))).To(Equal(parseTime(expectedNext)))
+		},
+		Entry("every minute", "* * * * *", "2023-03-01T10:00:30Z", "2023-03-01T10:01:00Z"),
+		Entry("fixed time later the same day", "0 22 * * *", "2023-03-01T10:00:00Z", "2023-03-01T22:00:00Z"),
+		Entry("fixed time already passed the same day", "0 22 * * *", "2023-03-01T22:00:00Z", "2023-03-02T22:00:00Z"),
+		Entry("week days range skipping the weekend", "0 22 * * 1-5", "2023-03-03T23:00:00Z", "2023-03-06T22:00:00Z"),
+		Entry("sunday as 7", "30 1 * * 7", "2023-03-01T00:00:00Z", "2023-03-05T01:30:00Z"),
+		Entry("minute step", "*/15 * * * *", "2023-03-01T10:16:00Z", "2023-03-01T10:30:00Z"),
+		Entry("minute list", "5,50 * * * *", "2023-03-01T10:16:00Z", "2023-03-01T10:50:00Z"),
+		Entry("day of month and month", "0 0 1 6 *", "2023-03-01T10:00:00Z", "2023-06-01T00:00:00Z"),
+		Entry("day of month or day of week when both are restricted", "0 0 15 * 0", "2023-03-01T10:00:00Z", "2023-03-05T00:00:00Z"),
+	)
+	It("should return zero time for schedules that never match", func() {
+		schedule, err := ParseSchedule("0 0 30 2 *")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Next(parseTime("2023-03-01T10:00:00Z"))).To(BeZero())
+	})
+	DescribeTable("when parsing an invalid expression",
+		func(expression, expectedError string) {
+			_, err := ParseSchedule(expression)
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("missing fields", "0 22 * *", "expected 5 fields"),
+		Entry("value out of range", "0 24 * * *", "hour 24 out of range"),
+		Entry("not a number", "0 a * * *", `invalid hour "a"`),
+		Entry("inverted range", "0 0 * * 5-1", `invalid day of week range "5-1"`),
+		Entry("zero step", "*/0 * * * *", `invalid minute step "0"`),
+	)
+})
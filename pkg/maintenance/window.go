@@ -0,0 +1,76 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"time"
+
+	// Embed the time zone database so the handler image does not need it
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// Status tells if a maintenance window is open at a given time
+type Status struct {
+	Open bool
+	// Closes is when the open window closes
+	Closes time.Time
+	// NextStart is when the next window opens, when the window is open it
+	// is the start after the current one.
+	NextStart time.Time
+}
+
+// Evaluate calculates if the maintenance window is open at now and when the
+// next one starts.
+func Evaluate(window *nmstateapi.MaintenanceWindow, now time.Time) (Status, error) {
+	schedule, err := ParseSchedule(window.Schedule)
+	if err != nil {
+		return Status{}, errors.Wrap(err, "invalid schedule")
+	}
+	loc, err := Location(window)
+	if err != nil {
+		return Status{}, errors.Wrap(err, "invalid time zone")
+	}
+	now = now.In(loc)
+	status := Status{
+		NextStart: schedule.Next(now),
+	}
+	// The window is open if one of the starts since duration ago has not
+	// closed yet, the latest one closes later.
+	start := schedule.Next(now.Add(-window.Duration.Duration - time.Minute))
+	for ; !start.IsZero() && !start.After(now); start = schedule.Next(start) {
+		closes := start.Add(window.Duration.Duration)
+		if now.Before(closes) {
+			status.Open = true
+			status.Closes = closes
+		}
+	}
+	return status, nil
+}
+
+// Location returns the time zone the maintenance window schedule is
+// interpreted at.
+func Location(window *nmstateapi.MaintenanceWindow) (*time.Location, error) {
+	if window.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(window.TimeZone)
+}
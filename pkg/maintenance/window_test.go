@@ -0,0 +1,84 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Maintenance window", func() {
+	type evaluateCase struct {
+		window         nmstateapi.MaintenanceWindow
+		now            string
+		expectedStatus Status
+	}
+	weekNights := nmstateapi.MaintenanceWindow{
+		Schedule: "0 22 * * 1-5",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}
+	DescribeTable("when evaluating it",
+		func(c evaluateCase) {
+			status, err := Evaluate(&c.window, parseTime(c.now))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Open).To(Equal(c.expectedStatus.Open))
+			Expect(status.Closes.Equal(c.expectedStatus.Closes)).To(BeTrue(), "closes %s", status.Closes)
+			Expect(status.NextStart.Equal(c.expectedStatus.NextStart)).To(BeTrue(), "next start %s", status.NextStart)
+		},
+		Entry("before it opens, should be closed", evaluateCase{
+			window: weekNights,
+			now:    "2023-03-01T10:00:00Z",
+			expectedStatus: Status{
+				NextStart: parseTime("2023-03-01T22:00:00Z"),
+			},
+		}),
+		Entry("while it is open across midnight, should be open", evaluateCase{
+			window: weekNights,
+			now:    "2023-03-02T01:00:00Z",
+			expectedStatus: Status{
+				Open:      true,
+				Closes:    parseTime("2023-03-02T02:00:00Z"),
+				NextStart: parseTime("2023-03-02T22:00:00Z"),
+			},
+		}),
+		Entry("at the time it closes, should be closed", evaluateCase{
+			window: weekNights,
+			now:    "2023-03-02T02:00:00Z",
+			expectedStatus: Status{
+				NextStart: parseTime("2023-03-02T22:00:00Z"),
+			},
+		}),
+		Entry("with a time zone, should interpret the schedule at it", evaluateCase{
+			window: nmstateapi.MaintenanceWindow{
+				Schedule: "0 22 * * 1-5",
+				Duration: metav1.Duration{Duration: time.Hour},
+				TimeZone: "Europe/Madrid",
+			},
+			now: "2023-03-01T20:30:00Z",
+			expectedStatus: Status{
+				NextStart: parseTime("2023-03-01T21:00:00Z"),
+			},
+		}),
+	)
+})
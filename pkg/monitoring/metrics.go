@@ -23,6 +23,15 @@ import (
 	"k8s.io/utils/pointer"
 )
 
+const (
+	NmstatectlOperationApply    = "apply"
+	NmstatectlOperationCommit   = "commit"
+	NmstatectlOperationRollback = "rollback"
+
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+)
+
 var (
 	AppliedFeaturesOpts = prometheus.GaugeOpts{
 		Name: "kubernetes_nmstate_features_applied",
@@ -33,20 +42,217 @@ var (
 		AppliedFeaturesOpts,
 		[]string{"name"},
 	)
+
+	PolicyConditionOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_policy_condition",
+		Help: "Whether the NodeNetworkConfigurationPolicy condition is true labeled by the policy name and the condition type",
+	}
+
+	PolicyCondition = prometheus.NewGaugeVec(
+		PolicyConditionOpts,
+		[]string{"policy", "type"},
+	)
+
+	NmstatectlDurationOpts = prometheus.HistogramOpts{
+		Name:    "kubernetes_nmstate_nmstatectl_duration_seconds",
+		Help:    "Duration of the nmstate apply, commit and rollback operations labeled by the operation",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}
+
+	NmstatectlDuration = prometheus.NewHistogramVec(
+		NmstatectlDurationOpts,
+		[]string{"operation"},
+	)
+
+	ProbeDurationOpts = prometheus.HistogramOpts{
+		Name:    "kubernetes_nmstate_probe_duration_seconds",
+		Help:    "Duration of the probes run after network reconfiguration labeled by the probe kind",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}
+
+	ProbeDuration = prometheus.NewHistogramVec(
+		ProbeDurationOpts,
+		[]string{"kind"},
+	)
+
+	ProbeFailuresOpts = prometheus.CounterOpts{
+		Name: "kubernetes_nmstate_probe_failures_total",
+		Help: "Number of probes failed after network reconfiguration labeled by the probe kind",
+	}
+
+	ProbeFailures = prometheus.NewCounterVec(
+		ProbeFailuresOpts,
+		[]string{"kind"},
+	)
+
+	EnactmentOutcomesOpts = prometheus.CounterOpts{
+		Name: "kubernetes_nmstate_enactment_outcomes_total",
+		Help: "Number of finished NodeNetworkConfigurationEnactments labeled by the condition reason",
+	}
+
+	EnactmentOutcomes = prometheus.NewCounterVec(
+		EnactmentOutcomesOpts,
+		[]string{"reason"},
+	)
+
+	RollbacksOpts = prometheus.CounterOpts{
+		Name: "kubernetes_nmstate_rollbacks_total",
+		Help: "Number of desired states rolled back after failing the probes",
+	}
+
+	Rollbacks = prometheus.NewCounter(RollbacksOpts)
+
+	MaxUnavailablePendingDurationOpts = prometheus.HistogramOpts{
+		Name:    "kubernetes_nmstate_max_unavailable_pending_duration_seconds",
+		Help:    "Time the NodeNetworkConfigurationEnactments spend pending on the policy maxUnavailable limit",
+		Buckets: []float64{5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+	}
+
+	MaxUnavailablePendingDuration = prometheus.NewHistogram(MaxUnavailablePendingDurationOpts)
+
+	NNSRefreshDurationOpts = prometheus.HistogramOpts{
+		Name:    "kubernetes_nmstate_nns_refresh_duration_seconds",
+		Help:    "Duration of the NodeNetworkState refresh from retrieving the node network state to updating it",
+		Buckets: prometheus.DefBuckets,
+	}
+
+	NNSRefreshDuration = prometheus.NewHistogram(NNSRefreshDurationOpts)
+
+	InterfaceUpOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_interface_up",
+		Help: "Whether the node interface is up labeled by the node, the interface name and the interface type",
+	}
+
+	InterfaceUp = prometheus.NewGaugeVec(
+		InterfaceUpOpts,
+		[]string{"node", "interface", "type"},
+	)
+
+	InterfaceMTUOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_interface_mtu_bytes",
+		Help: "MTU of the node interface labeled by the node and the interface name",
+	}
+
+	InterfaceMTU = prometheus.NewGaugeVec(
+		InterfaceMTUOpts,
+		[]string{"node", "interface"},
+	)
+
+	InterfaceAddressesOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_interface_addresses",
+		Help: "Number of IP addresses of the node interface labeled by the node, the interface name and the IP family",
+	}
+
+	InterfaceAddresses = prometheus.NewGaugeVec(
+		InterfaceAddressesOpts,
+		[]string{"node", "interface", "family"},
+	)
+
+	BondPortsOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_bond_ports",
+		Help: "Number of ports of the node bond labeled by the node and the bond name",
+	}
+
+	BondPorts = prometheus.NewGaugeVec(
+		BondPortsOpts,
+		[]string{"node", "interface"},
+	)
+
+	DefaultRouteOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_default_route",
+		Help: "Whether the node has a default route labeled by the node and the IP family",
+	}
+
+	DefaultRoute = prometheus.NewGaugeVec(
+		DefaultRouteOpts,
+		[]string{"node", "family"},
+	)
+
+	DNSServersOpts = prometheus.GaugeOpts{
+		Name: "kubernetes_nmstate_dns_servers",
+		Help: "Number of DNS servers used by the node labeled by the node",
+	}
+
+	DNSServers = prometheus.NewGaugeVec(
+		DNSServersOpts,
+		[]string{"node"},
+	)
+
 	gaugeOpts = []prometheus.GaugeOpts{
 		AppliedFeaturesOpts,
+		PolicyConditionOpts,
+		InterfaceUpOpts,
+		InterfaceMTUOpts,
+		InterfaceAddressesOpts,
+		BondPortsOpts,
+		DefaultRouteOpts,
+		DNSServersOpts,
+	}
+	counterOpts = []prometheus.CounterOpts{
+		ProbeFailuresOpts,
+		EnactmentOutcomesOpts,
+		RollbacksOpts,
+	}
+	histogramOpts = []prometheus.HistogramOpts{
+		NmstatectlDurationOpts,
+		ProbeDurationOpts,
+		MaxUnavailablePendingDurationOpts,
+		NNSRefreshDurationOpts,
 	}
 )
 
+// Collectors returns all the metrics to register them at the metrics registry
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		AppliedFeatures,
+		PolicyCondition,
+		NmstatectlDuration,
+		ProbeDuration,
+		ProbeFailures,
+		EnactmentOutcomes,
+		Rollbacks,
+		MaxUnavailablePendingDuration,
+		NNSRefreshDuration,
+		InterfaceUp,
+		InterfaceMTU,
+		InterfaceAddresses,
+		BondPorts,
+		DefaultRoute,
+		DNSServers,
+	}
+}
+
+// InventoryGauges are the gauges reporting the network inventory of the
+// nodes, all of them are labeled by "node".
+func InventoryGauges() []*prometheus.GaugeVec {
+	return []*prometheus.GaugeVec{
+		InterfaceUp,
+		InterfaceMTU,
+		InterfaceAddresses,
+		BondPorts,
+		DefaultRoute,
+		DNSServers,
+	}
+}
+
 func Families() []pgo.MetricFamily {
 	metricFamilies := []pgo.MetricFamily{}
 	for _, gauge := range gaugeOpts {
-		metricTypeGauge := pgo.MetricType_GAUGE
-		metricFamilies = append(metricFamilies, pgo.MetricFamily{
-			Name: pointer.String(gauge.Name),
-			Help: pointer.String(gauge.Help),
-			Type: &metricTypeGauge,
-		})
+		metricFamilies = append(metricFamilies, family(gauge.Name, gauge.Help, pgo.MetricType_GAUGE))
+	}
+	for _, counter := range counterOpts {
+		metricFamilies = append(metricFamilies, family(counter.Name, counter.Help, pgo.MetricType_COUNTER))
+	}
+	for _, histogram := range histogramOpts {
+		metricFamilies = append(metricFamilies, family(histogram.Name, histogram.Help, pgo.MetricType_HISTOGRAM))
 	}
 	return metricFamilies
 }
+
+func family(name, help string, metricType pgo.MetricType) pgo.MetricFamily {
+	return pgo.MetricFamily{
+		Name: pointer.String(name),
+		Help: pointer.String(help),
+		Type: &metricType,
+	}
+}
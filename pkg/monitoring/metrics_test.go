@@ -0,0 +1,52 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitoring
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var fqNameRegexp = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+var _ = Describe("Metric families", func() {
+	It("should include every collector so the metric linter covers them", func() {
+		collectorNames := []string{}
+		descs := make(chan *prometheus.Desc, 100)
+		for _, collector := range Collectors() {
+			collector.Describe(descs)
+		}
+		close(descs)
+		for desc := range descs {
+			match := fqNameRegexp.FindStringSubmatch(desc.String())
+			Expect(match).ToNot(BeNil())
+			collectorNames = append(collectorNames, match[1])
+		}
+
+		familyNames := []string{}
+		families := Families()
+		for i := range families {
+			familyNames = append(familyNames, families[i].GetName())
+		}
+		Expect(familyNames).To(ConsistOf(collectorNames))
+	})
+})
@@ -0,0 +1,237 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nmpolicy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The capture expressions are parsed following the nmpolicy grammar:
+//
+//	expression := path | path operator value | path "|" path operator value
+//	operator   := "==" | "!=" | ":="
+//	value      := term ("+" term)*
+//	term       := string | path
+//	path       := identity ("." identity)*
+//
+// where the path at the left of a pipe has to be a capture reference.
+
+type tokenKind string
+
+const (
+	identityToken tokenKind = "identity"
+	stringToken   tokenKind = "string"
+	dotToken      tokenKind = "."
+	pipeToken     tokenKind = "|"
+	equalToken    tokenKind = "=="
+	notEqualToken tokenKind = "!="
+	replaceToken  tokenKind = ":="
+	mergeToken    tokenKind = "+"
+	eofToken      tokenKind = "end of expression"
+)
+
+var operatorTokens = []tokenKind{equalToken, notEqualToken, replaceToken}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func isIdentityChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func lex(expression string) ([]token, error) {
+	tokens := []token{}
+	for pos := 0; pos < len(expression); {
+		c := expression[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			pos++
+		case isIdentityChar(c):
+			start := pos
+			for pos < len(expression) && isIdentityChar(expression[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: identityToken, value: expression[start:pos], pos: start})
+		case c == '"':
+			start := pos
+			pos++
+			for pos < len(expression) && expression[pos] != '"' {
+				if expression[pos] == '\\' {
+					pos++
+				}
+				pos++
+			}
+			if pos >= len(expression) {
+				return nil, positionError(start, "unterminated string")
+			}
+			pos++
+			tokens = append(tokens, token{kind: stringToken, value: expression[start:pos], pos: start})
+		case c == '.' || c == '|' || c == '+':
+			tokens = append(tokens, token{kind: tokenKind(c), value: string(c), pos: pos})
+			pos++
+		case strings.HasPrefix(expression[pos:], string(equalToken)),
+			strings.HasPrefix(expression[pos:], string(notEqualToken)),
+			strings.HasPrefix(expression[pos:], string(replaceToken)):
+			tokens = append(tokens, token{kind: tokenKind(expression[pos : pos+2]), value: expression[pos : pos+2], pos: pos})
+			pos += 2
+		default:
+			return nil, positionError(pos, fmt.Sprintf("unexpected character %q", c))
+		}
+	}
+	return append(tokens, token{kind: eofToken, pos: len(expression)}), nil
+}
+
+type parser struct {
+	tokens  []token
+	current int
+}
+
+// parseExpression returns the captures referenced by expression
+func parseExpression(expression string) ([]string, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := parser{tokens: tokens}
+	references := []string{}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	references = appendReference(references, path)
+
+	if p.peek().kind == pipeToken {
+		if !isCaptureReference(path) {
+			return nil, positionError(p.peek().pos, "pipe input has to be a capture reference")
+		}
+		p.next()
+		path, err = p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		references = appendReference(references, path)
+		if !p.isOperator() {
+			return nil, p.unexpected("an operator")
+		}
+	}
+	if p.isOperator() {
+		p.next()
+		valueReferences, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, valueReferences...)
+	}
+	if err := p.expect(eofToken); err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
+func (p *parser) parseValue() ([]string, error) {
+	references := []string{}
+	for {
+		switch p.peek().kind {
+		case stringToken:
+			p.next()
+		case identityToken:
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			references = appendReference(references, path)
+		default:
+			return nil, p.unexpected("a string or a path")
+		}
+		if p.peek().kind != mergeToken {
+			return references, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parsePath() ([]string, error) {
+	path := []string{}
+	for {
+		if p.peek().kind != identityToken {
+			return nil, p.unexpected("a path")
+		}
+		path = append(path, p.next().value)
+		if p.peek().kind != dotToken {
+			return path, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) isOperator() bool {
+	for _, operator := range operatorTokens {
+		if p.peek().kind == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.current]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.current]
+	if p.current < len(p.tokens)-1 {
+		p.current++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	if p.peek().kind != kind {
+		return p.unexpected(string(kind))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	found := string(t.kind)
+	if t.kind != eofToken {
+		found = fmt.Sprintf("%q", t.value)
+	}
+	return positionError(t.pos, fmt.Sprintf("expected %s, found %s", expected, found))
+}
+
+func appendReference(references, path []string) []string {
+	if isCaptureReference(path) {
+		return append(references, path[1])
+	}
+	return references
+}
+
+// positionError reports pos starting at 1 so it matches the column of the
+// expression
+func positionError(pos int, msg string) error {
+	return fmt.Errorf("%s at position %d", msg, pos+1)
+}
@@ -0,0 +1,260 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nmpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nodetemplate"
+)
+
+const captureReferencePrefix = "capture"
+
+var desiredStateReferenceRegexp = regexp.MustCompile(`{{(.*?)}}`)
+
+// ValidateCapture checks the syntax of the capture expressions, that they
+// only reference defined captures without cycles, and that the
+// "{{ capture.name }}" references at the desired state point to defined
+// captures.
+func ValidateCapture(capture map[string]string, desiredState nmstateapi.State, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := []string{}
+	for name := range capture {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	references := map[string][]string{}
+	capturePath := fldPath.Child("capture")
+	for _, name := range names {
+		expressionReferences, err := parseExpression(capture[name])
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(capturePath.Key(name), capture[name], err.Error()))
+			continue
+		}
+		references[name] = expressionReferences
+		for _, reference := range expressionReferences {
+			if _, defined := capture[reference]; !defined {
+				allErrs = append(allErrs, field.Invalid(capturePath.Key(name), capture[name],
+					fmt.Sprintf("references undefined capture %q", reference)))
+			}
+		}
+	}
+	for _, name := range names {
+		if cycle := findCycle(name, references, []string{name}); cycle != nil {
+			allErrs = append(allErrs, field.Invalid(capturePath.Key(name), capture[name],
+				fmt.Sprintf("capture reference cycle: %s", strings.Join(cycle, " -> "))))
+		}
+	}
+	return append(allErrs, validateDesiredStateReferences(capture, desiredState, fldPath.Child("desiredState"))...)
+}
+
+func findCycle(name string, references map[string][]string, visited []string) []string {
+	for _, reference := range references[name] {
+		if reference == visited[0] {
+			return append(visited, reference)
+		}
+		if sets.New(visited...).Has(reference) {
+			// a cycle not including the first capture, it is reported from there
+			continue
+		}
+		if cycle := findCycle(reference, references, append(visited, reference)); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+func validateDesiredStateReferences(capture map[string]string, desiredState nmstateapi.State, fldPath *field.Path) field.ErrorList {
+	if len(desiredState.Raw) == 0 {
+		return nil
+	}
+	var state interface{}
+	if err := yaml.Unmarshal(desiredState.Raw, &state); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, "", fmt.Sprintf("failed decoding desired state: %v", err))}
+	}
+	allErrs := field.ErrorList{}
+	walkStrings(state, fldPath, func(value string, valuePath *field.Path) {
+		for _, match := range desiredStateReferenceRegexp.FindAllStringSubmatch(value, -1) {
+			reference := strings.TrimSpace(match[1])
+			if strings.HasPrefix(reference, ippool.ReferencePrefix+".") {
+				// validated against the NodeIPPools by the webhook
+				continue
+			}
+			if strings.HasPrefix(reference, nodetemplate.ReferencePrefix+".") {
+				// validated by the nodetemplate package
+				continue
+			}
+			path, err := parseReference(reference)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(valuePath, value, err.Error()))
+				continue
+			}
+			if _, defined := capture[path[1]]; !defined {
+				allErrs = append(allErrs, field.Invalid(valuePath, value, fmt.Sprintf("references undefined capture %q", path[1])))
+			}
+		}
+	})
+	return allErrs
+}
+
+// walkStrings calls fn with every string value at the state and its path
+func walkStrings(value interface{}, fldPath *field.Path, fn func(string, *field.Path)) {
+	switch v := value.(type) {
+	case string:
+		fn(v, fldPath)
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkStrings(v[key], fldPath.Child(key), fn)
+		}
+	case []interface{}:
+		for i := range v {
+			walkStrings(v[i], fldPath.Index(i), fn)
+		}
+	}
+}
+
+// parseReference parses a desired state reference like
+// "capture.default-gw.routes.running.0.next-hop-interface"
+func parseReference(reference string) ([]string, error) {
+	tokens, err := lex(reference)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(eofToken); err != nil {
+		return nil, err
+	}
+	if !isCaptureReference(path) {
+		return nil, fmt.Errorf("invalid reference %q: only capture references like capture.name.path are supported", reference)
+	}
+	return path, nil
+}
+
+func isCaptureReference(path []string) bool {
+	return len(path) >= 2 && path[0] == captureReferencePrefix
+}
+
+// OmitCaptureReferences returns the desired state without the values
+// referencing captures together with their paths, so the rest of the desired
+// state can be validated before the captures are resolved at the nodes. List
+// items of objects are replaced by an empty object to keep the indexes of
+// their siblings.
+func OmitCaptureReferences(desiredState nmstateapi.State, fldPath *field.Path) (nmstateapi.State, []*field.Path, error) {
+	if len(desiredState.Raw) == 0 {
+		return desiredState, nil, nil
+	}
+	var state interface{}
+	if err := yaml.Unmarshal(desiredState.Raw, &state); err != nil {
+		return desiredState, nil, fmt.Errorf("failed decoding desired state: %w", err)
+	}
+	omitted := []*field.Path{}
+	state = omitCaptureReferences(state, fldPath, &omitted)
+	raw, err := yaml.Marshal(state)
+	if err != nil {
+		return desiredState, nil, fmt.Errorf("failed encoding desired state: %w", err)
+	}
+	return nmstateapi.NewState(string(raw)), omitted, nil
+}
+
+func omitCaptureReferences(value interface{}, fldPath *field.Path, omitted *[]*field.Path) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key := range v {
+			if isCaptureValue(v[key]) {
+				*omitted = append(*omitted, fldPath.Child(key))
+				delete(v, key)
+				continue
+			}
+			v[key] = omitCaptureReferences(v[key], fldPath.Child(key), omitted)
+		}
+	case []interface{}:
+		hasObjects := false
+		for i := range v {
+			if _, isObject := v[i].(map[string]interface{}); isObject {
+				hasObjects = true
+			}
+		}
+		for i := range v {
+			if isCaptureValue(v[i]) {
+				*omitted = append(*omitted, fldPath.Index(i))
+				if hasObjects {
+					v[i] = map[string]interface{}{}
+				}
+				continue
+			}
+			v[i] = omitCaptureReferences(v[i], fldPath.Index(i), omitted)
+		}
+	}
+	return value
+}
+
+// isCaptureValue returns true for the strings with references that are not
+// node templates nor NodeIPPool references
+func isCaptureValue(value interface{}) bool {
+	s, isString := value.(string)
+	if !isString {
+		return false
+	}
+	for _, match := range desiredStateReferenceRegexp.FindAllStringSubmatch(s, -1) {
+		reference := strings.TrimSpace(match[1])
+		if !strings.HasPrefix(reference, ippool.ReferencePrefix+".") && !strings.HasPrefix(reference, nodetemplate.ReferencePrefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterOmitted drops the errors at the omitted paths or below them.
+func FilterOmitted(allErrs field.ErrorList, omitted []*field.Path) field.ErrorList {
+	filtered := field.ErrorList{}
+	for _, err := range allErrs {
+		if !isOmitted(err.Field, omitted) {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
+
+func isOmitted(fieldPath string, omitted []*field.Path) bool {
+	for _, path := range omitted {
+		p := path.String()
+		if fieldPath == p || strings.HasPrefix(fieldPath, p+".") || strings.HasPrefix(fieldPath, p+"[") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nmpolicy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("ValidateCapture", func() {
+	DescribeTable("should validate capture expressions and references",
+		func(capture map[string]string, desiredState string, expectedErrors []string) {
+			errs := []string{}
+			for _, err := range ValidateCapture(capture, nmstateapi.NewState(desiredState), field.NewPath("spec")) {
+				errs = append(errs, err.Error())
+			}
+			Expect(errs).To(ConsistOf(expectedErrors))
+		},
+		Entry("with valid expressions and references",
+			map[string]string{
+				"default-gw":        `routes.running.destination=="0.0.0.0/0"`,
+				"base-iface-routes": `routes.running.next-hop-interface==capture.default-gw.routes.running.0.next-hop-interface`,
+				"base-iface":        `interfaces.name == capture.default-gw.routes.running.0.next-hop-interface`,
+				"bridge-routes":     `capture.base-iface-routes | routes.running.next-hop-interface:="br1"`,
+				"not-loopback":      `interfaces.type!="loopback"`,
+				"bridge-name":       `interfaces.name:="br-" + capture.base-iface.interfaces.0.name`,
+			}, `
+interfaces:
+- name: br1
+  type: linux-bridge
+  ipv4: "{{ capture.base-iface.interfaces.0.ipv4 }}"
+  bridge:
+    port:
+    - name: "{{capture.base-iface.interfaces.0.name}}"
+routes:
+  config: "{{ capture.bridge-routes.routes.running }}"
+`, []string{}),
+		Entry("with ip pool references",
+			map[string]string{}, `
+interfaces:
+- name: eth1
+  ipv4:
+    address:
+    - "{{ ippool.storage }}"
+`, []string{}),
+		Entry("with node templates",
+			map[string]string{}, `
+interfaces:
+- name: eth1
+  description: "{{ node.labels['rack'] }}"
+`, []string{}),
+		Entry("with syntax errors",
+			map[string]string{
+				"missing-value":    `interfaces.name==`,
+				"bad-operator":     `interfaces.name="eth1"`,
+				"bad-pipe":         `interfaces | interfaces.name:="br1"`,
+				"pipe-no-operator": `capture.missing-value | interfaces.name`,
+				"unterminated":     `interfaces.name=="eth1`,
+				"trailing-dot":     `interfaces.`,
+				"extra-tokens":     `interfaces.name=="eth1" "eth2"`,
+				"empty":            ` `,
+			}, "", []string{
+				`spec.capture[bad-operator]: Invalid value: "interfaces.name=\"eth1\"": unexpected character '=' at position 16`,
+				`spec.capture[bad-pipe]: Invalid value: "interfaces | interfaces.name:=\"br1\"": ` +
+					`pipe input has to be a capture reference at position 12`,
+				`spec.capture[empty]: Invalid value: " ": empty expression`,
+				`spec.capture[extra-tokens]: Invalid value: "interfaces.name==\"eth1\" \"eth2\"": ` +
+					`expected end of expression, found "\"eth2\"" at position 25`,
+				`spec.capture[missing-value]: Invalid value: "interfaces.name==": expected a string or a path, found end of expression at position 18`,
+				`spec.capture[pipe-no-operator]: Invalid value: "capture.missing-value | interfaces.name": ` +
+					`expected an operator, found end of expression at position 40`,
+				`spec.capture[trailing-dot]: Invalid value: "interfaces.": expected a path, found end of expression at position 12`,
+				`spec.capture[unterminated]: Invalid value: "interfaces.name==\"eth1": unterminated string at position 18`,
+			}),
+		Entry("with references to undefined captures",
+			map[string]string{
+				"base-iface":    `interfaces.name==capture.default-gw.routes.running.0.next-hop-interface`,
+				"bridge-routes": `capture.base-iface-routes | routes.running.next-hop-interface:="br1"`,
+			}, `
+interfaces:
+- name: br1
+  ipv4: "{{ capture.base-iface.interfaces.0.ipv4 }}"
+  bridge:
+    port:
+    - name: "{{ capture.primary-nic.interfaces.0.name }}"
+    - name: "{{ interfaces.0.name }}"
+`, []string{
+				`spec.capture[base-iface]: Invalid value: "interfaces.name==capture.default-gw.routes.running.0.next-hop-interface": ` +
+					`references undefined capture "default-gw"`,
+				`spec.capture[bridge-routes]: Invalid value: "capture.base-iface-routes | routes.running.next-hop-interface:=\"br1\"": ` +
+					`references undefined capture "base-iface-routes"`,
+				`spec.desiredState.interfaces[0].bridge.port[0].name: Invalid value: "{{ capture.primary-nic.interfaces.0.name }}": ` +
+					`references undefined capture "primary-nic"`,
+				`spec.desiredState.interfaces[0].bridge.port[1].name: Invalid value: "{{ interfaces.0.name }}": ` +
+					`invalid reference "interfaces.0.name": only capture references like capture.name.path are supported`,
+			}),
+		Entry("with reference cycles",
+			map[string]string{
+				"self": `interfaces.name==capture.self.interfaces.0.name`,
+				"a":    `interfaces.name==capture.b.interfaces.0.name`,
+				"b":    `capture.a | interfaces.name:="br1"`,
+			}, "", []string{
+				`spec.capture[a]: Invalid value: "interfaces.name==capture.b.interfaces.0.name": capture reference cycle: a -> b -> a`,
+				`spec.capture[b]: Invalid value: "capture.a | interfaces.name:=\"br1\"": capture reference cycle: b -> a -> b`,
+				`spec.capture[self]: Invalid value: "interfaces.name==capture.self.interfaces.0.name": capture reference cycle: self -> self`,
+			}),
+	)
+})
+
+var _ = Describe("OmitCaptureReferences", func() {
+	It("should omit the values referencing captures and keep the rest", func() {
+		desiredState, omitted, err := OmitCaptureReferences(nmstateapi.NewState(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  ipv4: "{{ capture.base-iface.interfaces.0.ipv4 }}"
+  bridge:
+    port:
+    - name: eth2
+    - "{{ capture.base-iface.interfaces.0.bridge.port.0 }}"
+- name: eth1
+  description: "{{ node.labels['rack'] }}"
+routes:
+  config: "{{ capture.bridge-routes.routes.running }}"
+`), field.NewPath("spec", "desiredState"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desiredState.String()).To(MatchYAML(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: eth2
+    - {}
+- name: eth1
+  description: "{{ node.labels['rack'] }}"
+routes: {}
+`))
+		paths := []string{}
+		for _, path := range omitted {
+			paths = append(paths, path.String())
+		}
+		Expect(paths).To(ConsistOf(
+			"spec.desiredState.interfaces[0].ipv4",
+			"spec.desiredState.interfaces[0].bridge.port[1]",
+			"spec.desiredState.routes.config",
+		))
+	})
+
+	It("should filter the errors at or below the omitted paths", func() {
+		fldPath := field.NewPath("spec", "desiredState", "interfaces").Index(0)
+		allErrs := field.ErrorList{
+			field.Required(fldPath.Child("name"), ""),
+			field.Required(fldPath.Child("vlan", "base-iface"), ""),
+			field.Invalid(fldPath.Child("vlan", "id"), 4095, "must be between 0 and 4094"),
+			field.Invalid(fldPath.Child("names"), "names", "unknown field"),
+		}
+		Expect(FilterOmitted(allErrs, []*field.Path{fldPath.Child("name"), fldPath.Child("vlan", "base-iface")})).To(Equal(field.ErrorList{
+			allErrs[2], allErrs[3],
+		}))
+	})
+})
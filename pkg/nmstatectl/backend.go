@@ -0,0 +1,145 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nmstatectl
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// CommandBackendName is the default backend, it runs the nmstatectl binary
+const CommandBackendName = "nmstatectl"
+
+// Backend runs the nmstate operations needed by kubernetes-nmstate, the
+// package functions delegate on the backend in use so it can be replaced
+// without changing the callers.
+type Backend interface {
+	// Show returns the current network state
+	Show() (string, error)
+	// Set applies the desired state without committing it, it is rolled
+	// back automatically if it is not committed before timeout.
+	Set(desiredState nmstate.State, timeout time.Duration) (string, error)
+	// Commit makes the last applied desired state persistent
+	Commit() (string, error)
+	// Rollback reverts the last not committed desired state
+	Rollback() error
+	// Statistic returns the nmstate features used by the desired state
+	Statistic(desiredState nmstate.State) (*Stats, error)
+	// Policy generates the desired state from a nmpolicy, the current state
+	// and the states captured by a previous call.
+	Policy(policy, currentState, capturedState []byte) (desiredState, generatedCapturedState []byte, err error)
+}
+
+var (
+	backendsLock sync.RWMutex
+	backends     = map[string]func() (Backend, error){
+		CommandBackendName: func() (Backend, error) { return &commandBackend{}, nil },
+	}
+	backend Backend = &commandBackend{}
+)
+
+// Register makes a backend available by name to UseBackend
+func Register(name string, factory func() (Backend, error)) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+	backends[name] = factory
+}
+
+// Backends returns the names of the registered backends
+func Backends() []string {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+	names := []string{}
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseBackend creates the registered backend with name and uses it for the
+// next calls.
+func UseBackend(name string) error {
+	backendsLock.RLock()
+	factory, ok := backends[name]
+	backendsLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown nmstate backend %q, registered backends are %v", name, Backends())
+	}
+	b, err := factory()
+	if err != nil {
+		return fmt.Errorf("failed creating nmstate backend %q: %w", name, err)
+	}
+	SetBackend(b)
+	return nil
+}
+
+// SetBackend replaces the backend in use, it returns the previous one so
+// tests can restore it.
+func SetBackend(b Backend) Backend {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+	previous := backend
+	backend = b
+	return previous
+}
+
+func currentBackend() Backend {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+	return backend
+}
+
+// Error is returned by the backends when nmstate fails, Kind is the nmstate
+// error kind, like "InvalidArgument" or "VerificationError", if it is known.
+type Error struct {
+	Kind    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func Show() (string, error) {
+	return currentBackend().Show()
+}
+
+func Set(desiredState nmstate.State, timeout time.Duration) (string, error) {
+	return currentBackend().Set(desiredState, timeout)
+}
+
+func Commit() (string, error) {
+	return currentBackend().Commit()
+}
+
+func Rollback() error {
+	return currentBackend().Rollback()
+}
+
+func Statistic(desiredState nmstate.State) (*Stats, error) {
+	return currentBackend().Statistic(desiredState)
+}
+
+func Policy(policy, currentState, capturedState []byte) (desiredState, generatedCapturedState []byte, err error) {
+	return currentBackend().Policy(policy, currentState, capturedState)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nmstatectl
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+type stubBackend struct {
+	commandBackend
+	shown bool
+}
+
+func (b *stubBackend) Show() (string, error) {
+	b.shown = true
+	return "interfaces: []\n", nil
+}
+
+func (b *stubBackend) Set(nmstate.State, time.Duration) (string, error) {
+	return "", &Error{Kind: "VerificationError", Message: "failed verifying"}
+}
+
+var _ = Describe("nmstate backend", func() {
+	var stub *stubBackend
+	BeforeEach(func() {
+		stub = &stubBackend{}
+		previous := SetBackend(stub)
+		DeferCleanup(func() { SetBackend(previous) })
+	})
+	It("should delegate the package functions on the backend in use", func() {
+		output, err := Show()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal("interfaces: []\n"))
+		Expect(stub.shown).To(BeTrue())
+	})
+	It("should return the backend typed errors", func() {
+		_, err := Set(nmstate.NewState("{}"), time.Second)
+		nmstateErr := &Error{}
+		Expect(errors.As(err, &nmstateErr)).To(BeTrue())
+		Expect(nmstateErr.Kind).To(Equal("VerificationError"))
+	})
+	It("should use the registered backends", func() {
+		registered := &stubBackend{}
+		Register("stub", func() (Backend, error) { return registered, nil })
+		DeferCleanup(func() {
+			backendsLock.Lock()
+			defer backendsLock.Unlock()
+			delete(backends, "stub")
+		})
+		Expect(Backends()).To(ContainElements(CommandBackendName, "stub"))
+		Expect(UseBackend("stub")).To(Succeed())
+		Expect(currentBackend()).To(BeIdenticalTo(registered))
+	})
+	It("should fail using a backend not registered", func() {
+		Expect(UseBackend("varlink")).To(MatchError(ContainSubstring(`unknown nmstate backend "varlink"`)))
+		Expect(currentBackend()).To(BeIdenticalTo(stub))
+	})
+	It("should fail when the backend cannot be created", func() {
+		Register("broken", func() (Backend, error) { return nil, errors.New("missing libnmstate") })
+		DeferCleanup(func() {
+			backendsLock.Lock()
+			defer backendsLock.Unlock()
+			delete(backends, "broken")
+		})
+		Expect(UseBackend("broken")).To(MatchError(ContainSubstring("missing libnmstate")))
+		Expect(currentBackend()).To(BeIdenticalTo(stub))
+	})
+})
+
+var _ = DescribeTable("errorKind",
+	func(stderr, expectedKind string) {
+		Expect(errorKind(stderr)).To(Equal(expectedKind))
+	},
+	Entry("with a nmstate error", "[2023-03-01T10:00:00Z ERROR nmstatectl] NmstateError: InvalidArgument: Invalid MTU", "InvalidArgument"),
+	Entry("with a verification error", "NmstateError: VerificationError: Verification failure: eth1.mtu desire '1400'", "VerificationError"),
+	Entry("without a nmstate error", "command not found", ""),
+)
@@ -0,0 +1,233 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides an in memory nmstatectl.Backend to exercise the
+// code that applies network state without a real nmstate at the node.
+package fake
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+// Backend keeps the network state in memory, Set merges the interfaces by
+// name removing the ones with "state: absent" and replaces the rest of the
+// top level sections.
+type Backend struct {
+	lock       sync.Mutex
+	current    map[string]interface{}
+	checkpoint map[string]interface{}
+	applied    []nmstate.State
+
+	// The errors returned by the different operations, nil means success
+	ShowErr      error
+	SetErr       error
+	CommitErr    error
+	RollbackErr  error
+	StatisticErr error
+	PolicyErr    error
+
+	// Features returned by Statistic
+	Features []string
+	// PolicyFn generates the Policy output, Policy fails if it is not set
+	PolicyFn func(policy, currentState, capturedState []byte) ([]byte, []byte, error)
+}
+
+var _ nmstatectl.Backend = &Backend{}
+
+// New returns a fake backend with currentState as the initial state
+func New(currentState nmstate.State) (*Backend, error) {
+	current := map[string]interface{}{}
+	if err := yaml.Unmarshal(currentState.Raw, &current); err != nil {
+		return nil, err
+	}
+	return &Backend{current: current}, nil
+}
+
+// CurrentState returns the state as Show would do
+func (b *Backend) CurrentState() nmstate.State {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.currentState()
+}
+
+// Applied returns the desired states passed to Set
+func (b *Backend) Applied() []nmstate.State {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]nmstate.State{}, b.applied...)
+}
+
+// Pending returns true if there is a desired state not committed or rolled back
+func (b *Backend) Pending() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.checkpoint != nil
+}
+
+func (b *Backend) currentState() nmstate.State {
+	raw, err := yaml.Marshal(b.current)
+	if err != nil {
+		panic(err)
+	}
+	return nmstate.NewState(string(raw))
+}
+
+func (b *Backend) Show() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.ShowErr != nil {
+		return "", b.ShowErr
+	}
+	return string(b.currentState().Raw), nil
+}
+
+func (b *Backend) Set(desiredState nmstate.State, _ time.Duration) (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.applied = append(b.applied, desiredState)
+	if b.SetErr != nil {
+		return "", b.SetErr
+	}
+	desired := map[string]interface{}{}
+	if err := yaml.Unmarshal(desiredState.Raw, &desired); err != nil {
+		return "", &nmstatectl.Error{Kind: "InvalidArgument", Message: err.Error()}
+	}
+	if b.checkpoint == nil {
+		b.checkpoint = b.current
+	}
+	b.current = merge(b.current, desired)
+	return "", nil
+}
+
+func (b *Backend) Commit() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.CommitErr != nil {
+		return "", b.CommitErr
+	}
+	b.checkpoint = nil
+	return "", nil
+}
+
+func (b *Backend) Rollback() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.RollbackErr != nil {
+		return b.RollbackErr
+	}
+	if b.checkpoint == nil {
+		return &nmstatectl.Error{Kind: "NotFoundError", Message: "no checkpoint to roll back"}
+	}
+	b.current = b.checkpoint
+	b.checkpoint = nil
+	return nil
+}
+
+func (b *Backend) Statistic(_ nmstate.State) (*nmstatectl.Stats, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.StatisticErr != nil {
+		return nil, b.StatisticErr
+	}
+	return nmstatectl.NewStats(b.Features), nil
+}
+
+func (b *Backend) Policy(policy, currentState, capturedState []byte) (desiredState, generatedCapturedState []byte, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.PolicyErr != nil {
+		return nil, nil, b.PolicyErr
+	}
+	if b.PolicyFn == nil {
+		return nil, nil, errors.New("fake backend has no PolicyFn")
+	}
+	return b.PolicyFn(policy, currentState, capturedState)
+}
+
+// merge returns a new state without modifying current so it can be kept as
+// the checkpoint.
+func merge(current, desired map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range desired {
+		if k == "interfaces" {
+			merged[k] = mergeInterfaces(current[k], v)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+func mergeInterfaces(current, desired interface{}) []interface{} {
+	currentIfaces, _ := current.([]interface{})
+	desiredIfaces, _ := desired.([]interface{})
+	merged := []interface{}{}
+	desiredByName := map[string]map[string]interface{}{}
+	for _, d := range desiredIfaces {
+		if iface, ok := d.(map[string]interface{}); ok {
+			desiredByName[interfaceName(iface)] = iface
+		}
+	}
+	seen := map[string]bool{}
+	for _, c := range currentIfaces {
+		iface, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := interfaceName(iface)
+		seen[name] = true
+		d, found := desiredByName[name]
+		if !found {
+			merged = append(merged, iface)
+			continue
+		}
+		if d["state"] == "absent" {
+			continue
+		}
+		updated := map[string]interface{}{}
+		for k, v := range iface {
+			updated[k] = v
+		}
+		for k, v := range d {
+			updated[k] = v
+		}
+		merged = append(merged, updated)
+	}
+	for _, d := range desiredIfaces {
+		iface, ok := d.(map[string]interface{})
+		if !ok || seen[interfaceName(iface)] || iface["state"] == "absent" {
+			continue
+		}
+		merged = append(merged, iface)
+	}
+	return merged
+}
+
+func interfaceName(iface map[string]interface{}) string {
+	name, _ := iface["name"].(string)
+	return name
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+var _ = Describe("Fake nmstate backend", func() {
+	var backend *Backend
+	BeforeEach(func() {
+		var err error
+		backend, err = New(nmstate.NewState(`
+dns-resolver:
+  running:
+    server: [8.8.8.8]
+interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should merge the desired state interfaces by name", func() {
+		_, err := backend.Set(nmstate.NewState(`
+interfaces:
+- name: eth1
+  mtu: 9000
+- name: eth0
+  state: absent
+- name: br1
+  type: linux-bridge
+  state: up
+`), time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend.CurrentState()).To(MatchYAML(nmstate.NewState(`
+dns-resolver:
+  running:
+    server: [8.8.8.8]
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+- name: br1
+  type: linux-bridge
+  state: up
+`)))
+		Expect(backend.Applied()).To(HaveLen(1))
+		Expect(backend.Pending()).To(BeTrue())
+	})
+	It("should roll back to the state before the first not committed Set", func() {
+		initial := backend.CurrentState()
+		_, err := backend.Set(nmstate.NewState("interfaces: [{name: eth0, state: absent}]"), time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = backend.Set(nmstate.NewState("interfaces: [{name: eth1, state: absent}]"), time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend.Rollback()).To(Succeed())
+		Expect(backend.CurrentState()).To(MatchYAML(initial))
+		Expect(backend.Pending()).To(BeFalse())
+	})
+	It("should keep the state after commit", func() {
+		_, err := backend.Set(nmstate.NewState("interfaces: [{name: eth0, state: absent}]"), time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = backend.Commit()
+		Expect(err).ToNot(HaveOccurred())
+		nmstateErr := &nmstatectl.Error{}
+		Expect(errors.As(backend.Rollback(), &nmstateErr)).To(BeTrue())
+		Expect(nmstateErr.Kind).To(Equal("NotFoundError"))
+		output, err := backend.Show()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).ToNot(ContainSubstring("eth0"))
+	})
+	It("should return the injected errors", func() {
+		backend.SetErr = &nmstatectl.Error{Kind: "VerificationError", Message: "failed verifying"}
+		initial := backend.CurrentState()
+		_, err := backend.Set(nmstate.NewState("interfaces: [{name: eth0, state: absent}]"), time.Second)
+		Expect(err).To(MatchError("failed verifying"))
+		Expect(backend.CurrentState()).To(MatchYAML(initial))
+		Expect(backend.Applied()).To(HaveLen(1))
+	})
+	It("should be usable as the nmstatectl backend", func() {
+		previous := nmstatectl.SetBackend(backend)
+		DeferCleanup(func() { nmstatectl.SetBackend(previous) })
+		backend.Features = []string{"mtu"}
+		stats, err := nmstatectl.Statistic(nmstate.NewState("{}"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Features).To(Equal(map[string]bool{"mtu": true}))
+	})
+})
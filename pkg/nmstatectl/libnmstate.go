@@ -0,0 +1,118 @@
+//go:build libnmstate
+
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nmstatectl
+
+/*
+#cgo pkg-config: nmstate
+#include <stdlib.h>
+#include <nmstate.h>
+*/
+import "C"
+
+import (
+	"time"
+	"unsafe"
+
+	"sigs.k8s.io/yaml"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// LibnmstateBackendName calls nmstate through the libnmstate C API in
+// process, it is only available at binaries built with the libnmstate tag.
+const LibnmstateBackendName = "libnmstate"
+
+func init() {
+	Register(LibnmstateBackendName, func() (Backend, error) { return &libnmstateBackend{}, nil })
+}
+
+// libnmstateBackend avoids forking nmstatectl for show, apply, commit and
+// rollback, the policy and statistic operations are not part of the C API so
+// they still run the nmstatectl binary.
+type libnmstateBackend struct {
+	commandBackend
+}
+
+type libnmstateResult struct {
+	log     *C.char
+	errKind *C.char
+	errMsg  *C.char
+}
+
+func (r *libnmstateResult) free() {
+	C.nmstate_cstring_free(r.log)
+	C.nmstate_cstring_free(r.errKind)
+	C.nmstate_cstring_free(r.errMsg)
+}
+
+func (r *libnmstateResult) err(rc C.int) error {
+	if rc == C.NMSTATE_PASS {
+		return nil
+	}
+	return &Error{
+		Kind:    C.GoString(r.errKind),
+		Message: C.GoString(r.errKind) + ": " + C.GoString(r.errMsg),
+	}
+}
+
+func (libnmstateBackend) Show() (string, error) {
+	var state *C.char
+	result := libnmstateResult{}
+	defer result.free()
+	rc := C.nmstate_net_state_retrieve(C.NMSTATE_FLAG_NONE, &state, &result.log, &result.errKind, &result.errMsg)
+	defer C.nmstate_cstring_free(state)
+	if err := result.err(rc); err != nil {
+		return "", err
+	}
+	// keep the nmstatectl show output format
+	output, err := yaml.JSONToYAML([]byte(C.GoString(state)))
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (libnmstateBackend) Set(desiredState nmstate.State, timeout time.Duration) (string, error) {
+	desiredStateJSON, err := yaml.YAMLToJSON(desiredState.Raw)
+	if err != nil {
+		return "", &Error{Kind: "InvalidArgument", Message: err.Error()}
+	}
+	state := C.CString(string(desiredStateJSON))
+	defer C.free(unsafe.Pointer(state))
+	result := libnmstateResult{}
+	defer result.free()
+	rc := C.nmstate_net_state_apply(C.NMSTATE_FLAG_NO_COMMIT, state, C.uint32_t(timeout.Seconds()), &result.log, &result.errKind, &result.errMsg)
+	return C.GoString(result.log), result.err(rc)
+}
+
+func (libnmstateBackend) Commit() (string, error) {
+	result := libnmstateResult{}
+	defer result.free()
+	// a nil checkpoint commits the last one
+	rc := C.nmstate_checkpoint_commit(nil, &result.log, &result.errKind, &result.errMsg)
+	return C.GoString(result.log), result.err(rc)
+}
+
+func (libnmstateBackend) Rollback() error {
+	result := libnmstateResult{}
+	defer result.free()
+	rc := C.nmstate_checkpoint_rollback(nil, &result.log, &result.errKind, &result.errMsg)
+	return result.err(rc)
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -35,6 +36,20 @@ import (
 
 const nmstateCommand = "nmstatectl"
 
+// nmstatectl 2.x reports failures at stderr as "NmstateError: <Kind>: <msg>"
+var errorKindRegexp = regexp.MustCompile(`NmstateError: (\w+):`)
+
+// commandBackend runs nmstatectl for every operation
+type commandBackend struct{}
+
+func errorKind(stderr string) string {
+	match := errorKindRegexp.FindStringSubmatch(stderr)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
 func nmstatectlWithInput(arguments []string, input string) (string, error) {
 	cmd := exec.Command(nmstateCommand, arguments...)
 	var stdout, stderr bytes.Buffer
@@ -54,14 +69,17 @@ func nmstatectlWithInput(arguments []string, input string) (string, error) {
 		}()
 	}
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf(
-			"failed to execute %s %s: '%v' '%s' '%s'",
-			nmstateCommand,
-			strings.Join(arguments, " "),
-			err,
-			stdout.String(),
-			stderr.String(),
-		)
+		return "", &Error{
+			Kind: errorKind(stderr.String()),
+			Message: fmt.Sprintf(
+				"failed to execute %s %s: '%v' '%s' '%s'",
+				nmstateCommand,
+				strings.Join(arguments, " "),
+				err,
+				stdout.String(),
+				stderr.String(),
+			),
+		}
 	}
 	return stdout.String(), nil
 }
@@ -70,11 +88,11 @@ func nmstatectl(arguments []string) (string, error) {
 	return nmstatectlWithInput(arguments, "")
 }
 
-func Show() (string, error) {
+func (commandBackend) Show() (string, error) {
 	return nmstatectl([]string{"show"})
 }
 
-func Set(desiredState nmstate.State, timeout time.Duration) (string, error) {
+func (commandBackend) Set(desiredState nmstate.State, timeout time.Duration) (string, error) {
 	var setDoneCh = make(chan struct{})
 	defer close(setDoneCh)
 
@@ -85,11 +103,11 @@ func Set(desiredState nmstate.State, timeout time.Duration) (string, error) {
 	return setOutput, err
 }
 
-func Commit() (string, error) {
+func (commandBackend) Commit() (string, error) {
 	return nmstatectl([]string{"commit"})
 }
 
-func Rollback() error {
+func (commandBackend) Rollback() error {
 	_, err := nmstatectl([]string{"rollback"})
 	if err != nil {
 		return errors.Wrapf(err, "failed calling nmstatectl rollback")
@@ -125,7 +143,7 @@ func (s *Stats) Subtract(statsToSubstract *Stats) Stats {
 	return result
 }
 
-func Statistic(desiredState nmstate.State) (*Stats, error) {
+func (commandBackend) Statistic(desiredState nmstate.State) (*Stats, error) {
 	statsOutput, err := nmstatectlWithInput(
 		[]string{"st", "-"},
 		string(desiredState.Raw),
@@ -143,7 +161,7 @@ func Statistic(desiredState nmstate.State) (*Stats, error) {
 	return NewStats(stats.Features), nil
 }
 
-func Policy(policy, currentState, capturedState []byte) (desiredState, generatedCapturedState []byte, err error) {
+func (commandBackend) Policy(policy, currentState, capturedState []byte) (desiredState, generatedCapturedState []byte, err error) {
 	policyFile, err := generateFileWithContent("policy", policy)
 	if err != nil {
 		return nil, nil, err
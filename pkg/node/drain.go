@@ -0,0 +1,146 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kubectl/pkg/drain"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const DefaultDrainTimeout = 10 * time.Minute
+
+var drainLog = logf.Log.WithName("node/drain")
+
+// Cordon marks the node as unschedulable recording the policy that did it
+// at the node, it returns true if the policy cordoned the node, now or
+// before a handler restart, so the caller knows it has to uncordon it
+// afterwards. Nodes cordoned by someone else are kept as they are.
+func Cordon(cli client.Client, nodeName, policy string, generation int64) (bool, error) {
+	cordoned := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node := corev1.Node{}
+		err := cli.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node)
+		if err != nil {
+			return err
+		}
+		cordoned = !node.Spec.Unschedulable || cordonedBy(&node) == policy
+		if !cordoned {
+			return nil
+		}
+		owner := fmt.Sprintf("%s/%d", policy, generation)
+		if node.Spec.Unschedulable && node.Annotations[shared.NodeCordonedByPolicyAnnotation] == owner {
+			return nil
+		}
+		node.Spec.Unschedulable = true
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[shared.NodeCordonedByPolicyAnnotation] = owner
+		return cli.Update(context.TODO(), &node)
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed cordoning node %s", nodeName)
+	}
+	return cordoned, nil
+}
+
+// Uncordon marks the node as schedulable if the policy cordoned it
+func Uncordon(cli client.Client, nodeName, policy string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node := corev1.Node{}
+		err := cli.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node)
+		if err != nil {
+			return err
+		}
+		if cordonedBy(&node) != policy {
+			return nil
+		}
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, shared.NodeCordonedByPolicyAnnotation)
+		return cli.Update(context.TODO(), &node)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed uncordoning node %s", nodeName)
+	}
+	return nil
+}
+
+// cordonedBy returns the policy that cordoned the node, if any
+func cordonedBy(node *corev1.Node) string {
+	owner, found := node.Annotations[shared.NodeCordonedByPolicyAnnotation]
+	if !found {
+		return ""
+	}
+	policy, _, _ := strings.Cut(owner, "/")
+	return policy
+}
+
+// Drain evicts the pods running at the node using the eviction API, so
+// PodDisruptionBudgets are respected. Daemon set and mirror pods are kept,
+// and pods not managed by a controller make it fail since they would not be
+// recreated. onProgress is called with the number of evicted pods.
+func Drain(kubeClient kubernetes.Interface, nodeName string, timeout time.Duration, onProgress func(evicted, total int)) error {
+	evicted := 0
+	drainer := drain.Helper{
+		Ctx:                 context.TODO(),
+		Client:              kubeClient,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  true,
+		Timeout:             timeout,
+		Out:                 io.Discard,
+		ErrOut:              io.Discard,
+	}
+
+	podsForDeletion, errs := drainer.GetPodsForDeletion(nodeName)
+	if len(errs) > 0 {
+		return errors.Wrapf(utilerrors.NewAggregate(errs), "failed listing pods to evict at node %s", nodeName)
+	}
+	if warnings := podsForDeletion.Warnings(); warnings != "" {
+		drainLog.Info(warnings, "node", nodeName)
+	}
+
+	pods := podsForDeletion.Pods()
+	onProgress(evicted, len(pods))
+	drainer.OnPodDeletedOrEvicted = func(pod *corev1.Pod, _ bool) {
+		evicted++
+		drainLog.Info(fmt.Sprintf("pod %s/%s evicted", pod.Namespace, pod.Name), "node", nodeName)
+		onProgress(evicted, len(pods))
+	}
+	err := drainer.DeleteOrEvictPods(pods)
+	if err != nil {
+		return errors.Wrapf(err, "failed evicting pods at node %s", nodeName)
+	}
+	return nil
+}
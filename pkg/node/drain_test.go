@@ -0,0 +1,189 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Node cordon", func() {
+	var cli client.Client
+	obtainNode := func() corev1.Node {
+		node := corev1.Node{}
+		ExpectWithOffset(1, cli.Get(context.TODO(), types.NamespacedName{Name: "node01"}, &node)).To(Succeed())
+		return node
+	}
+	DescribeTable("when cordoning the node and",
+		func(unschedulable bool, annotations map[string]string, expectedCordoned bool) {
+			cli = fake.NewClientBuilder().WithObjects(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node01", Annotations: annotations},
+				Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			}).Build()
+			cordoned, err := Cordon(cli, "node01", "br1", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cordoned).To(Equal(expectedCordoned))
+			node := obtainNode()
+			Expect(node.Spec.Unschedulable).To(BeTrue())
+			if expectedCordoned {
+				Expect(node.Annotations).To(HaveKeyWithValue(shared.NodeCordonedByPolicyAnnotation, "br1/2"))
+			}
+
+			Expect(Uncordon(cli, "node01", "br1")).To(Succeed())
+			node = obtainNode()
+			Expect(node.Spec.Unschedulable).To(Equal(!expectedCordoned))
+			if expectedCordoned {
+				Expect(node.Annotations).ToNot(HaveKey(shared.NodeCordonedByPolicyAnnotation))
+			}
+		},
+		Entry("it is schedulable, should report it as cordoned and uncordon it", false, nil, true),
+		Entry("it has been cordoned by the policy before, should report it as cordoned and uncordon it",
+			true, map[string]string{shared.NodeCordonedByPolicyAnnotation: "br1/1"}, true),
+		Entry("it is already unschedulable, should not report it as cordoned nor uncordon it", true, nil, false),
+		Entry("it has been cordoned by another policy, should not report it as cordoned nor uncordon it",
+			true, map[string]string{shared.NodeCordonedByPolicyAnnotation: "br2/1"}, false),
+	)
+	It("should fail if the node does not exist", func() {
+		cli = fake.NewClientBuilder().Build()
+		_, err := Cordon(cli, "node01", "br1", 1)
+		Expect(err).To(MatchError(ContainSubstring("failed cordoning node node01")))
+	})
+	It("should keep a schedulable node when uncordoning it", func() {
+		cli = fake.NewClientBuilder().WithObjects(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node01"}}).Build()
+		Expect(Uncordon(cli, "node01", "br1")).To(Succeed())
+		Expect(obtainNode().Spec.Unschedulable).To(BeFalse())
+	})
+	It("should fail uncordoning a node that does not exist", func() {
+		cli = fake.NewClientBuilder().Build()
+		Expect(Uncordon(cli, "node01", "br1")).To(MatchError(ContainSubstring("failed uncordoning node node01")))
+	})
+})
+
+var _ = Describe("Node drain", func() {
+	const nodeName = "node01"
+	var (
+		kubeClient *kubefake.Clientset
+		progress   [][]int
+	)
+	pod := func(name, ownerKind, ownerName string) *corev1.Pod {
+		controller := true
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(name),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1", Kind: ownerKind, Name: ownerName, Controller: &controller,
+				}},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+	onProgress := func(evicted, total int) {
+		progress = append(progress, []int{evicted, total})
+	}
+	obtainPodNames := func() []string {
+		pods, err := kubeClient.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		names := []string{}
+		for i := range pods.Items {
+			names = append(names, pods.Items[i].Name)
+		}
+		return names
+	}
+	// reactToEviction handles the pod evictions with evict, the pod is
+	// deleted if it returns no error
+	reactToEviction := func(evict func(name string) error) {
+		kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			name := action.(clienttesting.CreateAction).GetObject().(metav1.Object).GetName()
+			if err := evict(name); err != nil {
+				return true, nil, err
+			}
+			return true, nil, kubeClient.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), "default", name)
+		})
+	}
+	BeforeEach(func() {
+		progress = [][]int{}
+		kubeClient = kubefake.NewSimpleClientset(
+			&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "handler", Namespace: "default"}},
+			pod("web-1", "ReplicaSet", "web"),
+			pod("web-2", "ReplicaSet", "web"),
+			pod("handler-1", "DaemonSet", "handler"),
+		)
+		kubeClient.Resources = []*metav1.APIResourceList{{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction", Group: "policy", Version: "v1"}},
+		}}
+	})
+	It("should evict the pods, keeping the daemon set ones, and report the progress", func() {
+		reactToEviction(func(string) error { return nil })
+		Expect(Drain(kubeClient, nodeName, time.Minute, onProgress)).To(Succeed())
+		Expect(obtainPodNames()).To(ConsistOf("handler-1"))
+		Expect(progress).To(Equal([][]int{{0, 2}, {1, 2}, {2, 2}}))
+	})
+	It("should fail at the timeout if a PodDisruptionBudget blocks the eviction", func() {
+		reactToEviction(func(name string) error {
+			if name == "web-2" {
+				return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+			}
+			return nil
+		})
+		err := Drain(kubeClient, nodeName, time.Second, onProgress)
+		Expect(err).To(MatchError(ContainSubstring(`failed evicting pods at node node01: error when evicting pods/"web-2"`)))
+		Expect(err).To(MatchError(ContainSubstring("global timeout reached")))
+		Expect(obtainPodNames()).To(ConsistOf("web-2", "handler-1"))
+		Expect(progress).To(Equal([][]int{{0, 2}, {1, 2}}))
+	})
+	It("should fail at the timeout if an evicted pod is not terminated", func() {
+		kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return action.GetSubresource() == "eviction", nil, nil
+		})
+		err := Drain(kubeClient, nodeName, 500*time.Millisecond, onProgress)
+		Expect(err).To(MatchError(ContainSubstring("failed evicting pods at node node01")))
+		Expect(err).To(MatchError(ContainSubstring("global timeout reached")))
+		Expect(obtainPodNames()).To(ConsistOf("web-1", "web-2", "handler-1"))
+		Expect(progress).To(Equal([][]int{{0, 2}}))
+	})
+	It("should fail with pods not managed by a controller", func() {
+		unmanaged := pod("debug", "ReplicaSet", "web")
+		unmanaged.OwnerReferences = nil
+		Expect(kubeClient.Tracker().Add(unmanaged)).To(Succeed())
+		err := Drain(kubeClient, nodeName, time.Minute, onProgress)
+		Expect(err).To(MatchError(ContainSubstring("failed listing pods to evict at node node01")))
+		Expect(obtainPodNames()).To(ConsistOf("web-1", "web-2", "handler-1", "debug"))
+	})
+})
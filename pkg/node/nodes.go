@@ -93,11 +93,20 @@ func MaxUnavailableNodeCount(cli client.Reader, policy *nmstatev1.NodeNetworkCon
 	if err != nil {
 		return MinMaxunavailable, err
 	}
-	intOrPercent := intstr.FromString(DefaultMaxunavailable)
+	return ScaledMaxUnavailableNodeCount(enactmentsTotal, maxUnavailable(policy))
+}
+
+// MaxUnavailableWaveNodeCount scales the policy MaxUnavailable to the number
+// of nodes at the rollout wave being applied instead of to all the matching nodes.
+func MaxUnavailableWaveNodeCount(policy *nmstatev1.NodeNetworkConfigurationPolicy, waveNodes int) (int, error) {
+	return ScaledMaxUnavailableNodeCount(waveNodes, maxUnavailable(policy))
+}
+
+func maxUnavailable(policy *nmstatev1.NodeNetworkConfigurationPolicy) intstr.IntOrString {
 	if policy.Spec.MaxUnavailable != nil {
-		intOrPercent = *policy.Spec.MaxUnavailable
+		return *policy.Spec.MaxUnavailable
 	}
-	return ScaledMaxUnavailableNodeCount(enactmentsTotal, intOrPercent)
+	return intstr.FromString(DefaultMaxunavailable)
 }
 
 func ScaledMaxUnavailableNodeCount(matchingNodes int, intOrPercent intstr.IntOrString) (int, error) {
@@ -0,0 +1,260 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetemplate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// The node templates at the desired state follow the grammar:
+//
+//	template   := "node" "." field [ "|" conversion ]
+//	field      := "name" | map "[" key "]"
+//	map        := "labels" | "annotations" | "addresses"
+//	key        := double or single quoted string
+//	conversion := "int"
+//
+// where the addresses key is a node address type like "InternalIP" and the
+// int conversion renders the node value as an integer.
+
+// ReferencePrefix starts the desired state node templates, like
+// "{{ node.labels["rack"] }}"
+const ReferencePrefix = "node"
+
+const (
+	nameField        = "name"
+	labelsField      = "labels"
+	annotationsField = "annotations"
+	addressesField   = "addresses"
+
+	intConversion = "int"
+)
+
+var (
+	templateRegexp = regexp.MustCompile(`{{(.*?)}}`)
+	fieldRegexp    = regexp.MustCompile(`^[a-z]+`)
+)
+
+// template is a parsed node template, key is empty for the name
+type template struct {
+	field string
+	key   string
+	toInt bool
+}
+
+// isNodeTemplate returns true for the templates starting with "node.",
+// the rest of them are capture or NodeIPPool references.
+func isNodeTemplate(expression string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expression), ReferencePrefix+".")
+}
+
+func positionError(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), pos+1)
+}
+
+func parse(expression string) (template, error) {
+	pos := len(expression) - len(strings.TrimLeft(expression, " "))
+	expression = strings.TrimRight(expression, " ")
+	pos += len(ReferencePrefix + ".")
+
+	field := fieldRegexp.FindString(expression[pos:])
+	switch field {
+	case nameField:
+		pos += len(field)
+		return parseConversion(expression, pos, template{field: field}, "node.name")
+	case labelsField, annotationsField, addressesField:
+		pos += len(field)
+	default:
+		return template{}, positionError(pos, "unknown node field %q, supported fields: %s", field,
+			strings.Join([]string{nameField, labelsField, annotationsField, addressesField}, ", "))
+	}
+
+	if pos >= len(expression) || expression[pos] != '[' {
+		return template{}, positionError(pos, "expected '[' after node.%s", field)
+	}
+	pos++
+	if pos >= len(expression) || (expression[pos] != '"' && expression[pos] != '\'') {
+		return template{}, positionError(pos, "expected a quoted key")
+	}
+	quote := expression[pos]
+	end := strings.IndexByte(expression[pos+1:], quote)
+	if end < 0 {
+		return template{}, positionError(pos, "unterminated key")
+	}
+	key := expression[pos+1 : pos+1+end]
+	if key == "" {
+		return template{}, positionError(pos, "empty key")
+	}
+	pos += end + 2
+	if pos >= len(expression) || expression[pos] != ']' {
+		return template{}, positionError(pos, "expected ']' after the key")
+	}
+	pos++
+	return parseConversion(expression, pos, template{field: field, key: key}, "']'")
+}
+
+// parseConversion parses the optional conversion following the field
+func parseConversion(expression string, pos int, t template, after string) (template, error) {
+	for pos < len(expression) && expression[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(expression) {
+		return t, nil
+	}
+	if expression[pos] != '|' {
+		return template{}, positionError(pos, "unexpected character '%c' after %s", expression[pos], after)
+	}
+	pos++
+	for pos < len(expression) && expression[pos] == ' ' {
+		pos++
+	}
+	if conversion := expression[pos:]; conversion != intConversion {
+		return template{}, positionError(pos, "unknown conversion %q, supported conversions: %s", conversion, intConversion)
+	}
+	t.toInt = true
+	return t, nil
+}
+
+func (t template) resolve(node *corev1.Node) (string, error) {
+	switch t.field {
+	case nameField:
+		return node.Name, nil
+	case labelsField:
+		if value, found := node.Labels[t.key]; found {
+			return value, nil
+		}
+		return "", fmt.Errorf("node %s has no label %q", node.Name, t.key)
+	case annotationsField:
+		if value, found := node.Annotations[t.key]; found {
+			return value, nil
+		}
+		return "", fmt.Errorf("node %s has no annotation %q", node.Name, t.key)
+	case addressesField:
+		for _, address := range node.Status.Addresses {
+			if string(address.Type) == t.key {
+				return address.Address, nil
+			}
+		}
+		return "", fmt.Errorf("node %s has no %s address", node.Name, t.key)
+	}
+	return "", fmt.Errorf("unknown node field %q", t.field)
+}
+
+// HasTemplates returns true if the desired state contains node templates
+func HasTemplates(desiredState shared.State) bool {
+	for _, match := range templateRegexp.FindAllStringSubmatch(string(desiredState.Raw), -1) {
+		if isNodeTemplate(match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Render replaces the node templates of the desired state with the node
+// values. A value that is a single template with the int conversion is
+// replaced by an integer, so it can be used for fields like a vlan id.
+func Render(desiredState shared.State, node *corev1.Node) (shared.State, error) {
+	if !HasTemplates(desiredState) {
+		return desiredState, nil
+	}
+	var state interface{}
+	if err := yaml.Unmarshal(desiredState.Raw, &state); err != nil {
+		return desiredState, fmt.Errorf("failed decoding desired state: %w", err)
+	}
+	var renderErr error
+	state = replaceStrings(state, func(value string) interface{} {
+		matches := templateRegexp.FindAllStringSubmatchIndex(value, -1)
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) && isNodeTemplate(value[matches[0][2]:matches[0][3]]) {
+			resolved, toInt, err := resolve(value[matches[0][2]:matches[0][3]], node)
+			if err != nil {
+				renderErr = err
+				return value
+			}
+			if toInt {
+				number, _ := strconv.ParseInt(resolved, 10, 64)
+				return number
+			}
+			return resolved
+		}
+		return templateRegexp.ReplaceAllStringFunc(value, func(text string) string {
+			expression := templateRegexp.FindStringSubmatch(text)[1]
+			if !isNodeTemplate(expression) {
+				return text
+			}
+			resolved, _, err := resolve(expression, node)
+			if err != nil {
+				renderErr = err
+				return text
+			}
+			return resolved
+		})
+	})
+	if renderErr != nil {
+		return desiredState, renderErr
+	}
+	rendered, err := yaml.Marshal(state)
+	if err != nil {
+		return desiredState, fmt.Errorf("failed encoding desired state: %w", err)
+	}
+	return shared.NewState(string(rendered)), nil
+}
+
+// resolve returns the node value of the template and whether it is an
+// integer to render as a number
+func resolve(expression string, node *corev1.Node) (string, bool, error) {
+	t, err := parse(expression)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid node template %q: %w", strings.TrimSpace(expression), err)
+	}
+	value, err := t.resolve(node)
+	if err != nil {
+		return "", false, err
+	}
+	if t.toInt {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "", false, fmt.Errorf("node %s value %q of template %q is not an integer", node.Name, value, strings.TrimSpace(expression))
+		}
+	}
+	return value, t.toInt, nil
+}
+
+// replaceStrings replaces every string value of the state with the result
+// of fn, map keys are kept.
+func replaceStrings(value interface{}, fn func(string) interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = replaceStrings(v[key], fn)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceStrings(v[i], fn)
+		}
+	}
+	return value
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetemplate
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Node templates", func() {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node01",
+			Labels:      map[string]string{"rack": "r1", "vlan": "101"},
+			Annotations: map[string]string{"example.com/gateway": "10.0.0.254"},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "node01"},
+				{Type: corev1.NodeInternalIP, Address: "192.168.66.101"},
+			},
+		},
+	}
+	It("Render should replace the node templates with the node values", func() {
+		rendered, err := Render(shared.NewState(`
+interfaces:
+- name: eth1.{{ node.labels["vlan"] }}
+  type: vlan
+  description: "{{node.name}} at rack {{ node.labels['rack'] }}"
+  vlan:
+    base-iface: eth1
+    id: "{{ node.labels['vlan'] | int }}"
+- name: eth2
+  description: "{{ node.labels['vlan'] }}"
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: "{{ node.annotations[\"example.com/gateway\"] }}"
+    next-hop-interface: "{{ capture.default-gw.routes.running.0.next-hop-interface }}"
+dns-resolver:
+  config:
+    server:
+    - '{{ node.addresses["InternalIP"] }}'
+`), node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.String()).To(MatchYAML(`
+interfaces:
+- name: eth1.101
+  type: vlan
+  description: node01 at rack r1
+  vlan:
+    base-iface: eth1
+    id: 101
+- name: eth2
+  description: "101"
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 10.0.0.254
+    next-hop-interface: "{{ capture.default-gw.routes.running.0.next-hop-interface }}"
+dns-resolver:
+  config:
+    server:
+    - 192.168.66.101
+`))
+	})
+	It("Render should keep a desired state without node templates", func() {
+		desiredState := shared.NewState(`
+interfaces:
+- name: eth1
+  description: "{{ capture.eth1.interfaces.0.name }}"
+`)
+		rendered, err := Render(desiredState, node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered).To(Equal(desiredState))
+	})
+	DescribeTable("Render should fail",
+		func(desiredState, expectedError string) {
+			_, err := Render(shared.NewState(desiredState), node)
+			Expect(err).To(MatchError(expectedError))
+		},
+		Entry("with a missing label",
+			`description: "{{ node.labels['zone'] }}"`,
+			`node node01 has no label "zone"`),
+		Entry("with a missing annotation",
+			`description: "{{ node.annotations['zone'] }}"`,
+			`node node01 has no annotation "zone"`),
+		Entry("with a missing address type",
+			`description: "{{ node.addresses['ExternalIP'] }}"`,
+			`node node01 has no ExternalIP address`),
+		Entry("with a non integer value to convert",
+			`description: "{{ node.labels['rack'] | int }}"`,
+			`node node01 value "r1" of template "node.labels['rack'] | int" is not an integer`),
+		Entry("with an invalid template",
+			`description: "{{ node.label['rack'] }}"`,
+			`invalid node template "node.label['rack']": unknown node field "label", `+
+				`supported fields: name, labels, annotations, addresses at position 7`),
+	)
+	DescribeTable("Validate",
+		func(desiredState string, expectedErrors field.ErrorList) {
+			Expect(Validate(shared.NewState(desiredState), field.NewPath("spec", "desiredState"))).To(Equal(expectedErrors))
+		},
+		Entry("should accept valid templates",
+			`
+interfaces:
+- name: "{{ node.name }}"
+  description: "{{ node.labels[\"rack\"] }} {{ node.annotations['a/b'] }} {{ node.addresses['InternalIP'] }}"
+  mtu: "{{ capture.eth1.interfaces.0.mtu }}"
+  vlan:
+    id: "{{ node.labels['vlan']|int }}"
+`,
+			field.ErrorList{}),
+		Entry("should accept a desired state without templates",
+			`interfaces: []`,
+			nil),
+		Entry("should report a missing bracket",
+			`description: "{{ node.labels }}"`,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "desiredState", "description"), "{{ node.labels }}",
+				"invalid node template: expected '[' after node.labels at position 13")}),
+		Entry("should report an unquoted key",
+			`description: "{{ node.labels[rack] }}"`,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "desiredState", "description"), "{{ node.labels[rack] }}",
+				"invalid node template: expected a quoted key at position 14")}),
+		Entry("should report an unterminated key",
+			`description: "{{ node.labels['rack] }}"`,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "desiredState", "description"), "{{ node.labels['rack] }}",
+				"invalid node template: unterminated key at position 14")}),
+		Entry("should report an unknown conversion",
+			`description: "{{ node.labels['vlan'] | float }}"`,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "desiredState", "description"), "{{ node.labels['vlan'] | float }}",
+				`invalid node template: unknown conversion "float", supported conversions: int at position 24`)}),
+		Entry("should report trailing characters",
+			`interfaces: [{name: "{{ node.name.first }}"}]`,
+			field.ErrorList{field.Invalid(field.NewPath("spec", "desiredState", "interfaces").Index(0).Child("name"), "{{ node.name.first }}",
+				"invalid node template: unexpected character '.' after node.name at position 11")}),
+	)
+})
@@ -0,0 +1,72 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodetemplate
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// Validate checks the syntax of the node templates at the desired state
+func Validate(desiredState shared.State, fldPath *field.Path) field.ErrorList {
+	if !HasTemplates(desiredState) {
+		return nil
+	}
+	var state interface{}
+	if err := yaml.Unmarshal(desiredState.Raw, &state); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, "", fmt.Sprintf("failed decoding desired state: %v", err))}
+	}
+	allErrs := field.ErrorList{}
+	walkStrings(state, fldPath, func(value string, valuePath *field.Path) {
+		for _, match := range templateRegexp.FindAllStringSubmatch(value, -1) {
+			if !isNodeTemplate(match[1]) {
+				continue
+			}
+			if _, err := parse(match[1]); err != nil {
+				allErrs = append(allErrs, field.Invalid(valuePath, value, fmt.Sprintf("invalid node template: %v", err)))
+			}
+		}
+	})
+	return allErrs
+}
+
+// walkStrings calls fn with every string value at the state and its path
+func walkStrings(value interface{}, fldPath *field.Path, fn func(string, *field.Path)) {
+	switch v := value.(type) {
+	case string:
+		fn(v, fldPath)
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkStrings(v[key], fldPath.Child(key), fn)
+		}
+	case []interface{}:
+		for i := range v {
+			walkStrings(v[i], fldPath.Index(i), fn)
+		}
+	}
+}
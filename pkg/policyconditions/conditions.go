@@ -20,6 +20,7 @@ package policyconditions
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -34,12 +35,14 @@ import (
 	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/maintenance"
 	"github.com/nmstate/kubernetes-nmstate/pkg/node"
 )
 
 var (
 	log       = logf.Log.WithName("policyconditions")
 	allErrors = func(error) bool { return true }
+	timeNow   = time.Now
 )
 
 type policyConditionStatus struct {
@@ -47,7 +50,11 @@ type policyConditionStatus struct {
 	numberOfReadyNmstateMatchingNodes    int
 	numberOfNotReadyNmstateMatchingNodes int
 	enactmentsCountByCondition           enactmentconditions.ConditionCount
+	numberOfDryRunSucceededEnactments    int
 	numberOfFinishedEnactments           int
+	// nextMaintenanceWindow is when the policy maintenance window opens,
+	// it is nil if the policy has no window or it is open.
+	nextMaintenanceWindow *time.Time
 }
 
 func SetPolicyProgressing(conditions *nmstate.ConditionList, message string) {
@@ -72,6 +79,50 @@ func SetPolicyProgressing(conditions *nmstate.ConditionList, message string) {
 	)
 }
 
+func SetPolicyPaused(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyPaused")
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
+		corev1.ConditionUnknown,
+		nmstate.NodeNetworkConfigurationPolicyConditionPaused,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
+		corev1.ConditionUnknown,
+		nmstate.NodeNetworkConfigurationPolicyConditionPaused,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionProgressing,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionPaused,
+		message,
+	)
+}
+
+func SetPolicyWaitingForMaintenanceWindow(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyWaitingForMaintenanceWindow")
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
+		corev1.ConditionUnknown,
+		nmstate.NodeNetworkConfigurationPolicyConditionWaitingForMaintenanceWindow,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
+		corev1.ConditionUnknown,
+		nmstate.NodeNetworkConfigurationPolicyConditionWaitingForMaintenanceWindow,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionProgressing,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionWaitingForMaintenanceWindow,
+		message,
+	)
+}
+
 func SetPolicySuccess(conditions *nmstate.ConditionList, message string) {
 	log.Info("SetPolicySuccess")
 	conditions.Set(
@@ -116,18 +167,51 @@ func SetPolicyNotMatching(conditions *nmstate.ConditionList, message string) {
 	)
 }
 
+// SetPolicyDryRunSucceeded reports the dry-run as finished without making
+// the policy available, since it has not configured any node.
+func SetPolicyDryRunSucceeded(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyDryRunSucceeded")
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionDryRunSucceeded,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionDryRunSucceeded,
+		message,
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionProgressing,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionConfigurationProgressing,
+		"",
+	)
+}
+
 func SetPolicyFailedToConfigure(conditions *nmstate.ConditionList, message string) {
 	log.Info("SetPolicyFailedToConfigure")
+	setPolicyFailed(conditions, nmstate.NodeNetworkConfigurationPolicyConditionFailedToConfigure, message)
+}
+
+func SetPolicyDryRunFailed(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyDryRunFailed")
+	setPolicyFailed(conditions, nmstate.NodeNetworkConfigurationPolicyConditionDryRunFailed, message)
+}
+
+func setPolicyFailed(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string) {
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
 		corev1.ConditionTrue,
-		nmstate.NodeNetworkConfigurationPolicyConditionFailedToConfigure,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationPolicyConditionFailedToConfigure,
+		reason,
 		"",
 	)
 	conditions.Set(
@@ -238,7 +322,16 @@ func setPolicyStatus(policy *nmstatev1.NodeNetworkConfigurationPolicy, policySta
 	if policyStatus.numberOfNmstateMatchingNodes == 0 {
 		message = "Policy does not match any node"
 		SetPolicyNotMatching(&policy.Status.Conditions, message)
-	} else if policyStatus.enactmentsCountByCondition.Failed() > 0 || policyStatus.enactmentsCountByCondition.Aborted() > 0 {
+	} else if policy.Spec.DryRun && policyStatus.enactmentsCountByCondition.Failed() > 0 &&
+		policyStatus.numberOfFinishedEnactments >= policyStatus.numberOfReadyNmstateMatchingNodes {
+		message = fmt.Sprintf(
+			"%d/%d nodes failed dry-run",
+			policyStatus.enactmentsCountByCondition.Failed(),
+			policyStatus.numberOfNmstateMatchingNodes,
+		)
+		SetPolicyDryRunFailed(&policy.Status.Conditions, message)
+	} else if !policy.Spec.DryRun &&
+		(policyStatus.enactmentsCountByCondition.Failed() > 0 || policyStatus.enactmentsCountByCondition.Aborted() > 0) {
 		message = fmt.Sprintf(
 			"%d/%d nodes failed to configure",
 			policyStatus.enactmentsCountByCondition.Failed(),
@@ -246,6 +339,24 @@ func setPolicyStatus(policy *nmstatev1.NodeNetworkConfigurationPolicy, policySta
 		)
 		informOfAbortedEnactments(policyStatus.enactmentsCountByCondition.Aborted())
 		SetPolicyFailedToConfigure(&policy.Status.Conditions, message)
+	} else if policy.IsPaused() && policyStatus.numberOfFinishedEnactments < policyStatus.numberOfReadyNmstateMatchingNodes {
+		message = fmt.Sprintf(
+			"Policy is paused %d/%d nodes finished",
+			policyStatus.numberOfFinishedEnactments,
+			policyStatus.numberOfReadyNmstateMatchingNodes,
+		)
+		informOfNotReadyNodes(policyStatus.numberOfNotReadyNmstateMatchingNodes)
+		SetPolicyPaused(&policy.Status.Conditions, message)
+	} else if policyStatus.nextMaintenanceWindow != nil && policyStatus.enactmentsCountByCondition.Progressing() == 0 &&
+		policyStatus.numberOfFinishedEnactments < policyStatus.numberOfReadyNmstateMatchingNodes {
+		message = fmt.Sprintf(
+			"Policy is waiting for the maintenance window starting at %s %d/%d nodes finished",
+			policyStatus.nextMaintenanceWindow.Format(time.RFC3339),
+			policyStatus.numberOfFinishedEnactments,
+			policyStatus.numberOfReadyNmstateMatchingNodes,
+		)
+		informOfNotReadyNodes(policyStatus.numberOfNotReadyNmstateMatchingNodes)
+		SetPolicyWaitingForMaintenanceWindow(&policy.Status.Conditions, message)
 	} else if policyStatus.numberOfFinishedEnactments < policyStatus.numberOfReadyNmstateMatchingNodes {
 		message = fmt.Sprintf(
 			"Policy is progressing %d/%d nodes finished",
@@ -257,6 +368,14 @@ func setPolicyStatus(policy *nmstatev1.NodeNetworkConfigurationPolicy, policySta
 			&policy.Status.Conditions,
 			message,
 		)
+	} else if policy.Spec.DryRun {
+		message = fmt.Sprintf(
+			"%d/%d nodes dry-run succeeded",
+			policyStatus.numberOfDryRunSucceededEnactments,
+			policyStatus.numberOfNmstateMatchingNodes,
+		)
+		informOfNotReadyNodes(policyStatus.numberOfNotReadyNmstateMatchingNodes)
+		SetPolicyDryRunSucceeded(&policy.Status.Conditions, message)
 	} else {
 		message = fmt.Sprintf(
 			"%d/%d nodes successfully configured",
@@ -277,15 +396,34 @@ func calculatePolicyConditionStatus(
 	numberOfReadyNmstateMatchingNodes := len(node.FilterReady(*nmstateMatchingNodes))
 	// Let's get conditions with true status count filtered by policy generation
 	enactmentsCountByCondition := enactmentconditions.Count(*enactments, policy.Generation)
+	numberOfDryRunSucceededEnactments := enactmentconditions.CountDryRunSucceeded(*enactments, policy.Generation)
 
 	return policyConditionStatus{
+		nextMaintenanceWindow:                nextMaintenanceWindow(policy, timeNow()),
 		numberOfNmstateMatchingNodes:         numberOfNmstateMatchingNodes,
 		numberOfReadyNmstateMatchingNodes:    numberOfReadyNmstateMatchingNodes,
 		numberOfNotReadyNmstateMatchingNodes: numberOfNmstateMatchingNodes - numberOfReadyNmstateMatchingNodes,
 		enactmentsCountByCondition:           enactmentsCountByCondition,
+		numberOfDryRunSucceededEnactments:    numberOfDryRunSucceededEnactments,
 		numberOfFinishedEnactments: enactmentsCountByCondition.Available() +
 			enactmentsCountByCondition.Failed() +
-			enactmentsCountByCondition.Aborted()}
+			enactmentsCountByCondition.Aborted() +
+			numberOfDryRunSucceededEnactments}
+}
+
+func nextMaintenanceWindow(policy *nmstatev1.NodeNetworkConfigurationPolicy, now time.Time) *time.Time {
+	if policy.Spec.MaintenanceWindow == nil || policy.Spec.DryRun {
+		return nil
+	}
+	window, err := maintenance.Evaluate(policy.Spec.MaintenanceWindow, now)
+	if err != nil {
+		log.Error(err, "failed evaluating policy maintenance window", "policy", policy.Name)
+		return nil
+	}
+	if window.Open || window.NextStart.IsZero() {
+		return nil
+	}
+	return &window.NextStart
 }
 
 func Reset(cli client.Client, policyKey types.NamespacedName) error {
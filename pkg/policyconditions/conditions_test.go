@@ -78,6 +78,24 @@ func s(nodeSelector map[string]string, policy nmstatev1.NodeNetworkConfiguration
 	return policy
 }
 
+func dryRun(policy nmstatev1.NodeNetworkConfigurationPolicy) nmstatev1.NodeNetworkConfigurationPolicy {
+	policy.Spec.DryRun = true
+	return policy
+}
+
+func paused(policy nmstatev1.NodeNetworkConfigurationPolicy) nmstatev1.NodeNetworkConfigurationPolicy {
+	policy.Annotations = map[string]string{nmstate.NodeNetworkConfigurationPolicyPausedAnnotation: "true"}
+	return policy
+}
+
+func withMaintenanceWindow(schedule string, policy nmstatev1.NodeNetworkConfigurationPolicy) nmstatev1.NodeNetworkConfigurationPolicy {
+	policy.Spec.MaintenanceWindow = &nmstate.MaintenanceWindow{
+		Schedule: schedule,
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}
+	return policy
+}
+
 func nodeName(idx int) string {
 	return fmt.Sprintf("node%d", idx)
 }
@@ -180,6 +198,10 @@ var _ = Describe("Policy Conditions", func() {
 		Policy     nmstatev1.NodeNetworkConfigurationPolicy
 		Pods       []corev1.Pod
 	}
+	BeforeEach(func() {
+		timeNow = func() time.Time { return time.Date(2023, time.March, 1, 10, 0, 0, 0, time.UTC) }
+		DeferCleanup(func() { timeNow = time.Now })
+	})
 	DescribeTable("the policy overall condition",
 		func(c ConditionsCase) {
 			objs := []runtime.Object{}
@@ -351,5 +373,78 @@ var _ = Describe("Policy Conditions", func() {
 			Pods:   newNmstatePods(4),
 			Policy: p(SetPolicySuccess, "3/4 nodes successfully configured, 1 nodes ignored due to NotReady state"),
 		}),
+		Entry("when all enactments dry-run succeeded then dry-run policy is succeeded", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetDryRunSuccess),
+				e("node2", "policy1", enactmentconditions.SetDryRunSuccess),
+				e("node3", "policy1", enactmentconditions.SetDryRunSuccess),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: dryRun(p(SetPolicyDryRunSucceeded, "3/3 nodes dry-run succeeded")),
+		}),
+		Entry("when some enactments dry-run failed and others are progressing then dry-run policy is progressing", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetDryRunFailed),
+				e("node2", "policy1", enactmentconditions.SetProgressing),
+				e("node3", "policy1", enactmentconditions.SetDryRunSuccess),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: dryRun(p(SetPolicyProgressing, "Policy is progressing 2/3 nodes finished")),
+		}),
+		Entry("when all enactments finished and some dry-run failed then dry-run policy is failed", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetDryRunFailed),
+				e("node2", "policy1", enactmentconditions.SetDryRunSuccess),
+				e("node3", "policy1", enactmentconditions.SetDryRunSuccess),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: dryRun(p(SetPolicyDryRunFailed, "1/3 nodes failed dry-run")),
+		}),
+		Entry("when policy is paused and some enactments are pending then policy is paused", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetSuccess),
+				e("node2", "policy1", enactmentconditions.SetPaused),
+				e("node3", "policy1", enactmentconditions.SetPaused),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: paused(p(SetPolicyPaused, "Policy is paused 1/3 nodes finished")),
+		}),
+		Entry("when policy is paused and all enactments are success then policy is success", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetSuccess),
+				e("node2", "policy1", enactmentconditions.SetSuccess),
+				e("node3", "policy1", enactmentconditions.SetSuccess),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: paused(p(SetPolicySuccess, "3/3 nodes successfully configured")),
+		}),
+		Entry("when policy maintenance window is closed and some enactments are waiting for it then policy is waiting for it",
+			ConditionsCase{
+				Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+					e("node1", "policy1", enactmentconditions.SetSuccess),
+					e("node2", "policy1", enactmentconditions.SetWaitingForMaintenanceWindow),
+					e("node3", "policy1", enactmentconditions.SetWaitingForMaintenanceWindow),
+				},
+				Nodes: newNodes(3),
+				Pods:  newNmstatePods(3),
+				Policy: withMaintenanceWindow("0 22 * * *", p(SetPolicyWaitingForMaintenanceWindow,
+					"Policy is waiting for the maintenance window starting at 2023-03-01T22:00:00Z 1/3 nodes finished")),
+			}),
+		Entry("when policy maintenance window is open and some enactments are progressing then policy is progressing",
+			ConditionsCase{
+				Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+					e("node1", "policy1", enactmentconditions.SetSuccess),
+					e("node2", "policy1", enactmentconditions.SetProgressing),
+					e("node3", "policy1", enactmentconditions.SetWaitingForMaintenanceWindow),
+				},
+				Nodes:  newNodes(3),
+				Pods:   newNmstatePods(3),
+				Policy: withMaintenanceWindow("0 9 * * *", p(SetPolicyProgressing, "Policy is progressing 1/3 nodes finished")),
+			}),
 	)
 })
@@ -0,0 +1,156 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policytemplate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// ReferencePrefix starts the template references to parameters, like
+// "{{ parameters.vlan }}"
+const ReferencePrefix = "parameters"
+
+var referenceRegexp = regexp.MustCompile(`{{\s*` + ReferencePrefix + `\.([^{}]*?)\s*}}`)
+
+// Parameters returns the values of every template parameter, the defaults
+// are used for the parameters without value.
+func Parameters(template *shared.NodeNetworkConfigurationPolicyTemplateSpec, values map[string]string) (map[string]string, error) {
+	parameters := map[string]string{}
+	missing := []string{}
+	for _, parameter := range template.Parameters {
+		if _, found := parameters[parameter.Name]; found {
+			return nil, fmt.Errorf("duplicated parameter %q", parameter.Name)
+		}
+		if value, found := values[parameter.Name]; found {
+			parameters[parameter.Name] = value
+		} else if parameter.Default != nil {
+			parameters[parameter.Name] = *parameter.Default
+		} else {
+			missing = append(missing, parameter.Name)
+		}
+	}
+	unknown := []string{}
+	for name := range values {
+		if _, found := parameters[name]; !found {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown parameters %s", strings.Join(quote(unknown), ", "))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing parameters %s", strings.Join(quote(missing), ", "))
+	}
+	return parameters, nil
+}
+
+// Render returns the capture and desired state of the template with the
+// parameter references replaced by the values. A desired state value that
+// is a single reference is replaced by an integer if the parameter value is
+// one, so it can be used for fields like a vlan id.
+func Render(
+	template *shared.NodeNetworkConfigurationPolicyTemplateSpec,
+	values map[string]string,
+) (map[string]string, shared.State, error) {
+	parameters, err := Parameters(template, values)
+	if err != nil {
+		return nil, shared.State{}, err
+	}
+	var renderErr error
+	lookup := func(text string) string {
+		name := referenceRegexp.FindStringSubmatch(text)[1]
+		value, found := parameters[name]
+		if !found {
+			renderErr = fmt.Errorf("references undeclared parameter %q", name)
+		}
+		return value
+	}
+
+	var capture map[string]string
+	if template.Capture != nil {
+		capture = map[string]string{}
+		for name, expression := range template.Capture {
+			capture[name] = referenceRegexp.ReplaceAllStringFunc(expression, lookup)
+		}
+	}
+	if renderErr != nil {
+		return nil, shared.State{}, fmt.Errorf("invalid capture: %w", renderErr)
+	}
+
+	if len(template.DesiredState.Raw) == 0 {
+		return capture, template.DesiredState, nil
+	}
+	var state interface{}
+	if err := yaml.Unmarshal(template.DesiredState.Raw, &state); err != nil {
+		return nil, shared.State{}, fmt.Errorf("failed decoding desired state: %w", err)
+	}
+	state = replaceStrings(state, func(value string) interface{} {
+		matches := referenceRegexp.FindAllStringIndex(value, -1)
+		if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+			rendered := lookup(value)
+			if number, err := strconv.ParseInt(rendered, 10, 64); err == nil {
+				return number
+			}
+			return rendered
+		}
+		return referenceRegexp.ReplaceAllStringFunc(value, lookup)
+	})
+	if renderErr != nil {
+		return nil, shared.State{}, fmt.Errorf("invalid desired state: %w", renderErr)
+	}
+	rendered, err := yaml.Marshal(state)
+	if err != nil {
+		return nil, shared.State{}, fmt.Errorf("failed encoding desired state: %w", err)
+	}
+	return capture, shared.NewState(string(rendered)), nil
+}
+
+// replaceStrings replaces every string value of the state with the result
+// of fn, map keys are kept.
+func replaceStrings(value interface{}, fn func(string) interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = replaceStrings(v[key], fn)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceStrings(v[i], fn)
+		}
+	}
+	return value
+}
+
+func quote(names []string) []string {
+	quoted := []string{}
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	return quoted
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policytemplate
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Policy templates", func() {
+	defaultMode := "active-backup"
+	template := shared.NodeNetworkConfigurationPolicyTemplateSpec{
+		Parameters: []shared.NodeNetworkConfigurationPolicyTemplateParameter{
+			{Name: "nic1"},
+			{Name: "nic2"},
+			{Name: "vlan"},
+			{Name: "mode", Default: &defaultMode},
+		},
+		Capture: map[string]string{
+			"nic1": `interfaces.name=="{{ parameters.nic1 }}"`,
+		},
+		DesiredState: shared.NewState(`
+interfaces:
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: "{{ parameters.mode }}"
+    port:
+    - "{{ capture.nic1.interfaces.0.name }}"
+    - "{{parameters.nic2}}"
+- name: bond0.{{ parameters.vlan }}
+  type: vlan
+  state: up
+  vlan:
+    base-iface: bond0
+    id: "{{ parameters.vlan }}"
+`),
+	}
+	It("Render should replace the references with the parameter values and defaults", func() {
+		capture, desiredState, err := Render(&template, map[string]string{"nic1": "eth1", "nic2": "eth2", "vlan": "100"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capture).To(Equal(map[string]string{"nic1": `interfaces.name=="eth1"`}))
+		Expect(desiredState.String()).To(MatchYAML(`
+interfaces:
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: active-backup
+    port:
+    - "{{ capture.nic1.interfaces.0.name }}"
+    - eth2
+- name: bond0.100
+  type: vlan
+  state: up
+  vlan:
+    base-iface: bond0
+    id: 100
+`))
+	})
+	DescribeTable("Render should fail",
+		func(template shared.NodeNetworkConfigurationPolicyTemplateSpec, values map[string]string, expectedError string) {
+			_, _, err := Render(&template, values)
+			Expect(err).To(MatchError(expectedError))
+		},
+		Entry("with missing parameters",
+			template, map[string]string{"nic1": "eth1"},
+			`missing parameters "nic2", "vlan"`),
+		Entry("with unknown parameters",
+			template, map[string]string{"nic1": "eth1", "nic2": "eth2", "vlan": "100", "mtu": "9000"},
+			`unknown parameters "mtu"`),
+		Entry("with duplicated parameters",
+			shared.NodeNetworkConfigurationPolicyTemplateSpec{
+				Parameters: []shared.NodeNetworkConfigurationPolicyTemplateParameter{{Name: "vlan"}, {Name: "vlan"}},
+			}, map[string]string{"vlan": "100"},
+			`duplicated parameter "vlan"`),
+		Entry("with undeclared parameters at the capture",
+			shared.NodeNetworkConfigurationPolicyTemplateSpec{
+				Capture: map[string]string{"nic": `interfaces.name=="{{ parameters.nic }}"`},
+			}, map[string]string{},
+			`invalid capture: references undeclared parameter "nic"`),
+		Entry("with undeclared parameters at the desired state",
+			shared.NodeNetworkConfigurationPolicyTemplateSpec{
+				DesiredState: shared.NewState(`interfaces: [{name: "{{ parameters.nic }}"}]`),
+			}, map[string]string{},
+			`invalid desired state: references undeclared parameter "nic"`),
+	)
+})
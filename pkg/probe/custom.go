@@ -0,0 +1,210 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const (
+	// DefaultCustomProbeTimeout is used by the custom probes without timeout.
+	DefaultCustomProbeTimeout = 120 * time.Second
+	// MaxCustomProbesTotalTimeout bounds how much the custom probes can
+	// extend the checkpoint of a policy, it matches the configuration timeout
+	// so it is at most doubled.
+	MaxCustomProbesTotalTimeout = 480 * time.Second
+	customProbeAttemptTimeout   = 5 * time.Second
+	defaultHTTPExpectedStatus   = http.StatusOK
+)
+
+func customProbes(probesConfig *shared.NodeNetworkConfigurationPolicyProbes) []Probe {
+	if probesConfig == nil {
+		return []Probe{}
+	}
+	probes := []Probe{}
+	for i := range probesConfig.Custom {
+		customProbe := probesConfig.Custom[i]
+		timeout := DefaultCustomProbeTimeout
+		if customProbe.Timeout != nil {
+			timeout = customProbe.Timeout.Duration
+		}
+		probes = append(probes, Probe{
+			name:      customProbe.Name,
+			kind:      customProbeKind(&customProbe),
+			timeout:   timeout,
+			condition: customProbeCondition(&customProbe),
+		})
+	}
+	return probes
+}
+
+// CustomProbesTotalTimeout returns how long the custom probes of a policy
+// can take, they run one after the other after the built-in ones.
+func CustomProbesTotalTimeout(probesConfig *shared.NodeNetworkConfigurationPolicyProbes) time.Duration {
+	total := time.Duration(0)
+	for _, p := range customProbes(probesConfig) {
+		total += p.timeout
+	}
+	return total
+}
+
+func customProbeCondition(customProbe *shared.CustomProbe) func(client.Client, time.Duration) wait.ConditionWithContextFunc {
+	return func(_ client.Client, timeout time.Duration) wait.ConditionWithContextFunc {
+		return func(ctx context.Context) (bool, error) {
+			err := runCustomProbe(ctx, customProbe, attemptTimeout(timeout))
+			if err != nil {
+				log.Error(err, fmt.Sprintf("failed running custom probe '%s'", customProbe.Name))
+				return false, nil
+			}
+			return true, nil
+		}
+	}
+}
+
+func attemptTimeout(timeout time.Duration) time.Duration {
+	if timeout < customProbeAttemptTimeout {
+		return timeout
+	}
+	return customProbeAttemptTimeout
+}
+
+func customProbeKind(customProbe *shared.CustomProbe) string {
+	switch {
+	case customProbe.Ping != nil:
+		return "ping"
+	case customProbe.TCP != nil:
+		return "tcp"
+	case customProbe.HTTP != nil:
+		return "http"
+	case customProbe.DNS != nil:
+		return "dns"
+	}
+	return ""
+}
+
+func runCustomProbe(ctx context.Context, customProbe *shared.CustomProbe, timeout time.Duration) error {
+	switch {
+	case customProbe.Ping != nil:
+		return runCustomPing(customProbe.Ping)
+	case customProbe.TCP != nil:
+		return runCustomTCP(ctx, customProbe.TCP, timeout)
+	case customProbe.HTTP != nil:
+		return runCustomHTTP(ctx, customProbe.HTTP, timeout)
+	case customProbe.DNS != nil:
+		return runCustomDNS(ctx, customProbe.DNS, timeout)
+	}
+	return errors.New("missing probe type")
+}
+
+func runCustomPing(pingProbe *shared.PingProbe) error {
+	target := Route{
+		nextHop: net.ParseIP(pingProbe.Address),
+		iface:   pingProbe.Interface,
+	}
+	if target.nextHop == nil {
+		return fmt.Errorf("invalid ping address %q", pingProbe.Address)
+	}
+	pingOutput, err := ping(target)
+	if err != nil {
+		return errors.Wrapf(err, "failed pinging %s -> output: '%s'", pingProbe.Address, pingOutput)
+	}
+	return nil
+}
+
+func runCustomTCP(ctx context.Context, tcpProbe *shared.TCPProbe, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	address := net.JoinHostPort(tcpProbe.Host, strconv.Itoa(int(tcpProbe.Port)))
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "failed connecting to %s", address)
+	}
+	return conn.Close()
+}
+
+func runCustomHTTP(ctx context.Context, httpProbe *shared.HTTPProbe, timeout time.Duration) error {
+	expectedStatus := defaultHTTPExpectedStatus
+	if httpProbe.ExpectedStatus != 0 {
+		expectedStatus = httpProbe.ExpectedStatus
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpProbe.URL, http.NoBody)
+	if err != nil {
+		return errors.Wrapf(err, "failed creating request for %s", httpProbe.URL)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed requesting %s", httpProbe.URL)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != expectedStatus {
+		return fmt.Errorf("unexpected status %d requesting %s, expected %d", response.StatusCode, httpProbe.URL, expectedStatus)
+	}
+	return nil
+}
+
+func runCustomDNS(ctx context.Context, dnsProbe *shared.DNSProbe, timeout time.Duration) error {
+	nameServers := []string{}
+	if dnsProbe.Server != "" {
+		nameServers = append(nameServers, dnsProbe.Server)
+	} else {
+		// Get the name servers at node since the ones at container may not be up to date
+		currentState, err := observedState()
+		if err != nil {
+			return errors.Wrap(err, "failed retrieving current state to get name resolving config")
+		}
+		nameServers = append(nameServers, runningNameServers(currentState)...)
+	}
+	if len(nameServers) == 0 {
+		return errors.New("missing name servers")
+	}
+	errs := []error{}
+	for _, nameServer := range nameServers {
+		err := lookupHost(ctx, nameServer, dnsProbe.Name, timeout)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("failed resolving %s: %v", dnsProbe.Name, errs)
+}
+
+func lookupHost(ctx context.Context, nameServer, name string, timeout time.Duration) error {
+	r := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, network, net.JoinHostPort(nameServer, "53"))
+		},
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err := r.LookupHost(ctx, name)
+	return err
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+func TestFilterOutDisabled(t *testing.T) {
+	probes := []Probe{
+		{name: string(shared.BuiltInProbeAPIServer)},
+		{name: string(shared.BuiltInProbeNodeReadiness)},
+	}
+
+	enabledProbes := filterOutDisabled(probes, nil)
+	if len(enabledProbes) != 2 {
+		t.Fatalf("expected all probes without configuration, got %v", enabledProbes)
+	}
+
+	enabledProbes = filterOutDisabled(probes, &shared.NodeNetworkConfigurationPolicyProbes{
+		DisableBuiltIn: []shared.BuiltInProbeName{shared.BuiltInProbeNodeReadiness},
+	})
+	if len(enabledProbes) != 1 || enabledProbes[0].name != string(shared.BuiltInProbeAPIServer) {
+		t.Fatalf("expected only api-server probe, got %v", enabledProbes)
+	}
+}
+
+func TestCustomProbesTimeout(t *testing.T) {
+	probes := customProbes(&shared.NodeNetworkConfigurationPolicyProbes{
+		Custom: []shared.CustomProbe{
+			{Name: "default", TCP: &shared.TCPProbe{Host: "127.0.0.1", Port: 80}},
+			{Name: "custom", TCP: &shared.TCPProbe{Host: "127.0.0.1", Port: 80}, Timeout: &metav1.Duration{Duration: 10 * time.Second}},
+		},
+	})
+	if len(probes) != 2 {
+		t.Fatalf("expected two custom probes, got %d", len(probes))
+	}
+	if probes[0].kind != "tcp" || probes[1].kind != "tcp" {
+		t.Errorf("expected tcp custom probes, got %s and %s", probes[0].kind, probes[1].kind)
+	}
+	if probes[0].name != "default" || probes[0].timeout != DefaultCustomProbeTimeout {
+		t.Errorf("unexpected default probe %s with timeout %s", probes[0].name, probes[0].timeout)
+	}
+	if probes[1].name != "custom" || probes[1].timeout != 10*time.Second {
+		t.Errorf("unexpected custom probe %s with timeout %s", probes[1].name, probes[1].timeout)
+	}
+	if total := CustomProbesTotalTimeout(nil); total != 0 {
+		t.Errorf("expected no timeout without custom probes, got %s", total)
+	}
+}
+
+func TestCustomProbesTotalTimeout(t *testing.T) {
+	total := CustomProbesTotalTimeout(&shared.NodeNetworkConfigurationPolicyProbes{
+		Custom: []shared.CustomProbe{
+			{Name: "default", TCP: &shared.TCPProbe{Host: "127.0.0.1", Port: 80}},
+			{Name: "custom", TCP: &shared.TCPProbe{Host: "127.0.0.1", Port: 80}, Timeout: &metav1.Duration{Duration: 10 * time.Second}},
+		},
+	})
+	if total != DefaultCustomProbeTimeout+10*time.Second {
+		t.Errorf("unexpected custom probes total timeout %s", total)
+	}
+}
+
+func TestCustomTCPProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	tcpProbe := &shared.CustomProbe{Name: "tcp", TCP: &shared.TCPProbe{Host: "127.0.0.1", Port: int32(port)}}
+
+	if err = runCustomProbe(context.TODO(), tcpProbe, time.Second); err != nil {
+		t.Errorf("expected tcp probe to succeed: %v", err)
+	}
+
+	listener.Close()
+	if err = runCustomProbe(context.TODO(), tcpProbe, time.Second); err == nil {
+		t.Errorf("expected tcp probe to fail at closed port %s", strconv.Itoa(port))
+	}
+}
+
+func TestCustomHTTPProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		desc      string
+		probe     shared.HTTPProbe
+		shouldErr bool
+	}{
+		{
+			desc:  "default expected status",
+			probe: shared.HTTPProbe{URL: server.URL + "/ready"},
+		},
+		{
+			desc:  "custom expected status",
+			probe: shared.HTTPProbe{URL: server.URL + "/other", ExpectedStatus: http.StatusNoContent},
+		},
+		{
+			desc:      "unexpected status",
+			probe:     shared.HTTPProbe{URL: server.URL + "/other"},
+			shouldErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			httpProbe := tc.probe
+			err := runCustomProbe(context.TODO(), &shared.CustomProbe{Name: "http", HTTP: &httpProbe}, time.Second)
+			if tc.shouldErr && err == nil {
+				t.Errorf("expected error")
+			}
+			if !tc.shouldErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
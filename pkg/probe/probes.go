@@ -33,20 +33,25 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
-	yaml "sigs.k8s.io/yaml"
-
-	"github.com/tidwall/gjson"
 
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
+	"github.com/nmstate/kubernetes-nmstate/pkg/monitoring"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
 )
 
 var (
 	log = logf.Log.WithName("probe")
 )
 
+// builtInProbeKind labels the metrics of the built-in probes, the custom ones
+// are labeled by their type so the user defined names do not end up there.
+const builtInProbeKind = "builtin"
+
 type Probe struct {
 	name      string
+	kind      string
 	timeout   time.Duration
 	condition func(client.Client, time.Duration) wait.ConditionWithContextFunc
 }
@@ -70,24 +75,23 @@ const (
 		nodeReadinessProbeTimeout
 )
 
-func currentStateAsGJson() (gjson.Result, error) {
+func observedState() (*schema.State, error) {
 	observedStateRaw, err := nmstatectl.Show()
 	if err != nil {
-		return gjson.Result{}, errors.Wrap(err, "failed retrieving current state")
+		return nil, errors.Wrap(err, "failed retrieving current state")
 	}
-	res, err := yamlToGJson(observedStateRaw)
+	state, err := schema.FromState(shared.NewState(observedStateRaw))
 	if err != nil {
-		return gjson.Result{}, errors.Wrap(err, "failed to convert the current state to GJson")
+		return nil, errors.Wrap(err, "failed to decode the current state")
 	}
-	return res, nil
+	return state, nil
 }
 
-func yamlToGJson(rawYaml string) (gjson.Result, error) {
-	json, err := yaml.YAMLToJSON([]byte(rawYaml))
-	if err != nil {
-		return gjson.Result{}, err
+func runningNameServers(currentState *schema.State) []string {
+	if currentState.DNSResolver == nil || currentState.DNSResolver.Running == nil {
+		return nil
 	}
-	return gjson.ParseBytes(json), nil
+	return currentState.DNSResolver.Running.Server
 }
 
 func apiServerCondition(_ client.Client, _ time.Duration) wait.ConditionWithContextFunc {
@@ -140,27 +144,27 @@ func checkNodeReadiness(cli client.Client) (bool, error) {
 	return false, nil
 }
 
-func defaultGw(currentState gjson.Result) (Route, error) {
+func defaultGw(currentState *schema.State) (Route, error) {
 	var found Route
-	currentState.Get("routes.running").ForEach(
-		func(_, v gjson.Result) bool {
+	if currentState.Routes != nil {
+		for _, route := range currentState.Routes.Running {
+			tableID, err := route.TableID.Int64()
 			// we want to pick the next hop related to the "main" table because we may have multiple tables
-			if (v.Get("destination").String() == "0.0.0.0/0" || v.Get("destination").String() == "::/0") &&
-				v.Get("table-id").Int() == mainRoutingTableID {
-				found.nextHop = net.ParseIP(v.Get("next-hop-address").String())
-				found.iface = v.Get("next-hop-interface").String()
-				return false
+			if (route.Destination == "0.0.0.0/0" || route.Destination == "::/0") &&
+				err == nil && tableID == mainRoutingTableID {
+				found.nextHop = net.ParseIP(route.NextHopAddress)
+				found.iface = route.NextHopInterface
+				break
 			}
-			return true
-		},
-	)
+		}
+	}
 
 	if found.nextHop == nil {
 		msg := "default gw missing"
 		defaultGwLog := log.WithValues("path", "routes.running.next-hop-address", "table-id", mainRoutingTableID)
 		defaultGwLogDebug := defaultGwLog.V(1)
 		if defaultGwLogDebug.Enabled() {
-			defaultGwLogDebug.Info(msg, "state", currentState.String())
+			defaultGwLogDebug.Info(msg, "state", currentState)
 		} else {
 			defaultGwLog.Info(msg)
 		}
@@ -176,12 +180,12 @@ func pingCondition(cli client.Client, timeout time.Duration) wait.ConditionWithC
 }
 
 func runPing(_ client.Client) (bool, error) {
-	gjsonCurrentState, err := currentStateAsGJson()
+	currentState, err := observedState()
 	if err != nil {
 		return false, errors.Wrap(err, "failed retrieving current state to retrieve default gw")
 	}
 
-	defaultGw, err := defaultGw(gjsonCurrentState)
+	defaultGw, err := defaultGw(currentState)
 	if err != nil {
 		log.Error(err, "failed to retrieve default gw")
 		return false, nil
@@ -198,11 +202,16 @@ func runPing(_ client.Client) (bool, error) {
 func ping(target Route) (string, error) {
 	// If next hop is IPv6 link-local, we need to append an interface otherwise it is
 	// not clear which interface should be used for communication (e.g. ping test).
-	// As this syntax works always, we simply append it always.
+	// As this syntax works always, we simply append it whenever the interface is known,
+	// custom ping probes may not specify it.
 	//
-	// It is safe to ignore gosec error about concatenated strings as the Route struct
-	// is not directly taking any user input.
-	cmd := exec.Command("ping", "-I", target.iface, "-c", "1", target.nextHop.String()) // #nosec G204
+	// It is safe to ignore gosec error about concatenated strings as the arguments
+	// are passed directly to ping without a shell.
+	args := []string{"-c", "1", target.nextHop.String()}
+	if target.iface != "" {
+		args = append([]string{"-I", target.iface}, args...)
+	}
+	cmd := exec.Command("ping", args...) // #nosec G204
 	var outputBuffer bytes.Buffer
 	cmd.Stdout = &outputBuffer
 	cmd.Stderr = &outputBuffer
@@ -221,25 +230,24 @@ func dnsCondition(cli client.Client, timeout time.Duration) wait.ConditionWithCo
 }
 
 func runDNS(_ client.Client, timeout time.Duration) (bool, error) {
-	runningServersGJsonPath := "dns-resolver.running.server"
 	errs := []error{}
 
 	// Get the name servers at node since the ones at container may not be up to date
-	currentStateAsGJson, err := currentStateAsGJson()
+	currentState, err := observedState()
 	if err != nil {
 		return false, errors.Wrap(err, "failed retrieving current state to get name resolving config")
 	}
-	runningNameServers := currentStateAsGJson.Get(runningServersGJsonPath).Array()
-	if len(runningNameServers) == 0 {
-		log.Info(fmt.Sprintf("missing name servers at '%s' on %s", runningServersGJsonPath, currentStateAsGJson.String()))
+	nameServers := runningNameServers(currentState)
+	if len(nameServers) == 0 {
+		log.Info("missing name servers at 'dns-resolver.running.server'", "state", currentState)
 		return false, nil
 	}
-	for _, runningNameServer := range runningNameServers {
+	for _, runningNameServer := range nameServers {
 		r := &net.Resolver{
 			PreferGo: true,
 			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
 				d := net.Dialer{}
-				return d.DialContext(ctx, network, net.JoinHostPort(runningNameServer.String(), "53"))
+				return d.DialContext(ctx, network, net.JoinHostPort(runningNameServer, "53"))
 			},
 		}
 		ctx, cancel := context.WithTimeout(context.TODO(), timeout)
@@ -256,22 +264,25 @@ func runDNS(_ client.Client, timeout time.Duration) (bool, error) {
 	return false, nil
 }
 
-// Select will return the external connectivity probes that are working (ping and dns) and
-// the internal connectivity probes
-func Select(cli client.Client) []Probe {
+// Select will return the external connectivity probes that are working (ping and dns),
+// the internal connectivity probes and the custom probes configured at the policy,
+// built-in probes disabled at the policy are not selected.
+func Select(cli client.Client, probesConfig *shared.NodeNetworkConfigurationPolicyProbes) []Probe {
 	probes := []Probe{}
-	externalConnectivityProbes := []Probe{
+	externalConnectivityProbes := filterOutDisabled([]Probe{
 		{
-			name:      "ping",
+			name:      string(shared.BuiltInProbePing),
+			kind:      builtInProbeKind,
 			timeout:   defaultGwProbeTimeout,
 			condition: pingCondition,
 		},
 		{
-			name:      "dns",
+			name:      string(shared.BuiltInProbeDNS),
+			kind:      builtInProbeKind,
 			timeout:   defaultDNSProbeTimeout,
 			condition: dnsCondition,
 		},
-	}
+	}, probesConfig)
 
 	for _, p := range externalConnectivityProbes {
 		err := wait.PollUntilContextTimeout(context.TODO(), time.Second, p.timeout, true /*immediate*/, p.condition(cli, p.timeout))
@@ -282,19 +293,45 @@ func Select(cli client.Client) []Probe {
 		}
 	}
 
-	probes = append(probes,
-		Probe{
-			name:      "api-server",
+	probes = append(probes, filterOutDisabled([]Probe{
+		{
+			name:      string(shared.BuiltInProbeAPIServer),
+			kind:      builtInProbeKind,
 			timeout:   apiServerProbeTimeout,
 			condition: apiServerCondition,
 		},
-		Probe{
-			name:      "node-readiness",
+		{
+			name:      string(shared.BuiltInProbeNodeReadiness),
+			kind:      builtInProbeKind,
 			timeout:   nodeReadinessProbeTimeout,
 			condition: nodeReadinessCondition,
-		})
+		}}, probesConfig)...)
 
-	return probes
+	return append(probes, customProbes(probesConfig)...)
+}
+
+func filterOutDisabled(probes []Probe, probesConfig *shared.NodeNetworkConfigurationPolicyProbes) []Probe {
+	if probesConfig == nil {
+		return probes
+	}
+	enabledProbes := []Probe{}
+	for _, p := range probes {
+		if isDisabled(p.name, probesConfig.DisableBuiltIn) {
+			log.Info(fmt.Sprintf("not selecting %s probe, it is disabled at policy", p.name))
+			continue
+		}
+		enabledProbes = append(enabledProbes, p)
+	}
+	return enabledProbes
+}
+
+func isDisabled(name string, disabledProbes []shared.BuiltInProbeName) bool {
+	for _, disabledProbe := range disabledProbes {
+		if string(disabledProbe) == name {
+			return true
+		}
+	}
+	return false
 }
 
 // Run will run the externalConnectivityProbes and also some internal
@@ -307,13 +344,34 @@ func Run(cli client.Client, probes []Probe) error {
 
 	for _, p := range probes {
 		log.Info(fmt.Sprintf("Running '%s' probe", p.name))
+		start := time.Now()
 		err = wait.PollUntilContextTimeout(context.TODO(), time.Second, p.timeout, true /*immediate*/, p.condition(cli, p.timeout))
+		monitoring.ProbeDuration.WithLabelValues(p.kind).Observe(time.Since(start).Seconds())
 		if err != nil {
-			return errors.Wrapf(
-				err,
-				"failed runnig probe '%s' with after network reconfiguration -> currentState: %s", p.name, currentState,
-			)
+			monitoring.ProbeFailures.WithLabelValues(p.kind).Inc()
+			return &Failure{
+				Name: p.name,
+				err: errors.Wrapf(
+					err,
+					"failed runnig probe '%s' with after network reconfiguration -> currentState: %s", p.name, currentState,
+				),
+			}
 		}
 	}
 	return nil
 }
+
+// Failure is returned by Run when one of the probes does not pass after
+// network reconfiguration.
+type Failure struct {
+	Name string
+	err  error
+}
+
+func (f *Failure) Error() string {
+	return f.err.Error()
+}
+
+func (f *Failure) Unwrap() error {
+	return f.err
+}
@@ -20,6 +20,9 @@ package probe
 import (
 	"net"
 	"testing"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
 )
 
 // nolint: funlen
@@ -138,11 +141,11 @@ func TestDefaultGatewayParsing(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
-			gJSON, err := yamlToGJson(test.status)
+			currentState, err := schema.FromState(shared.NewState(test.status))
 			if err != nil {
 				t.Fatalf("failed to parse test status, %v", err)
 			}
-			defaultGw, err := defaultGw(gJSON)
+			defaultGw, err := defaultGw(currentState)
 			if err != nil && !test.shouldErr {
 				t.Fatalf("unexpected error %v", err)
 			}
@@ -0,0 +1,146 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protection
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+)
+
+// NodeSelectorMatchFunc returns true if the protection of an interface or
+// route with the node selector applies
+type NodeSelectorMatchFunc func(nodeSelector map[string]string) bool
+
+// Get returns the NMState ruling the cluster, the oldest one as the operator
+// does, or nil if there is none.
+func Get(cli client.Reader) (*nmstatev1.NMState, error) {
+	nmstateList := nmstatev1.NMStateList{}
+	if err := cli.List(context.TODO(), &nmstateList); err != nil {
+		return nil, errors.Wrap(err, "failed listing NMStates")
+	}
+	if len(nmstateList.Items) == 0 {
+		return nil, nil
+	}
+	sort.Slice(nmstateList.Items, func(i, j int) bool {
+		return nmstateList.Items[j].CreationTimestamp.After(nmstateList.Items[i].CreationTimestamp.Time)
+	})
+	return &nmstateList.Items[0], nil
+}
+
+// HasProtected returns true if the NMState protects some interface or route
+func HasProtected(nmstate *nmstatev1.NMState) bool {
+	return nmstate != nil && (len(nmstate.Spec.ProtectedInterfaces) > 0 || len(nmstate.Spec.ProtectedRoutes) > 0)
+}
+
+// MatchesLabels returns a NodeSelectorMatchFunc for a node with the labels
+func MatchesLabels(nodeLabels map[string]string) NodeSelectorMatchFunc {
+	return func(nodeSelector map[string]string) bool {
+		return labels.SelectorFromSet(nodeSelector).Matches(labels.Set(nodeLabels))
+	}
+}
+
+// Validate rejects the desired state changes to the interfaces and routes
+// protected by the NMState. An interface is changed if it is configured,
+// routed through or used as a controller, port, base interface or peer by
+// any interface type.
+func Validate(nmstate *nmstatev1.NMState, matches NodeSelectorMatchFunc, state *schema.State, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if !HasProtected(nmstate) {
+		return allErrs
+	}
+	checkInterface := func(name string, path *field.Path) {
+		if isProtectedInterface(nmstate, matches, name) {
+			allErrs = append(allErrs, field.Forbidden(path,
+				fmt.Sprintf("interface %s is protected by NMState %s", name, nmstate.Name)))
+		}
+	}
+	for i := range state.Interfaces {
+		iface := &state.Interfaces[i]
+		ifacePath := fldPath.Child("interfaces").Index(i)
+		checkInterface(iface.Name, ifacePath.Child("name"))
+		for _, reference := range iface.References(ifacePath) {
+			checkInterface(reference.Name, reference.Path)
+		}
+	}
+	if state.Routes != nil {
+		for i := range state.Routes.Config {
+			route := &state.Routes.Config[i]
+			routePath := fldPath.Child("routes", "config").Index(i)
+			if route.NextHopInterface != "" {
+				checkInterface(route.NextHopInterface, routePath.Child("next-hop-interface"))
+			}
+			if route.Destination != "" && isProtectedRoute(nmstate, matches, route.Destination) {
+				allErrs = append(allErrs, field.Forbidden(routePath.Child("destination"),
+					fmt.Sprintf("route to %s is protected by NMState %s", route.Destination, nmstate.Name)))
+			}
+			// an absent route without destination removes the routes
+			// matching the rest of its fields, whatever their destination
+			if route.Destination == "" && route.State == "absent" && hasProtectedRoutes(nmstate, matches) {
+				allErrs = append(allErrs, field.Forbidden(routePath,
+					fmt.Sprintf("absent route without destination can remove the routes protected by NMState %s", nmstate.Name)))
+			}
+		}
+	}
+	return allErrs
+}
+
+func isProtectedInterface(nmstate *nmstatev1.NMState, matches NodeSelectorMatchFunc, name string) bool {
+	for _, protected := range nmstate.Spec.ProtectedInterfaces {
+		if matchesPattern(protected.Name, name) && matches(protected.NodeSelector) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasProtectedRoutes(nmstate *nmstatev1.NMState, matches NodeSelectorMatchFunc) bool {
+	for _, protected := range nmstate.Spec.ProtectedRoutes {
+		if matches(protected.NodeSelector) {
+			return true
+		}
+	}
+	return false
+}
+
+func isProtectedRoute(nmstate *nmstatev1.NMState, matches NodeSelectorMatchFunc, destination string) bool {
+	for _, protected := range nmstate.Spec.ProtectedRoutes {
+		if matchesPattern(protected.Destination, destination) && matches(protected.NodeSelector) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern returns true if the value matches the shell pattern, the
+// "/" is not a separator so "*" also matches the prefix of route
+// destinations.
+func matchesPattern(pattern, value string) bool {
+	matched, err := path.Match(strings.ReplaceAll(pattern, "/", "|"), strings.ReplaceAll(value, "/", "|"))
+	return err == nil && matched
+}
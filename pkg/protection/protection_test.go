@@ -0,0 +1,195 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protection
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+)
+
+var _ = Describe("Protected interfaces and routes", func() {
+	nmstate := &nmstatev1.NMState{
+		ObjectMeta: metav1.ObjectMeta{Name: "nmstate"},
+		Spec: nmstatev1.NMStateSpec{
+			ProtectedInterfaces: []nmstatev1.ProtectedInterface{
+				{Name: "eth0"},
+				{Name: "br-ex"},
+				{Name: "eno*", NodeSelector: map[string]string{"vendor": "dell"}},
+			},
+			ProtectedRoutes: []nmstatev1.ProtectedRoute{
+				{Destination: "0.0.0.0/0"},
+				{Destination: "10.0.*", NodeSelector: map[string]string{"rack": "r1"}},
+			},
+		},
+	}
+	It("Get should return the oldest NMState", func() {
+		s := runtime.NewScheme()
+		s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NMState{}, &nmstatev1.NMStateList{})
+		cli := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(
+			&nmstatev1.NMState{ObjectMeta: metav1.ObjectMeta{Name: "newer", CreationTimestamp: metav1.NewTime(time.Now())}},
+			&nmstatev1.NMState{ObjectMeta: metav1.ObjectMeta{Name: "older", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}},
+		).Build()
+		obtained, err := Get(cli)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obtained.Name).To(Equal("older"))
+
+		obtained, err = Get(fake.NewClientBuilder().WithScheme(s).Build())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obtained).To(BeNil())
+	})
+	DescribeTable("Validate",
+		func(nodeLabels map[string]string, desiredState string, expectedErrs field.ErrorList) {
+			state, err := schema.FromState(shared.NewState(desiredState))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(Validate(nmstate, MatchesLabels(nodeLabels), state, field.NewPath("spec", "desiredState"))).To(Equal(expectedErrs))
+		},
+		Entry("should allow interfaces and routes not protected",
+			map[string]string{"vendor": "hpe"},
+			`
+interfaces:
+- name: eno1.100
+  type: vlan
+  vlan:
+    base-iface: eno1
+    id: 100
+routes:
+  config:
+  - destination: 10.0.0.0/8
+    next-hop-interface: eno1.100
+`,
+			field.ErrorList{}),
+		Entry("should reject configuring, using as a port or routing through protected interfaces",
+			map[string]string{"vendor": "dell"},
+			`
+interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: eno1
+- name: bond0
+  type: bond
+  link-aggregation:
+    port:
+    - eno2
+- name: eno3
+  type: ethernet
+  controller: br-ex
+routes:
+  config:
+  - destination: 192.168.0.0/16
+    next-hop-interface: br-ex
+`,
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(0).Child("name"),
+					"interface eth0 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(1).Child("bridge", "port").Index(0).Child("name"),
+					"interface eno1 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(2).Child("link-aggregation", "port").Index(0),
+					"interface eno2 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(3).Child("name"),
+					"interface eno3 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(3).Child("controller"),
+					"interface br-ex is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0).Child("next-hop-interface"),
+					"interface br-ex is protected by NMState nmstate"),
+			}),
+		Entry("should reject using protected interfaces at the interface types not modeled",
+			map[string]string{"vendor": "dell"},
+			`
+interfaces:
+- name: vrf0
+  type: vrf
+  vrf:
+    port:
+    - eth0
+    route-table-id: 100
+- name: team0
+  type: team
+  team:
+    port:
+    - name: eno1
+- name: macvlan0
+  type: mac-vlan
+  mac-vlan:
+    base-iface: br-ex
+    mode: bridge
+`,
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(0).Child("vrf", "port").Index(0),
+					"interface eth0 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(1).Child("team", "port").Index(0).Child("name"),
+					"interface eno1 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(2).Child("mac-vlan", "base-iface"),
+					"interface br-ex is protected by NMState nmstate"),
+			}),
+		Entry("should reject absent routes without destination if there are protected routes at the node",
+			map[string]string{"rack": "r2"},
+			`
+routes:
+  config:
+  - next-hop-interface: eth1
+    state: absent
+  - destination: 192.168.0.0/16
+    state: absent
+`,
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0),
+					"absent route without destination can remove the routes protected by NMState nmstate"),
+			}),
+		Entry("should reject protected routes at the selected nodes",
+			map[string]string{"rack": "r1"},
+			`
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+  - destination: 10.0.0.0/8
+    next-hop-address: 192.168.1.1
+`,
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0).Child("destination"),
+					"route to 0.0.0.0/0 is protected by NMState nmstate"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(1).Child("destination"),
+					"route to 10.0.0.0/8 is protected by NMState nmstate"),
+			}),
+		Entry("should allow routes protected at other nodes",
+			map[string]string{"rack": "r2"},
+			`
+routes:
+  config:
+  - destination: 10.0.0.0/8
+    next-hop-address: 192.168.1.1
+`,
+			field.ErrorList{}),
+	)
+})
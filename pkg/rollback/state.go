@@ -0,0 +1,191 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollback
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/diff"
+)
+
+const absentState = "absent"
+
+// StateBeforeApply returns the parts of the current state that the desired
+// state changes so applying it undoes the desired state. It contains the
+// desired interfaces and the ones they use as ports or base interfaces, the
+// routes and route rules and the dns config. Interfaces, routes and route
+// rules that the desired state adds are marked as absent.
+func StateBeforeApply(desiredState, currentState shared.State) (shared.State, error) {
+	desired := map[string]interface{}{}
+	err := yaml.Unmarshal(desiredState.Raw, &desired)
+	if err != nil {
+		return shared.State{}, errors.Wrap(err, "failed unmarshaling desired state")
+	}
+	current := map[string]interface{}{}
+	err = yaml.Unmarshal(currentState.Raw, &current)
+	if err != nil {
+		return shared.State{}, errors.Wrap(err, "failed unmarshaling current state")
+	}
+
+	before := map[string]interface{}{}
+	interfaces := interfacesBeforeApply(list(desired["interfaces"]), list(current["interfaces"]))
+	if len(interfaces) > 0 {
+		before["interfaces"] = interfaces
+	}
+	for _, section := range []string{"routes", "route-rules"} {
+		config := configBeforeApply(list(config(desired[section])), list(config(current[section])))
+		if len(config) > 0 {
+			before[section] = map[string]interface{}{"config": config}
+		}
+	}
+	if _, ok := desired["dns-resolver"]; ok {
+		dnsConfig := config(current["dns-resolver"])
+		if dnsConfig == nil {
+			dnsConfig = map[string]interface{}{}
+		}
+		before["dns-resolver"] = map[string]interface{}{"config": dnsConfig}
+	}
+
+	beforeRaw, err := yaml.Marshal(before)
+	if err != nil {
+		return shared.State{}, errors.Wrap(err, "failed marshaling state before apply")
+	}
+	return shared.NewState(string(beforeRaw)), nil
+}
+
+func interfacesBeforeApply(desired, current []interface{}) []interface{} {
+	interfaces := []interface{}{}
+	added := map[string]bool{}
+	addInterface := func(name string, absent map[string]interface{}) {
+		if added[name] {
+			return
+		}
+		added[name] = true
+		currentInterface := diff.FindItem(map[string]interface{}{"name": name}, current)
+		if currentInterface != nil {
+			interfaces = append(interfaces, currentInterface)
+		} else if absent != nil {
+			interfaces = append(interfaces, absent)
+		}
+	}
+	for _, desiredInterface := range desired {
+		desiredInterfaceMap, ok := desiredInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := desiredInterfaceMap["name"].(string)
+		if !ok {
+			continue
+		}
+		absent := map[string]interface{}{"name": name, "state": absentState}
+		if interfaceType, ok := desiredInterfaceMap["type"]; ok {
+			absent["type"] = interfaceType
+		}
+		addInterface(name, absent)
+		for _, usedInterface := range usedInterfaces(desiredInterfaceMap) {
+			// Interfaces used by the desired ones that do not exist are not
+			// created by the desired state
+			addInterface(usedInterface, nil)
+		}
+	}
+	return interfaces
+}
+
+// usedInterfaces returns the names of the ports and base interfaces of an
+// interface, it looks for them at any level since each interface type has
+// them at a different place.
+func usedInterfaces(value interface{}) []string {
+	names := []string{}
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			attribute := typedValue[key]
+			switch key {
+			case "base-iface":
+				if name, ok := attribute.(string); ok {
+					names = append(names, name)
+				}
+			case "port":
+				for _, port := range list(attribute) {
+					if name, ok := port.(string); ok {
+						names = append(names, name)
+					} else if portMap, ok := port.(map[string]interface{}); ok {
+						if name, ok := portMap["name"].(string); ok {
+							names = append(names, name)
+						}
+					}
+				}
+			default:
+				names = append(names, usedInterfaces(attribute)...)
+			}
+		}
+	case []interface{}:
+		for _, item := range typedValue {
+			names = append(names, usedInterfaces(item)...)
+		}
+	}
+	return names
+}
+
+// configBeforeApply returns the current items changed by the desired ones,
+// desired items not present at the current config are marked as absent.
+func configBeforeApply(desired, current []interface{}) []interface{} {
+	config := []interface{}{}
+	for _, desiredItem := range desired {
+		desiredItemMap, ok := desiredItem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := map[string]interface{}{}
+		for key, value := range desiredItemMap {
+			if key != "state" {
+				item[key] = value
+			}
+		}
+		currentItem := diff.FindItem(item, current)
+		if currentItem != nil {
+			config = append(config, currentItem)
+		} else if desiredItemMap["state"] != absentState {
+			item["state"] = absentState
+			config = append(config, item)
+		}
+	}
+	return config
+}
+
+func list(value interface{}) []interface{} {
+	valueList, _ := value.([]interface{})
+	return valueList
+}
+
+func config(section interface{}) interface{} {
+	sectionMap, ok := section.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return sectionMap["config"]
+}
@@ -0,0 +1,196 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollback
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("State before apply", func() {
+	currentState := shared.NewState(`
+dns-resolver:
+  config:
+    server:
+    - 192.168.1.1
+  running:
+    server:
+    - 192.168.1.1
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+  ipv4:
+    enabled: true
+    dhcp: true
+- name: eth2
+  type: ethernet
+  state: up
+- name: eth3
+  type: ethernet
+  state: up
+routes:
+  config:
+  - destination: 10.0.0.0/8
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+    table-id: 254
+  running: []
+route-rules:
+  config: []
+`)
+	type stateBeforeApplyCase struct {
+		desiredState  string
+		expectedState string
+	}
+	DescribeTable("when capturing it",
+		func(c stateBeforeApplyCase) {
+			stateBeforeApply, err := StateBeforeApply(shared.NewState(c.desiredState), currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(stateBeforeApply.Raw)).To(MatchYAML(c.expectedState))
+		},
+		Entry("with a modified interface, should keep its current config",
+			stateBeforeApplyCase{
+				desiredState: `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+`,
+				expectedState: `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+  ipv4:
+    enabled: true
+    dhcp: true
+`,
+			}),
+		Entry("with a new bridge, should mark it absent and keep its ports current config",
+			stateBeforeApplyCase{
+				desiredState: `
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  bridge:
+    port:
+    - name: eth2
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth3
+    - eth4
+`,
+				expectedState: `
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: absent
+- name: eth2
+  type: ethernet
+  state: up
+- name: bond0
+  type: bond
+  state: absent
+- name: eth3
+  type: ethernet
+  state: up
+`,
+			}),
+		Entry("with a removed interface, should keep its current config",
+			stateBeforeApplyCase{
+				desiredState: `
+interfaces:
+- name: eth3
+  state: absent
+`,
+				expectedState: `
+interfaces:
+- name: eth3
+  type: ethernet
+  state: up
+`,
+			}),
+		Entry("with routes, should mark the new ones absent and keep the removed ones",
+			stateBeforeApplyCase{
+				desiredState: `
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth1
+    state: absent
+`,
+				expectedState: `
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+    state: absent
+  - destination: 10.0.0.0/8
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+    table-id: 254
+`,
+			}),
+		Entry("with route rules, should mark the new ones absent",
+			stateBeforeApplyCase{
+				desiredState: `
+route-rules:
+  config:
+  - ip-to: 10.0.0.0/8
+    route-table: 100
+`,
+				expectedState: `
+route-rules:
+  config:
+  - ip-to: 10.0.0.0/8
+    route-table: 100
+    state: absent
+`,
+			}),
+		Entry("with dns config, should keep the current one",
+			stateBeforeApplyCase{
+				desiredState: `
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+`,
+				expectedState: `
+dns-resolver:
+  config:
+    server:
+    - 192.168.1.1
+`,
+			}),
+	)
+})
@@ -0,0 +1,292 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+)
+
+const RemainingWaveName = "remaining"
+
+// Wave contains the nodes applying the policy together
+type Wave struct {
+	Name  string
+	Nodes []string
+	// StartTime is when the first node of the wave started applying the policy
+	StartTime *metav1.Time
+	// AvailableTime is when all the nodes of the wave were first seen available
+	AvailableTime *metav1.Time
+}
+
+// Gate tells if a node can start applying a policy with a rollout strategy
+type Gate struct {
+	Waves []Wave
+	// Wave is the index of the node wave
+	Wave int
+	// Open is true when the previous waves are available and soaked
+	Open bool
+	// Paused is true when a node from previous waves failed applying the policy
+	Paused       bool
+	Message      string
+	RequeueAfter time.Duration
+	// policyGeneration is the policy generation the waves are planned for
+	policyGeneration int64
+	// current is the index of the wave being applied, or soaked, when the
+	// gate is closed waiting for it
+	current int
+}
+
+// Status returns the policy rollout status for the node wave, the node wave
+// is started if the gate is open. The plan and the times already stored at
+// current for the policy generation are kept, so the handlers updating it at
+// the same time do not overwrite each other.
+func (g *Gate) Status(current *nmstateapi.RolloutStatus) *nmstateapi.RolloutStatus {
+	if len(g.Waves) == 0 {
+		return nil
+	}
+	if g.Open && g.Waves[g.Wave].StartTime == nil {
+		g.Waves[g.Wave].StartTime = &metav1.Time{Time: time.Now()}
+	}
+	plan := []nmstateapi.RolloutWaveStatus{}
+	for _, wave := range g.Waves {
+		plan = append(plan, nmstateapi.RolloutWaveStatus{
+			Name:          wave.Name,
+			Nodes:         wave.Nodes,
+			StartTime:     wave.StartTime,
+			AvailableTime: wave.AvailableTime,
+		})
+	}
+	if current != nil && current.PolicyGeneration == g.policyGeneration && len(current.Plan) == len(plan) {
+		currentPlan := current.DeepCopy().Plan
+		for i := range currentPlan {
+			if currentPlan[i].StartTime == nil {
+				currentPlan[i].StartTime = plan[i].StartTime
+			}
+			if currentPlan[i].AvailableTime == nil {
+				currentPlan[i].AvailableTime = plan[i].AvailableTime
+			}
+		}
+		plan = currentPlan
+	}
+	return &nmstateapi.RolloutStatus{
+		CurrentWave:      g.Waves[g.current].Name,
+		CurrentWaveIndex: g.current,
+		Waves:            len(g.Waves),
+		Paused:           g.Paused,
+		PolicyGeneration: g.policyGeneration,
+		Plan:             plan,
+	}
+}
+
+// Evaluate calculates the policy rollout waves, or takes the ones planned
+// for the policy generation at its status, and checks if the previous waves
+// to the one from nodeName are done.
+func Evaluate(cli client.Reader, policy *nmstatev1.NodeNetworkConfigurationPolicy, nodeName string) (*Gate, error) {
+	enactments := nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}
+	err := cli.List(context.TODO(), &enactments, client.MatchingLabels{nmstateapi.EnactmentPolicyLabel: policy.Name})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed listing policy enactments")
+	}
+	enactmentByNode := map[string]*nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	for i := range enactments.Items {
+		enactmentByNode[enactments.Items[i].Labels[nmstateapi.EnactmentNodeLabel]] = &enactments.Items[i]
+	}
+
+	gate := &Gate{Waves: plannedWaves(policy), Open: true, policyGeneration: policy.Generation}
+	if len(gate.Waves) == 0 {
+		// The waves are planned from all the nodes selected by the policy and
+		// not only the ones that already have an enactment, the handlers create
+		// them at the same time so each one would see a different set of nodes
+		// and plan different waves. Nodes without handler are left out since
+		// they never apply the policy.
+		policyNodes, err := node.NodesRunningNmstate(cli, policy.Spec.NodeSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed listing policy nodes")
+		}
+		gate.Waves = PlanWaves(policy.Spec.Rollout, policyNodes)
+	}
+	gate.Wave = waveOf(gate.Waves, nodeName)
+	gate.current = gate.Wave
+	for i := 0; i < gate.Wave; i++ {
+		wave := gate.Waves[i]
+		for _, waveNodeName := range wave.Nodes {
+			enactment := enactmentByNode[waveNodeName]
+			if isFailed(enactment, policy.Generation) {
+				gate.Open = false
+				gate.Paused = true
+				gate.Message = fmt.Sprintf("Rollout paused, wave %s failed at node %s", wave.Name, waveNodeName)
+				return gate, nil
+			}
+			if !isAvailable(enactment, policy.Generation) {
+				if gate.Open {
+					gate.current = i
+				}
+				gate.Open = false
+				gate.Message = fmt.Sprintf("Waiting for rollout wave %s to be available", wave.Name)
+			}
+		}
+	}
+	if !gate.Open || gate.Wave == 0 {
+		return gate, nil
+	}
+
+	soakDuration := time.Duration(0)
+	if policy.Spec.Rollout.SoakDuration != nil {
+		soakDuration = policy.Spec.Rollout.SoakDuration.Duration
+	}
+	previousWave := &gate.Waves[gate.Wave-1]
+	if previousWave.AvailableTime == nil {
+		previousWave.AvailableTime = &metav1.Time{Time: availableSince(*previousWave, enactmentByNode)}
+	}
+	soakLeft := soakDuration - time.Since(previousWave.AvailableTime.Time)
+	if soakLeft > 0 {
+		gate.current = gate.Wave - 1
+		gate.Open = false
+		gate.Message = fmt.Sprintf("Waiting for rollout wave %s to soak for %s", previousWave.Name, soakDuration)
+		gate.RequeueAfter = soakLeft
+	}
+	return gate, nil
+}
+
+// PlanWaves splits the nodes between the rollout strategy waves, each wave
+// takes, in name order, the nodes not taken by previous waves that match its
+// node selector, up to its number of nodes. Nodes not taken by any wave are
+// part of a last wave.
+func PlanWaves(strategy *nmstateapi.RolloutStrategy, nodes []corev1.Node) []Wave {
+	sortedNodes := make([]corev1.Node, len(nodes))
+	copy(sortedNodes, nodes)
+	sort.Slice(sortedNodes, func(i, j int) bool { return sortedNodes[i].Name < sortedNodes[j].Name })
+
+	waves := []Wave{}
+	taken := map[string]bool{}
+	for i, waveSpec := range strategy.Waves {
+		wave := Wave{Name: waveSpec.Name, Nodes: []string{}}
+		if wave.Name == "" {
+			wave.Name = fmt.Sprintf("wave-%d", i)
+		}
+		maxNodes := len(sortedNodes)
+		if waveSpec.Nodes != nil {
+			scaledNodes, err := intstr.GetScaledValueFromIntOrPercent(waveSpec.Nodes, len(sortedNodes), true)
+			if err == nil {
+				maxNodes = scaledNodes
+			}
+		}
+		selector := labels.SelectorFromSet(waveSpec.NodeSelector)
+		for j := range sortedNodes {
+			if len(wave.Nodes) == maxNodes {
+				break
+			}
+			if taken[sortedNodes[j].Name] || !selector.Matches(labels.Set(sortedNodes[j].Labels)) {
+				continue
+			}
+			taken[sortedNodes[j].Name] = true
+			wave.Nodes = append(wave.Nodes, sortedNodes[j].Name)
+		}
+		waves = append(waves, wave)
+	}
+
+	remaining := Wave{Name: RemainingWaveName, Nodes: []string{}}
+	for i := range sortedNodes {
+		if !taken[sortedNodes[i].Name] {
+			remaining.Nodes = append(remaining.Nodes, sortedNodes[i].Name)
+		}
+	}
+	if len(remaining.Nodes) > 0 {
+		waves = append(waves, remaining)
+	}
+	return waves
+}
+
+// plannedWaves returns the waves stored at the policy status if they were
+// planned for its current generation.
+func plannedWaves(policy *nmstatev1.NodeNetworkConfigurationPolicy) []Wave {
+	rolloutStatus := policy.Status.Rollout
+	if rolloutStatus == nil || rolloutStatus.PolicyGeneration != policy.Generation {
+		return nil
+	}
+	waves := []Wave{}
+	for _, wave := range rolloutStatus.DeepCopy().Plan {
+		waves = append(waves, Wave{
+			Name:          wave.Name,
+			Nodes:         wave.Nodes,
+			StartTime:     wave.StartTime,
+			AvailableTime: wave.AvailableTime,
+		})
+	}
+	return waves
+}
+
+// waveOf returns the index of the wave the node is at, nodes that joined
+// after planning the waves are applied with the last one.
+func waveOf(waves []Wave, nodeName string) int {
+	if len(waves) == 0 {
+		return 0
+	}
+	for i, wave := range waves {
+		for _, waveNodeName := range wave.Nodes {
+			if waveNodeName == nodeName {
+				return i
+			}
+		}
+	}
+	return len(waves) - 1
+}
+
+func isFailed(enactment *nmstatev1beta1.NodeNetworkConfigurationEnactment, generation int64) bool {
+	if enactment == nil || enactment.Status.PolicyGeneration != generation {
+		return false
+	}
+	condition := enactment.Status.Conditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionFailing)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+func isAvailable(enactment *nmstatev1beta1.NodeNetworkConfigurationEnactment, generation int64) bool {
+	if enactment == nil || enactment.Status.PolicyGeneration != generation {
+		return false
+	}
+	return enactmentstatus.IsAvailable(&enactment.Status.Conditions)
+}
+
+// availableSince returns when the last node of the wave became available
+func availableSince(wave Wave, enactmentByNode map[string]*nmstatev1beta1.NodeNetworkConfigurationEnactment) time.Time {
+	since := time.Time{}
+	for _, waveNodeName := range wave.Nodes {
+		condition := enactmentByNode[waveNodeName].Status.Conditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionAvailable)
+		if condition.LastTransitionTime.Time.After(since) {
+			since = condition.LastTransitionTime.Time
+		}
+	}
+	return since
+}
@@ -0,0 +1,410 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+)
+
+func newNode(name string, nodeLabels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: nodeLabels,
+		},
+	}
+}
+
+func newHandlerPod(nodeName string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nmstate-handler-" + nodeName,
+			Namespace: "nmstate",
+			Labels:    map[string]string{"component": "kubernetes-nmstate-handler"},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func intOrStringPtr(intOrString intstr.IntOrString) *intstr.IntOrString {
+	return &intOrString
+}
+
+var _ = Describe("Rollout waves", func() {
+	nodes := []corev1.Node{
+		newNode("node04", nil),
+		newNode("node03", map[string]string{"rack": "r2"}),
+		newNode("node02", map[string]string{"rack": "r1"}),
+		newNode("node01", map[string]string{"rack": "r1"}),
+	}
+	type planWavesCase struct {
+		strategy      nmstateapi.RolloutStrategy
+		expectedWaves []Wave
+	}
+	DescribeTable("when planning waves",
+		func(c planWavesCase) {
+			Expect(PlanWaves(&c.strategy, nodes)).To(Equal(c.expectedWaves))
+		},
+		Entry("with a count, should take the nodes in name order",
+			planWavesCase{
+				strategy: nmstateapi.RolloutStrategy{
+					Waves: []nmstateapi.RolloutWave{{Name: "canary", Nodes: intOrStringPtr(intstr.FromInt(1))}},
+				},
+				expectedWaves: []Wave{
+					{Name: "canary", Nodes: []string{"node01"}},
+					{Name: RemainingWaveName, Nodes: []string{"node02", "node03", "node04"}},
+				},
+			}),
+		Entry("with a percentage, should scale it to the number of nodes",
+			planWavesCase{
+				strategy: nmstateapi.RolloutStrategy{
+					Waves: []nmstateapi.RolloutWave{
+						{Nodes: intOrStringPtr(intstr.FromString("25%"))},
+						{Nodes: intOrStringPtr(intstr.FromString("50%"))},
+					},
+				},
+				expectedWaves: []Wave{
+					{Name: "wave-0", Nodes: []string{"node01"}},
+					{Name: "wave-1", Nodes: []string{"node02", "node03"}},
+					{Name: RemainingWaveName, Nodes: []string{"node04"}},
+				},
+			}),
+		Entry("with node selectors, should skip nodes taken by previous waves",
+			planWavesCase{
+				strategy: nmstateapi.RolloutStrategy{
+					Waves: []nmstateapi.RolloutWave{
+						{Name: "canary", Nodes: intOrStringPtr(intstr.FromInt(1))},
+						{Name: "rack-r1", NodeSelector: map[string]string{"rack": "r1"}},
+						{Name: "rack-r2", NodeSelector: map[string]string{"rack": "r2"}},
+					},
+				},
+				expectedWaves: []Wave{
+					{Name: "canary", Nodes: []string{"node01"}},
+					{Name: "rack-r1", Nodes: []string{"node02"}},
+					{Name: "rack-r2", Nodes: []string{"node03"}},
+					{Name: RemainingWaveName, Nodes: []string{"node04"}},
+				},
+			}),
+		Entry("with waves taking all the nodes, should not add the remaining wave",
+			planWavesCase{
+				strategy: nmstateapi.RolloutStrategy{
+					Waves: []nmstateapi.RolloutWave{
+						{Name: "first", Nodes: intOrStringPtr(intstr.FromInt(1))},
+						{Name: "rest", Nodes: intOrStringPtr(intstr.FromString("100%"))},
+					},
+				},
+				expectedWaves: []Wave{
+					{Name: "first", Nodes: []string{"node01"}},
+					{Name: "rest", Nodes: []string{"node02", "node03", "node04"}},
+				},
+			}),
+	)
+})
+
+var _ = Describe("Rollout gate", func() {
+	const policyGeneration = 2
+	var (
+		policy     nmstatev1.NodeNetworkConfigurationPolicy
+		enactments []runtime.Object
+	)
+	enactment := func(nodeName string, generation int64, setConditions func(*nmstateapi.ConditionList, string)) runtime.Object {
+		nnce := nmstatev1beta1.NewEnactment(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}, &policy)
+		nnce.Status.PolicyGeneration = generation
+		setConditions(&nnce.Status.Conditions, "")
+		return &nnce
+	}
+	evaluate := func(nodeName string) *Gate {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+		)
+		node01 := newNode("node01", nil)
+		node02 := newNode("node02", nil)
+		handler01 := newHandlerPod("node01")
+		handler02 := newHandlerPod("node02")
+		objs := append([]runtime.Object{&node01, &node02, &handler01, &handler02}, enactments...)
+		cli := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+		gate, err := Evaluate(cli, &policy, nodeName)
+		Expect(err).ToNot(HaveOccurred())
+		return gate
+	}
+	BeforeEach(func() {
+		policy = nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "policy1",
+				Generation: policyGeneration,
+			},
+			Spec: nmstateapi.NodeNetworkConfigurationPolicySpec{
+				Rollout: &nmstateapi.RolloutStrategy{
+					Waves: []nmstateapi.RolloutWave{{Name: "canary", Nodes: intOrStringPtr(intstr.FromInt(1))}},
+				},
+			},
+		}
+		enactments = []runtime.Object{}
+	})
+	Context("when node is at the first wave", func() {
+		BeforeEach(func() {
+			enactments = append(enactments,
+				enactment("node01", policyGeneration, conditions.SetPending),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should be open", func() {
+			gate := evaluate("node01")
+			Expect(gate.Open).To(BeTrue())
+			status := gate.Status(nil)
+			Expect(status.CurrentWave).To(Equal("canary"))
+			Expect(status.CurrentWaveIndex).To(Equal(0))
+			Expect(status.Waves).To(Equal(2))
+			Expect(status.PolicyGeneration).To(Equal(int64(policyGeneration)))
+			Expect(status.Plan).To(HaveLen(2))
+			Expect(status.Plan[0].Nodes).To(Equal([]string{"node01"}))
+			Expect(status.Plan[0].StartTime).ToNot(BeNil())
+			Expect(status.Plan[1].Nodes).To(Equal([]string{"node02"}))
+			Expect(status.Plan[1].StartTime).To(BeNil())
+		})
+		It("should keep the times already stored for the policy generation", func() {
+			startTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			current := &nmstateapi.RolloutStatus{
+				PolicyGeneration: policyGeneration,
+				Plan: []nmstateapi.RolloutWaveStatus{
+					{Name: "canary", Nodes: []string{"node01"}, StartTime: &startTime},
+					{Name: RemainingWaveName, Nodes: []string{"node02"}},
+				},
+			}
+			status := evaluate("node01").Status(current)
+			Expect(status.Plan[0].StartTime).To(Equal(&startTime))
+		})
+	})
+	Context("when the waves have been planned for the policy generation", func() {
+		BeforeEach(func() {
+			policy.Status.Rollout = &nmstateapi.RolloutStatus{
+				PolicyGeneration: policyGeneration,
+				Plan: []nmstateapi.RolloutWaveStatus{
+					{Name: "canary", Nodes: []string{"node02"}},
+					{Name: RemainingWaveName, Nodes: []string{"node01"}},
+				},
+			}
+			enactments = append(enactments,
+				enactment("node01", policyGeneration, conditions.SetPending),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should not plan them again", func() {
+			Expect(evaluate("node02").Open).To(BeTrue())
+			gate := evaluate("node01")
+			Expect(gate.Open).To(BeFalse())
+			Expect(gate.Message).To(Equal("Waiting for rollout wave canary to be available"))
+		})
+		It("should apply the nodes joining later with the last wave", func() {
+			gate := evaluate("node03")
+			Expect(gate.Wave).To(Equal(1))
+			Expect(gate.Open).To(BeFalse())
+		})
+		Context("for a previous generation", func() {
+			BeforeEach(func() {
+				policy.Status.Rollout.PolicyGeneration = policyGeneration - 1
+			})
+			It("should plan them again", func() {
+				Expect(evaluate("node01").Open).To(BeTrue())
+				Expect(evaluate("node02").Open).To(BeFalse())
+			})
+		})
+	})
+	Context("when previous wave is still progressing", func() {
+		BeforeEach(func() {
+			enactments = append(enactments,
+				enactment("node01", policyGeneration, conditions.SetProgressing),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should wait for it", func() {
+			gate := evaluate("node02")
+			Expect(gate.Open).To(BeFalse())
+			Expect(gate.Paused).To(BeFalse())
+			Expect(gate.Message).To(Equal("Waiting for rollout wave canary to be available"))
+			Expect(gate.Status(nil).CurrentWave).To(Equal("canary"))
+		})
+	})
+	Context("when previous wave is available for a previous generation", func() {
+		BeforeEach(func() {
+			enactments = append(enactments,
+				enactment("node01", policyGeneration-1, conditions.SetSuccess),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should wait for it", func() {
+			Expect(evaluate("node02").Open).To(BeFalse())
+		})
+	})
+	Context("when previous wave has only been dry-run", func() {
+		BeforeEach(func() {
+			enactments = append(enactments,
+				enactment("node01", policyGeneration, conditions.SetDryRunSuccess),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should wait for it", func() {
+			Expect(evaluate("node02").Open).To(BeFalse())
+		})
+	})
+	Context("when previous wave has failed", func() {
+		BeforeEach(func() {
+			enactments = append(enactments,
+				enactment("node01", policyGeneration, conditions.SetFailedToConfigure),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should pause the rollout", func() {
+			gate := evaluate("node02")
+			Expect(gate.Open).To(BeFalse())
+			Expect(gate.Paused).To(BeTrue())
+			Expect(gate.Message).To(Equal("Rollout paused, wave canary failed at node node01"))
+			Expect(gate.Status(nil)).To(Equal(&nmstateapi.RolloutStatus{
+				CurrentWave:      RemainingWaveName,
+				CurrentWaveIndex: 1,
+				Waves:            2,
+				Paused:           true,
+				PolicyGeneration: policyGeneration,
+				Plan: []nmstateapi.RolloutWaveStatus{
+					{Name: "canary", Nodes: []string{"node01"}},
+					{Name: RemainingWaveName, Nodes: []string{"node02"}},
+				},
+			}))
+		})
+	})
+	Context("when previous wave is available", func() {
+		BeforeEach(func() {
+			enactments = append(enactments,
+				enactment("node01", policyGeneration, conditions.SetSuccess),
+				enactment("node02", policyGeneration, conditions.SetPending),
+			)
+		})
+		It("should be open", func() {
+			Expect(evaluate("node02").Open).To(BeTrue())
+		})
+		Context("and soak duration has not passed", func() {
+			BeforeEach(func() {
+				policy.Spec.Rollout.SoakDuration = &metav1.Duration{Duration: time.Hour}
+			})
+			It("should wait for it", func() {
+				gate := evaluate("node02")
+				Expect(gate.Open).To(BeFalse())
+				Expect(gate.Message).To(Equal("Waiting for rollout wave canary to soak for 1h0m0s"))
+				Expect(gate.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+				status := gate.Status(nil)
+				Expect(status.CurrentWave).To(Equal("canary"))
+				Expect(status.Plan[0].AvailableTime).ToNot(BeNil())
+			})
+			Context("since the wave was first seen available", func() {
+				BeforeEach(func() {
+					availableTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+					policy.Status.Rollout = &nmstateapi.RolloutStatus{
+						PolicyGeneration: policyGeneration,
+						Plan: []nmstateapi.RolloutWaveStatus{
+							{Name: "canary", Nodes: []string{"node01"}, AvailableTime: &availableTime},
+							{Name: RemainingWaveName, Nodes: []string{"node02"}},
+						},
+					}
+				})
+				It("should not soak it again after the wave nodes reconcile", func() {
+					Expect(evaluate("node02").Open).To(BeTrue())
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("Rollout gate for a new policy", func() {
+	It("should plan the same waves at every handler evaluating it at once", func() {
+		policy := nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1", Generation: 1},
+			Spec: nmstateapi.NodeNetworkConfigurationPolicySpec{
+				Rollout: &nmstateapi.RolloutStrategy{
+					Waves: []nmstateapi.RolloutWave{{Name: "canary", Nodes: intOrStringPtr(intstr.FromInt(1))}},
+				},
+			},
+		}
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+		)
+		nodeNames := []string{"node03", "node01", "node02"}
+		objs := []runtime.Object{}
+		for _, nodeName := range nodeNames {
+			n := newNode(nodeName, nil)
+			pod := newHandlerPod(nodeName)
+			objs = append(objs, &n, &pod)
+		}
+		// node04 has no handler so it never applies the policy
+		node04 := newNode("node04", nil)
+		objs = append(objs, &node04)
+		cli := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+
+		// Every handler creates its enactment and evaluates the gate before
+		// the enactments of the others exist
+		gates := make([]*Gate, len(nodeNames))
+		done := make(chan struct{})
+		for i, nodeName := range nodeNames {
+			go func(i int, nodeName string) {
+				defer GinkgoRecover()
+				defer func() { done <- struct{}{} }()
+				node := newNode(nodeName, nil)
+				nnce := nmstatev1beta1.NewEnactment(&node, &policy)
+				Expect(cli.Create(context.TODO(), &nnce)).To(Succeed())
+				gate, err := Evaluate(cli, &policy, nodeName)
+				Expect(err).ToNot(HaveOccurred())
+				gates[i] = gate
+			}(i, nodeName)
+		}
+		for range nodeNames {
+			<-done
+		}
+
+		expectedWaves := []Wave{
+			{Name: "canary", Nodes: []string{"node01"}},
+			{Name: RemainingWaveName, Nodes: []string{"node02", "node03"}},
+		}
+		openGates := 0
+		for _, gate := range gates {
+			Expect(gate.Waves).To(Equal(expectedWaves))
+			if gate.Open {
+				openGates++
+			}
+		}
+		Expect(openGates).To(Equal(1), "only the canary node should start applying the policy")
+	})
+})
@@ -0,0 +1,178 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const mainRouteTableID = "254"
+
+// Conflict is a setting configured with a different value by two states
+type Conflict struct {
+	Err *field.Error
+	// Removal is true if one of the states removes the setting, like an
+	// absent interface or route or an empty dns configuration, so the
+	// states are likely meant to be applied one after the other.
+	Removal bool
+}
+
+// Conflicts returns the settings of state that other configures with a
+// different value, like an interface that is up at one and absent at the
+// other or a route with a different next hop. The settings only configured
+// by one of the states are not conflicts since nmstate merges them.
+func Conflicts(state, other *State, otherName string, fldPath *field.Path) []Conflict {
+	conflicts := []Conflict{}
+	for i := range state.Interfaces {
+		iface := &state.Interfaces[i]
+		otherIface := other.conflictingInterface(iface)
+		if otherIface == nil {
+			continue
+		}
+		removal := iface.State == InterfaceStateAbsent || otherIface.State == InterfaceStateAbsent
+		for _, err := range conflictingFields(iface, otherIface, otherName, fldPath.Child("interfaces").Index(i)) {
+			conflicts = append(conflicts, Conflict{Err: err, Removal: removal})
+		}
+	}
+	if state.Routes != nil && other.Routes != nil {
+		for i := range state.Routes.Config {
+			route := &state.Routes.Config[i]
+			otherRoute := findRoute(other.Routes.Config, route)
+			if otherRoute == nil {
+				continue
+			}
+			if err := conflict(route, otherRoute, otherName, fldPath.Child("routes", "config").Index(i)); err != nil {
+				conflicts = append(conflicts, Conflict{Err: err, Removal: route.State == "absent" || otherRoute.State == "absent"})
+			}
+		}
+	}
+	if state.DNSResolver != nil && state.DNSResolver.Config != nil && other.DNSResolver != nil && other.DNSResolver.Config != nil {
+		// nmstate replaces the whole dns configuration
+		dnsConfig, otherDNSConfig := state.DNSResolver.Config, other.DNSResolver.Config
+		if err := conflict(dnsConfig, otherDNSConfig, otherName, fldPath.Child("dns-resolver", "config")); err != nil {
+			conflicts = append(conflicts, Conflict{Err: err, Removal: dnsConfig.isEmpty() || otherDNSConfig.isEmpty()})
+		}
+	}
+	return conflicts
+}
+
+// conflictingInterface returns the interface of s with the same name as
+// iface, an ovs-bridge and an ovs-interface can share the name without
+// conflicting.
+func (s *State) conflictingInterface(iface *Interface) *Interface {
+	for i := range s.Interfaces {
+		other := &s.Interfaces[i]
+		if other.Name != iface.Name {
+			continue
+		}
+		if !isDuplicated(iface, []InterfaceType{other.Type}) {
+			continue
+		}
+		return other
+	}
+	return nil
+}
+
+func conflictingFields(iface, other *Interface, otherName string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fields, err := toFields(iface)
+	if err != nil {
+		return allErrs
+	}
+	otherFields, err := toFields(other)
+	if err != nil {
+		return allErrs
+	}
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		otherValue, found := otherFields[name]
+		if !found || reflect.DeepEqual(fields[name], otherValue) {
+			continue
+		}
+		allErrs = append(allErrs, conflictError(fldPath.Child(name), fields[name], otherValue, otherName))
+	}
+	return allErrs
+}
+
+// findRoute returns the route of routes with the same destination, next
+// hop interface and table as route.
+func findRoute(routes []Route, route *Route) *Route {
+	for i := range routes {
+		other := &routes[i]
+		if other.Destination == route.Destination &&
+			other.NextHopInterface == route.NextHopInterface &&
+			routeTable(other) == routeTable(route) {
+			return other
+		}
+	}
+	return nil
+}
+
+func routeTable(route *Route) string {
+	if route.TableID == nil {
+		return mainRouteTableID
+	}
+	return route.TableID.String()
+}
+
+func conflict(value, other interface{}, otherName string, fldPath *field.Path) *field.Error {
+	fields, err := toFields(value)
+	if err != nil {
+		return nil
+	}
+	otherFields, err := toFields(other)
+	if err != nil || reflect.DeepEqual(fields, otherFields) {
+		return nil
+	}
+	return conflictError(fldPath, fields, otherFields, otherName)
+}
+
+func conflictError(fldPath *field.Path, value, otherValue interface{}, otherName string) *field.Error {
+	return field.Invalid(fldPath, jsonValue{value}, fmt.Sprintf("conflicts with %s setting it to %s", otherName, jsonValue{otherValue}))
+}
+
+// jsonValue shows the conflicting values as compact JSON at the errors
+type jsonValue struct {
+	value interface{}
+}
+
+func (v jsonValue) String() string {
+	encoded, err := json.Marshal(v.value)
+	if err != nil {
+		return fmt.Sprintf("%v", v.value)
+	}
+	return string(encoded)
+}
+
+func toFields(value interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	return fields, json.Unmarshal(encoded, &fields)
+}
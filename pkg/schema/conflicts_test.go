@@ -0,0 +1,158 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("nmstate schema conflicts", func() {
+	DescribeTable("Conflicts",
+		func(desiredState, otherDesiredState string, expectedErrors []string) {
+			state, err := FromState(shared.NewState(desiredState))
+			Expect(err).ToNot(HaveOccurred())
+			otherState, err := FromState(shared.NewState(otherDesiredState))
+			Expect(err).ToNot(HaveOccurred())
+			errs := []string{}
+			for _, conflict := range Conflicts(state, otherState, `policy "other"`, field.NewPath("desiredState")) {
+				errs = append(errs, conflict.Err.Error())
+			}
+			Expect(errs).To(ConsistOf(expectedErrors))
+		},
+		Entry("with different interfaces, routes and dns", `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth1
+    next-hop-address: 192.168.1.1
+`, `
+interfaces:
+- name: eth2
+  type: ethernet
+  state: up
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth2
+    next-hop-address: 192.168.2.1
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+`, []string{}),
+		Entry("with the same interface configuring different fields", `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+`, `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    dhcp: true
+`, []string{}),
+		Entry("with an ovs bridge and an ovs interface with the same name", `
+interfaces:
+- name: br-ex
+  type: ovs-bridge
+  state: up
+`, `
+interfaces:
+- name: br-ex
+  type: ovs-interface
+  state: down
+`, []string{}),
+		Entry("with the same interface configured differently", `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+  ipv4:
+    enabled: true
+    dhcp: true
+`, `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: absent
+  mtu: 9000
+  ipv4:
+    enabled: true
+    dhcp: false
+`, []string{
+			`desiredState.interfaces[0].ipv4: Invalid value: {"dhcp":true,"enabled":true}: ` +
+				`conflicts with policy "other" setting it to {"dhcp":false,"enabled":true}`,
+			`desiredState.interfaces[0].state: Invalid value: "up": conflicts with policy "other" setting it to "absent"`,
+		}),
+		Entry("with the same route to a different next hop", `
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth1
+    next-hop-address: 192.168.1.1
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth1
+    next-hop-address: 192.168.1.1
+    table-id: 100
+`, `
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth1
+    next-hop-address: 192.168.1.254
+    table-id: 254
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth1
+    next-hop-address: 192.168.1.254
+`, []string{
+			`desiredState.routes.config[0]: Invalid value: ` +
+				`{"destination":"0.0.0.0/0","next-hop-address":"192.168.1.1","next-hop-interface":"eth1"}: ` +
+				`conflicts with policy "other" setting it to ` +
+				`{"destination":"0.0.0.0/0","next-hop-address":"192.168.1.254","next-hop-interface":"eth1","table-id":254}`,
+		}),
+		Entry("with different dns config", `
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+`, `
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+    - 1.1.1.1
+`, []string{
+			`desiredState.dns-resolver.config: Invalid value: {"server":["8.8.8.8"]}: ` +
+				`conflicts with policy "other" setting it to {"server":["8.8.8.8","1.1.1.1"]}`,
+		}),
+	)
+})
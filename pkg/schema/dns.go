@@ -0,0 +1,58 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+type DNSResolver struct {
+	Config  *DNSConfig `json:"config,omitempty"`
+	Running *DNSConfig `json:"running,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (d *DNSResolver) UnmarshalJSON(b []byte) error {
+	type plain DNSResolver
+	return unmarshalWithExtra(b, (*plain)(d), &d.Extra)
+}
+
+func (d DNSResolver) MarshalJSON() ([]byte, error) {
+	type plain DNSResolver
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+type DNSConfig struct {
+	Search []string `json:"search"`
+	Server []string `json:"server"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (d *DNSConfig) UnmarshalJSON(b []byte) error {
+	type plain DNSConfig
+	return unmarshalWithExtra(b, (*plain)(d), &d.Extra)
+}
+
+func (d DNSConfig) MarshalJSON() ([]byte, error) {
+	type plain DNSConfig
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+// isEmpty returns true if the configuration cleans up the dns servers and
+// search domains
+func (d *DNSConfig) isEmpty() bool {
+	return len(d.Search) == 0 && len(d.Server) == 0
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// unmarshalWithExtra decodes b into known and keeps the fields that are not
+// part of it at extra. The known fields that would not be encoded back, like
+// an empty "next-hop-address", are kept at extra too.
+func unmarshalWithExtra(b []byte, known interface{}, extra *map[string]interface{}) error {
+	if err := json.Unmarshal(b, known); err != nil {
+		return err
+	}
+	fields := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	// Keep the numbers as they are, float64 would lose precision
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+	encoded, err := marshalWithExtra(known, nil)
+	if err != nil {
+		return err
+	}
+	encodedFields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(encoded, &encodedFields); err != nil {
+		return err
+	}
+	for _, name := range jsonFieldNames(known) {
+		if _, isEncoded := encodedFields[name]; isEncoded {
+			delete(fields, name)
+		}
+	}
+	*extra = nil
+	if len(fields) > 0 {
+		*extra = fields
+	}
+	return nil
+}
+
+// marshalWithExtra encodes known adding the fields at extra, known fields
+// take precedence over extra ones. The known fields that are nil are not
+// encoded while the empty ones are, since "port: []" and a missing port
+// list mean different things to nmstate.
+func marshalWithExtra(known interface{}, extra map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(known)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if string(value) == "null" {
+			delete(fields, name)
+		}
+	}
+	for name, value := range extra {
+		if _, isKnown := fields[name]; isKnown {
+			continue
+		}
+		rawValue, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = rawValue
+	}
+	return json.Marshal(fields)
+}
+
+func jsonFieldNames(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,281 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+type InterfaceType string
+
+const (
+	InterfaceTypeEthernet     InterfaceType = "ethernet"
+	InterfaceTypeBond         InterfaceType = "bond"
+	InterfaceTypeLinuxBridge  InterfaceType = "linux-bridge"
+	InterfaceTypeOVSBridge    InterfaceType = "ovs-bridge"
+	InterfaceTypeOVSInterface InterfaceType = "ovs-interface"
+	InterfaceTypeVlan         InterfaceType = "vlan"
+	InterfaceTypeVxlan        InterfaceType = "vxlan"
+	InterfaceTypeVeth         InterfaceType = "veth"
+	InterfaceTypeDummy        InterfaceType = "dummy"
+	InterfaceTypeLoopback     InterfaceType = "loopback"
+)
+
+type InterfaceState string
+
+const (
+	InterfaceStateUp     InterfaceState = "up"
+	InterfaceStateDown   InterfaceState = "down"
+	InterfaceStateAbsent InterfaceState = "absent"
+	InterfaceStateIgnore InterfaceState = "ignore"
+)
+
+// Interface has the fields common to all the interface types and the
+// sections specific to some of them, only the section matching Type is
+// expected to be set.
+type Interface struct {
+	Name        string         `json:"name"`
+	Type        InterfaceType  `json:"type,omitempty"`
+	State       InterfaceState `json:"state,omitempty"`
+	MTU         *Number        `json:"mtu,omitempty"`
+	MACAddress  string         `json:"mac-address,omitempty"`
+	Controller  string         `json:"controller,omitempty"`
+	Description string         `json:"description,omitempty"`
+	IPv4        *IP            `json:"ipv4,omitempty"`
+	IPv6        *IP            `json:"ipv6,omitempty"`
+
+	// Bridge is used by both linux-bridge and ovs-bridge interfaces
+	Bridge          *Bridge          `json:"bridge,omitempty"`
+	LinkAggregation *LinkAggregation `json:"link-aggregation,omitempty"`
+	Vlan            *Vlan            `json:"vlan,omitempty"`
+	Vxlan           *Vxlan           `json:"vxlan,omitempty"`
+	Veth            *Veth            `json:"veth,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (i *Interface) UnmarshalJSON(b []byte) error {
+	type plain Interface
+	return unmarshalWithExtra(b, (*plain)(i), &i.Extra)
+}
+
+func (i Interface) MarshalJSON() ([]byte, error) {
+	type plain Interface
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+type IP struct {
+	Enabled  *Bool       `json:"enabled,omitempty"`
+	DHCP     *Bool       `json:"dhcp,omitempty"`
+	Autoconf *Bool       `json:"autoconf,omitempty"`
+	Address  []IPAddress `json:"address"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (i *IP) UnmarshalJSON(b []byte) error {
+	type plain IP
+	return unmarshalWithExtra(b, (*plain)(i), &i.Extra)
+}
+
+func (i IP) MarshalJSON() ([]byte, error) {
+	type plain IP
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+type IPAddress struct {
+	IP           string  `json:"ip"`
+	PrefixLength *Number `json:"prefix-length,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (a *IPAddress) UnmarshalJSON(b []byte) error {
+	type plain IPAddress
+	return unmarshalWithExtra(b, (*plain)(a), &a.Extra)
+}
+
+func (a IPAddress) MarshalJSON() ([]byte, error) {
+	type plain IPAddress
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+type Bridge struct {
+	Options *BridgeOptions `json:"options,omitempty"`
+	Port    []BridgePort   `json:"port"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (br *Bridge) UnmarshalJSON(b []byte) error {
+	type plain Bridge
+	return unmarshalWithExtra(b, (*plain)(br), &br.Extra)
+}
+
+func (br Bridge) MarshalJSON() ([]byte, error) {
+	type plain Bridge
+	return marshalWithExtra(plain(br), br.Extra)
+}
+
+type BridgeOptions struct {
+	STP *BridgeSTP `json:"stp,omitempty"`
+	// GCTimer and HelloTimer are reported by linux-bridge interfaces, they
+	// change all the time.
+	GCTimer    *Number `json:"gc-timer,omitempty"`
+	HelloTimer *Number `json:"hello-timer,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (o *BridgeOptions) UnmarshalJSON(b []byte) error {
+	type plain BridgeOptions
+	return unmarshalWithExtra(b, (*plain)(o), &o.Extra)
+}
+
+func (o BridgeOptions) MarshalJSON() ([]byte, error) {
+	type plain BridgeOptions
+	return marshalWithExtra(plain(o), o.Extra)
+}
+
+type BridgeSTP struct {
+	Enabled *Bool `json:"enabled,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (s *BridgeSTP) UnmarshalJSON(b []byte) error {
+	type plain BridgeSTP
+	return unmarshalWithExtra(b, (*plain)(s), &s.Extra)
+}
+
+func (s BridgeSTP) MarshalJSON() ([]byte, error) {
+	type plain BridgeSTP
+	return marshalWithExtra(plain(s), s.Extra)
+}
+
+type BridgePort struct {
+	Name string          `json:"name"`
+	Vlan *BridgePortVlan `json:"vlan,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (p *BridgePort) UnmarshalJSON(b []byte) error {
+	type plain BridgePort
+	return unmarshalWithExtra(b, (*plain)(p), &p.Extra)
+}
+
+func (p BridgePort) MarshalJSON() ([]byte, error) {
+	type plain BridgePort
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+type BridgePortVlan struct {
+	Mode         string     `json:"mode,omitempty"`
+	Tag          *Number    `json:"tag,omitempty"`
+	EnableNative *Bool      `json:"enable-native,omitempty"`
+	TrunkTags    []TrunkTag `json:"trunk-tags"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (v *BridgePortVlan) UnmarshalJSON(b []byte) error {
+	type plain BridgePortVlan
+	return unmarshalWithExtra(b, (*plain)(v), &v.Extra)
+}
+
+func (v BridgePortVlan) MarshalJSON() ([]byte, error) {
+	type plain BridgePortVlan
+	return marshalWithExtra(plain(v), v.Extra)
+}
+
+type TrunkTag struct {
+	ID      *Number      `json:"id,omitempty"`
+	IDRange *VlanIDRange `json:"id-range,omitempty"`
+}
+
+type VlanIDRange struct {
+	Min *Number `json:"min,omitempty"`
+	Max *Number `json:"max,omitempty"`
+}
+
+type LinkAggregation struct {
+	Mode    string                 `json:"mode,omitempty"`
+	Port    []string               `json:"port"`
+	Options map[string]interface{} `json:"options"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (l *LinkAggregation) UnmarshalJSON(b []byte) error {
+	type plain LinkAggregation
+	return unmarshalWithExtra(b, (*plain)(l), &l.Extra)
+}
+
+func (l LinkAggregation) MarshalJSON() ([]byte, error) {
+	type plain LinkAggregation
+	return marshalWithExtra(plain(l), l.Extra)
+}
+
+type Vlan struct {
+	BaseIface string  `json:"base-iface,omitempty"`
+	ID        *Number `json:"id,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (v *Vlan) UnmarshalJSON(b []byte) error {
+	type plain Vlan
+	return unmarshalWithExtra(b, (*plain)(v), &v.Extra)
+}
+
+func (v Vlan) MarshalJSON() ([]byte, error) {
+	type plain Vlan
+	return marshalWithExtra(plain(v), v.Extra)
+}
+
+type Vxlan struct {
+	BaseIface       string  `json:"base-iface,omitempty"`
+	ID              *Number `json:"id,omitempty"`
+	Remote          string  `json:"remote,omitempty"`
+	DestinationPort *Number `json:"destination-port,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (v *Vxlan) UnmarshalJSON(b []byte) error {
+	type plain Vxlan
+	return unmarshalWithExtra(b, (*plain)(v), &v.Extra)
+}
+
+func (v Vxlan) MarshalJSON() ([]byte, error) {
+	type plain Vxlan
+	return marshalWithExtra(plain(v), v.Extra)
+}
+
+type Veth struct {
+	Peer string `json:"peer,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (v *Veth) UnmarshalJSON(b []byte) error {
+	type plain Veth
+	return unmarshalWithExtra(b, (*plain)(v), &v.Extra)
+}
+
+func (v Veth) MarshalJSON() ([]byte, error) {
+	type plain Veth
+	return marshalWithExtra(plain(v), v.Extra)
+}
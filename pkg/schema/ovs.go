@@ -0,0 +1,58 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+type OVSDB struct {
+	ExternalIDs map[string]interface{} `json:"external_ids"`
+	OtherConfig map[string]interface{} `json:"other_config"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (o *OVSDB) UnmarshalJSON(b []byte) error {
+	type plain OVSDB
+	return unmarshalWithExtra(b, (*plain)(o), &o.Extra)
+}
+
+func (o OVSDB) MarshalJSON() ([]byte, error) {
+	type plain OVSDB
+	return marshalWithExtra(plain(o), o.Extra)
+}
+
+type OVN struct {
+	BridgeMappings []BridgeMapping `json:"bridge-mappings"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (o *OVN) UnmarshalJSON(b []byte) error {
+	type plain OVN
+	return unmarshalWithExtra(b, (*plain)(o), &o.Extra)
+}
+
+func (o OVN) MarshalJSON() ([]byte, error) {
+	type plain OVN
+	return marshalWithExtra(plain(o), o.Extra)
+}
+
+// BridgeMapping maps an ovn localnet to an ovs bridge
+type BridgeMapping struct {
+	Name   string `json:"localnet"        yaml:"localnet"`
+	Bridge string `json:"bridge"          yaml:"bridge"`
+	State  string `json:"state,omitempty" yaml:"state,omitempty"`
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// referenceFields are the fields referencing other interfaces at the
+// sections that are not modeled, like the vrf and team ports, the mac-vlan,
+// ipvlan and macsec base interfaces or the hsr ports.
+var referenceFields = sets.New("base-iface", "port", "port1", "port2", "peer", "controller", "parent", "interlink")
+
+// InterfaceReference is an interface referenced by another one
+type InterfaceReference struct {
+	Name string
+	Path *field.Path
+}
+
+// References returns the interfaces the interface references as controller,
+// ports, base interface or peer, including the ones at the sections of the
+// types that are not modeled.
+func (i *Interface) References(fldPath *field.Path) []InterfaceReference {
+	references := []InterfaceReference{}
+	add := func(name string, path *field.Path) {
+		if name != "" {
+			references = append(references, InterfaceReference{Name: name, Path: path})
+		}
+	}
+	add(i.Controller, fldPath.Child("controller"))
+	if i.Bridge != nil {
+		for j := range i.Bridge.Port {
+			portPath := fldPath.Child("bridge", "port").Index(j)
+			add(i.Bridge.Port[j].Name, portPath.Child("name"))
+			references = append(references, extraReferences(i.Bridge.Port[j].Extra, portPath)...)
+		}
+		references = append(references, extraReferences(i.Bridge.Extra, fldPath.Child("bridge"))...)
+	}
+	if i.LinkAggregation != nil {
+		for j, port := range i.LinkAggregation.Port {
+			add(port, fldPath.Child("link-aggregation", "port").Index(j))
+		}
+	}
+	if i.Vlan != nil {
+		add(i.Vlan.BaseIface, fldPath.Child("vlan", "base-iface"))
+	}
+	if i.Vxlan != nil {
+		add(i.Vxlan.BaseIface, fldPath.Child("vxlan", "base-iface"))
+	}
+	if i.Veth != nil {
+		add(i.Veth.Peer, fldPath.Child("veth", "peer"))
+	}
+	return append(references, extraReferences(i.Extra, fldPath)...)
+}
+
+// extraReferences walks the fields that are not modeled looking for
+// interface names at the reference fields, as a string, a list of them or a
+// list of objects with a name.
+func extraReferences(extra map[string]interface{}, fldPath *field.Path) []InterfaceReference {
+	references := []InterfaceReference{}
+	keys := []string{}
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		keyPath := fldPath.Child(key)
+		if !referenceFields.Has(key) {
+			references = append(references, nestedReferences(extra[key], keyPath)...)
+			continue
+		}
+		switch value := extra[key].(type) {
+		case string:
+			references = append(references, InterfaceReference{Name: value, Path: keyPath})
+		case []interface{}:
+			for j, item := range value {
+				switch item := item.(type) {
+				case string:
+					references = append(references, InterfaceReference{Name: item, Path: keyPath.Index(j)})
+				case map[string]interface{}:
+					if name, ok := item["name"].(string); ok {
+						references = append(references, InterfaceReference{Name: name, Path: keyPath.Index(j).Child("name")})
+					}
+					references = append(references, extraReferences(item, keyPath.Index(j))...)
+				}
+			}
+		}
+	}
+	return references
+}
+
+func nestedReferences(value interface{}, fldPath *field.Path) []InterfaceReference {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return extraReferences(value, fldPath)
+	case []interface{}:
+		references := []InterfaceReference{}
+		for j := range value {
+			references = append(references, nestedReferences(value[j], fldPath.Index(j))...)
+		}
+		return references
+	}
+	return nil
+}
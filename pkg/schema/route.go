@@ -0,0 +1,92 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+type Routes struct {
+	Config  []Route `json:"config"`
+	Running []Route `json:"running"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (r *Routes) UnmarshalJSON(b []byte) error {
+	type plain Routes
+	return unmarshalWithExtra(b, (*plain)(r), &r.Extra)
+}
+
+func (r Routes) MarshalJSON() ([]byte, error) {
+	type plain Routes
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type Route struct {
+	Destination      string  `json:"destination,omitempty"`
+	NextHopAddress   string  `json:"next-hop-address,omitempty"`
+	NextHopInterface string  `json:"next-hop-interface,omitempty"`
+	Metric           *Number `json:"metric,omitempty"`
+	TableID          *Number `json:"table-id,omitempty"`
+	State            string  `json:"state,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (r *Route) UnmarshalJSON(b []byte) error {
+	type plain Route
+	return unmarshalWithExtra(b, (*plain)(r), &r.Extra)
+}
+
+func (r Route) MarshalJSON() ([]byte, error) {
+	type plain Route
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type RouteRules struct {
+	Config []RouteRule `json:"config"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (r *RouteRules) UnmarshalJSON(b []byte) error {
+	type plain RouteRules
+	return unmarshalWithExtra(b, (*plain)(r), &r.Extra)
+}
+
+func (r RouteRules) MarshalJSON() ([]byte, error) {
+	type plain RouteRules
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type RouteRule struct {
+	IPFrom     string  `json:"ip-from,omitempty"`
+	IPTo       string  `json:"ip-to,omitempty"`
+	Priority   *Number `json:"priority,omitempty"`
+	RouteTable *Number `json:"route-table,omitempty"`
+	State      string  `json:"state,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (r *RouteRule) UnmarshalJSON(b []byte) error {
+	type plain RouteRule
+	return unmarshalWithExtra(b, (*plain)(r), &r.Extra)
+}
+
+func (r RouteRule) MarshalJSON() ([]byte, error) {
+	type plain RouteRule
+	return marshalWithExtra(plain(r), r.Extra)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schema is a typed model of the nmstate API for the sections
+// kubernetes-nmstate inspects or modifies, interfaces, routes, route rules,
+// dns resolver, ovs-db and ovn. The fields not modeled are kept at Extra so
+// converting a state to the model and back does not lose anything.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// APIVersion is the version of the nmstate API modeled by this package
+const APIVersion = 2
+
+type State struct {
+	Interfaces  []Interface  `json:"interfaces"`
+	Routes      *Routes      `json:"routes,omitempty"`
+	RouteRules  *RouteRules  `json:"route-rules,omitempty"`
+	DNSResolver *DNSResolver `json:"dns-resolver,omitempty"`
+	OVSDB       *OVSDB       `json:"ovs-db,omitempty"`
+	OVN         *OVN         `json:"ovn,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (s *State) UnmarshalJSON(b []byte) error {
+	type plain State
+	return unmarshalWithExtra(b, (*plain)(s), &s.Extra)
+}
+
+func (s State) MarshalJSON() ([]byte, error) {
+	type plain State
+	return marshalWithExtra(plain(s), s.Extra)
+}
+
+// FromState decodes a shared.State into the typed model
+func FromState(state shared.State) (*State, error) {
+	stateJSON, err := yaml.YAMLToJSON(state.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed converting state to JSON: %w", err)
+	}
+	result := &State{}
+	if err := json.Unmarshal(stateJSON, result); err != nil {
+		return nil, fmt.Errorf("failed decoding state: %w", err)
+	}
+	return result, nil
+}
+
+// ToState encodes the typed model as a shared.State
+func (s *State) ToState() (shared.State, error) {
+	stateYAML, err := yaml.Marshal(s)
+	if err != nil {
+		return shared.State{}, fmt.Errorf("failed encoding state: %w", err)
+	}
+	return shared.NewState(string(stateYAML)), nil
+}
+
+// Interface returns the interface with name, or nil if it is not at the state
+func (s *State) Interface(name string) *Interface {
+	for i := range s.Interfaces {
+		if s.Interfaces[i].Name == name {
+			return &s.Interfaces[i]
+		}
+	}
+	return nil
+}
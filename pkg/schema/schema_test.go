@@ -0,0 +1,190 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("nmstate schema", func() {
+	const fullState = `
+dns-resolver:
+  config:
+    search: []
+    server:
+    - 8.8.8.8
+    options:
+    - rotate
+hostname:
+  running: node01
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+  mac-address: 02:42:BB:10:B8:9F
+  ethtool:
+    feature:
+      rx-checksum: true
+  ipv4:
+    enabled: true
+    dhcp: false
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+      valid-life-time: forever
+- name: br1
+  type: linux-bridge
+  state: up
+  bridge:
+    options:
+      gc-timer: 13715
+      group-addr: 01:80:C2:00:00:00
+      stp:
+        enabled: false
+        forward-delay: 15
+    port: []
+- name: eth1.100
+  type: vlan
+  state: up
+  vlan:
+    base-iface: eth1
+    id: 100
+ovn:
+  bridge-mappings:
+  - localnet: datanet
+    bridge: br-ex
+ovs-db:
+  external_ids:
+    hostname: node01
+route-rules:
+  config:
+  - ip-from: 192.168.1.0/24
+    route-table: 200
+    priority: 1000
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+    metric: 4294967295
+    table-id: 254
+    cwnd: 10
+`
+	It("should decode and encode a state without losing fields", func() {
+		state, err := FromState(shared.NewState(fullState))
+		Expect(err).ToNot(HaveOccurred())
+		encoded, err := state.ToState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encoded).To(MatchYAML(fullState))
+	})
+	It("should decode the modeled sections", func() {
+		state, err := FromState(shared.NewState(fullState))
+		Expect(err).ToNot(HaveOccurred())
+
+		eth1 := state.Interface("eth1")
+		Expect(eth1).ToNot(BeNil())
+		Expect(eth1.Type).To(Equal(InterfaceTypeEthernet))
+		Expect(eth1.MTU.Int64()).To(Equal(int64(1500)))
+		Expect(eth1.IPv4.Enabled.IsTrue()).To(BeTrue())
+		Expect(eth1.IPv4.DHCP.IsTrue()).To(BeFalse())
+		Expect(eth1.IPv4.Address).To(HaveLen(1))
+		Expect(eth1.Extra).To(HaveKey("ethtool"))
+
+		br1 := state.Interface("br1")
+		Expect(br1.Bridge.Port).ToNot(BeNil())
+		Expect(br1.Bridge.Port).To(BeEmpty())
+		Expect(br1.Bridge.Options.GCTimer.Int64()).To(Equal(int64(13715)))
+
+		Expect(state.Interface("eth1.100").Vlan.BaseIface).To(Equal("eth1"))
+		Expect(state.Interface("eth2")).To(BeNil())
+
+		Expect(state.Routes.Running[0].Metric.Int64()).To(Equal(int64(4294967295)))
+		Expect(state.RouteRules.Config[0].RouteTable.Int64()).To(Equal(int64(200)))
+		Expect(state.DNSResolver.Config.Server).To(ConsistOf("8.8.8.8"))
+		Expect(state.OVN.BridgeMappings).To(ConsistOf(BridgeMapping{Name: "datanet", Bridge: "br-ex"}))
+		Expect(state.OVSDB.ExternalIDs).To(HaveKeyWithValue("hostname", "node01"))
+		Expect(state.Extra).To(HaveKey("hostname"))
+	})
+	It("should encode the modifications", func() {
+		state, err := FromState(shared.NewState(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  bridge:
+    options:
+      gc-timer: 13715
+      hello-timer: 0
+`))
+		Expect(err).ToNot(HaveOccurred())
+		br1 := state.Interface("br1")
+		br1.Bridge.Options.GCTimer = nil
+		br1.Bridge.Options.HelloTimer = nil
+		br1.MTU = NewNumber(9000)
+		br1.State = InterfaceStateUp
+		encoded, err := state.ToState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encoded).To(MatchYAML(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  mtu: 9000
+  bridge:
+    options: {}
+`))
+	})
+	It("should keep the values nmstate accepts as strings", func() {
+		state, err := FromState(shared.NewState(`
+interfaces:
+- name: eth1
+  mtu: "1400"
+  description: ""
+  ipv6:
+    enabled: "false"
+`))
+		Expect(err).ToNot(HaveOccurred())
+		eth1 := state.Interface("eth1")
+		Expect(eth1.MTU.Int64()).To(Equal(int64(1400)))
+		Expect(eth1.IPv6.Enabled).To(Equal(NewBool(false)))
+		encoded, err := state.ToState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encoded).To(MatchYAML(`
+interfaces:
+- name: eth1
+  mtu: "1400"
+  description: ""
+  ipv6:
+    enabled: false
+`))
+	})
+	DescribeTable("should fail decoding states not matching the schema",
+		func(state, expectedError string) {
+			_, err := FromState(shared.NewState(state))
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("with a wrong boolean", "interfaces: [{name: eth1, ipv4: {dhcp: sometimes}}]", `expected a boolean, got "sometimes"`),
+		Entry("with a wrong number", "interfaces: [{name: eth1, mtu: [1500]}]", "expected a number"),
+		Entry("with interfaces not being a list", "interfaces: {name: eth1}", "cannot unmarshal object"),
+		Entry("with a bad yaml", "interfaces: [", "failed converting state to JSON"),
+	)
+})
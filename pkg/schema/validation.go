@@ -0,0 +1,527 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	maxInterfaceNameLength = 15
+	maxVlanID              = 4094
+	maxVxlanID             = 1<<24 - 1
+	maxPort                = 65535
+	maxListedNodes         = 5
+)
+
+var (
+	knownStateFields = sets.New("hostname", "description", "dispatch")
+
+	knownInterfaceFields = sets.New(
+		"min-mtu", "max-mtu", "permanent-mac-address", "copy-mac-from", "accept-all-mac-addresses",
+		"profile-name", "identifier", "driver", "index", "altnames", "wait-ip", "dispatch",
+		"mptcp", "lldp", "ethtool", "802.1x", "ieee-802-1x", "ovs-db", "dpdk", "patch",
+		"ethernet", "team", "vrf", "infiniband", "mac-vlan", "mac-vtap", "macsec", "ipvlan",
+		"hsr", "libreswan", "xfrm", "loopback", "controller-type", "prop-list",
+	)
+
+	knownInterfaceTypes = sets.New(
+		InterfaceTypeEthernet, InterfaceTypeBond, InterfaceTypeLinuxBridge, InterfaceTypeOVSBridge,
+		InterfaceTypeOVSInterface, InterfaceTypeVlan, InterfaceTypeVxlan, InterfaceTypeVeth,
+		InterfaceTypeDummy, InterfaceTypeLoopback, "team", "vrf", "infiniband", "mac-vlan", "mac-vtap",
+		"macsec", "ipsec", "hsr", "ipvlan", "xfrm", "unknown",
+	)
+
+	knownInterfaceStates = sets.New(InterfaceStateUp, InterfaceStateDown, InterfaceStateAbsent, InterfaceStateIgnore)
+
+	knownBondModes = sets.New("balance-rr", "active-backup", "balance-xor", "broadcast", "802.3ad", "balance-tlb", "balance-alb")
+
+	knownVlanModes = sets.New("trunk", "access")
+)
+
+// MissingInterfaceFunc returns the nodes missing an interface that is
+// referenced but not defined at the desired state.
+type MissingInterfaceFunc func(name string) []string
+
+// Validate checks the state against the nmstate schema, the interfaces
+// referenced by ports, controllers, vlans and routes have to be defined at
+// the state or, if missingAt is not nil, exist at the nodes.
+func Validate(state *State, fldPath *field.Path, missingAt MissingInterfaceFunc) field.ErrorList {
+	v := validator{
+		interfaces: map[string]*Interface{},
+		missingAt:  missingAt,
+	}
+	for i := range state.Interfaces {
+		if name := state.Interfaces[i].Name; name != "" {
+			v.interfaces[name] = &state.Interfaces[i]
+		}
+	}
+
+	allErrs := unknownFields(state.Extra, state, knownStateFields, fldPath)
+	allErrs = append(allErrs, v.validateInterfaces(state.Interfaces, fldPath.Child("interfaces"))...)
+	if state.Routes != nil {
+		allErrs = append(allErrs, v.validateRoutes(state.Routes.Config, fldPath.Child("routes", "config"))...)
+	}
+	if state.RouteRules != nil {
+		allErrs = append(allErrs, validateRouteRules(state.RouteRules.Config, fldPath.Child("route-rules", "config"))...)
+	}
+	if state.DNSResolver != nil && state.DNSResolver.Config != nil {
+		allErrs = append(allErrs, validateNameServers(state.DNSResolver.Config.Server, fldPath.Child("dns-resolver", "config", "server"))...)
+	}
+	return allErrs
+}
+
+type validator struct {
+	interfaces map[string]*Interface
+	missingAt  MissingInterfaceFunc
+}
+
+func (v *validator) validateInterfaces(interfaces []Interface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	typesByName := map[string][]InterfaceType{}
+	for i := range interfaces {
+		iface := &interfaces[i]
+		ifacePath := fldPath.Index(i)
+		allErrs = append(allErrs, validateInterfaceName(iface, ifacePath.Child("name"))...)
+		if isDuplicated(iface, typesByName[iface.Name]) {
+			allErrs = append(allErrs, field.Duplicate(ifacePath.Child("name"), iface.Name))
+		}
+		typesByName[iface.Name] = append(typesByName[iface.Name], iface.Type)
+		allErrs = append(allErrs, v.validateInterface(iface, ifacePath)...)
+	}
+	return allErrs
+}
+
+func validateInterfaceName(iface *Interface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch {
+	case iface.Name == "":
+		allErrs = append(allErrs, field.Required(fldPath, ""))
+	case iface.Name == "." || iface.Name == ".." || strings.ContainsAny(iface.Name, "/: \t\n"):
+		allErrs = append(allErrs, field.Invalid(fldPath, iface.Name, "must not be '.' or '..' nor contain '/', ':' or whitespaces"))
+	case len(iface.Name) > maxInterfaceNameLength && iface.Type != InterfaceTypeOVSBridge:
+		allErrs = append(allErrs, field.TooLong(fldPath, iface.Name, maxInterfaceNameLength))
+	}
+	return allErrs
+}
+
+// isDuplicated returns true if there is already an interface with the same
+// name and type, an ovs-bridge and its ovs-interface can share the name.
+func isDuplicated(iface *Interface, previousTypes []InterfaceType) bool {
+	for _, previousType := range previousTypes {
+		if iface.Type == "" || previousType == "" || iface.Type == previousType {
+			return true
+		}
+		isOVSPair := sets.New(iface.Type, previousType).Equal(sets.New(InterfaceTypeOVSBridge, InterfaceTypeOVSInterface))
+		if !isOVSPair {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateInterface(iface *Interface, fldPath *field.Path) field.ErrorList {
+	allErrs := unknownFields(iface.Extra, iface, knownInterfaceFields, fldPath)
+	if iface.Type != "" && !knownInterfaceTypes.Has(iface.Type) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), iface.Type, supportedValues(knownInterfaceTypes)))
+	}
+	if iface.State != "" && !knownInterfaceStates.Has(iface.State) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("state"), iface.State, supportedValues(knownInterfaceStates)))
+	}
+	if iface.State == InterfaceStateAbsent {
+		// Only the name and type matter to remove an interface
+		return allErrs
+	}
+	allErrs = append(allErrs, validateNumber(iface.MTU, fldPath.Child("mtu"), 0, math.MaxUint32)...)
+	if iface.MACAddress != "" {
+		if _, err := net.ParseMAC(iface.MACAddress); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("mac-address"), iface.MACAddress, "must be a MAC address"))
+		}
+	}
+	if iface.Controller != "" {
+		allErrs = append(allErrs, v.validateReference(iface.Controller, fldPath.Child("controller"))...)
+	}
+	allErrs = append(allErrs, validateIP(iface.IPv4, false, fldPath.Child("ipv4"))...)
+	allErrs = append(allErrs, validateIP(iface.IPv6, true, fldPath.Child("ipv6"))...)
+	if iface.Bridge != nil {
+		allErrs = append(allErrs, v.validateBridge(iface.Bridge, fldPath.Child("bridge"))...)
+	}
+	if iface.LinkAggregation != nil {
+		allErrs = append(allErrs, v.validateLinkAggregation(iface.LinkAggregation, fldPath.Child("link-aggregation"))...)
+	}
+	if iface.Vlan != nil {
+		allErrs = append(allErrs, v.validateVlan(iface.Name, iface.Vlan, fldPath.Child("vlan"))...)
+	} else if iface.Type == InterfaceTypeVlan {
+		allErrs = append(allErrs, v.requiredToCreate(iface.Name, fldPath.Child("vlan"))...)
+	}
+	if iface.Vxlan != nil {
+		allErrs = append(allErrs, v.validateVxlan(iface.Name, iface.Vxlan, fldPath.Child("vxlan"))...)
+	}
+	return allErrs
+}
+
+func validateIP(ip *IP, isIPv6 bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ip == nil {
+		return allErrs
+	}
+	maxPrefixLength := int64(32)
+	if isIPv6 {
+		maxPrefixLength = 128
+	}
+	for i, address := range ip.Address {
+		addressPath := fldPath.Child("address").Index(i)
+		ipPath := addressPath.Child("ip")
+		if address.IP == "" {
+			allErrs = append(allErrs, field.Required(ipPath, ""))
+			continue
+		}
+		parsedIP := net.ParseIP(address.IP)
+		if strings.Contains(address.IP, "/") {
+			var err error
+			parsedIP, _, err = net.ParseCIDR(address.IP)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(ipPath, address.IP, "must be an IP address or CIDR"))
+				continue
+			}
+		} else if address.PrefixLength == nil {
+			allErrs = append(allErrs, field.Required(addressPath.Child("prefix-length"), ""))
+		}
+		if parsedIP == nil || (parsedIP.To4() == nil) != isIPv6 {
+			allErrs = append(allErrs, field.Invalid(ipPath, address.IP, fmt.Sprintf("must be an %s address", ipFamily(isIPv6))))
+			continue
+		}
+		allErrs = append(allErrs, validateNumber(address.PrefixLength, addressPath.Child("prefix-length"), 0, maxPrefixLength)...)
+	}
+	return allErrs
+}
+
+func (v *validator) validateBridge(bridge *Bridge, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ports := sets.New[string]()
+	for i := range bridge.Port {
+		port := &bridge.Port[i]
+		portPath := fldPath.Child("port").Index(i)
+		if port.Name == "" {
+			allErrs = append(allErrs, field.Required(portPath.Child("name"), ""))
+			continue
+		}
+		if ports.Has(port.Name) {
+			allErrs = append(allErrs, field.Duplicate(portPath.Child("name"), port.Name))
+		}
+		ports.Insert(port.Name)
+		allErrs = append(allErrs, v.validateReference(port.Name, portPath.Child("name"))...)
+		if port.Vlan != nil {
+			allErrs = append(allErrs, validateBridgePortVlan(port.Vlan, portPath.Child("vlan"))...)
+		}
+	}
+	return allErrs
+}
+
+func validateBridgePortVlan(vlan *BridgePortVlan, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if vlan.Mode != "" && !knownVlanModes.Has(vlan.Mode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), vlan.Mode, supportedValues(knownVlanModes)))
+	}
+	allErrs = append(allErrs, validateNumber(vlan.Tag, fldPath.Child("tag"), 0, maxVlanID)...)
+	for i, trunkTag := range vlan.TrunkTags {
+		trunkTagPath := fldPath.Child("trunk-tags").Index(i)
+		switch {
+		case trunkTag.ID != nil && trunkTag.IDRange != nil:
+			allErrs = append(allErrs, field.Invalid(trunkTagPath, "", "only one of id or id-range can be set"))
+		case trunkTag.ID != nil:
+			allErrs = append(allErrs, validateNumber(trunkTag.ID, trunkTagPath.Child("id"), 0, maxVlanID)...)
+		case trunkTag.IDRange != nil:
+			allErrs = append(allErrs, validateVlanIDRange(trunkTag.IDRange, trunkTagPath.Child("id-range"))...)
+		default:
+			allErrs = append(allErrs, field.Required(trunkTagPath, "id or id-range is required"))
+		}
+	}
+	return allErrs
+}
+
+func validateVlanIDRange(idRange *VlanIDRange, fldPath *field.Path) field.ErrorList {
+	if idRange.Min == nil || idRange.Max == nil {
+		return field.ErrorList{field.Required(fldPath, "min and max are required")}
+	}
+	allErrs := validateNumber(idRange.Min, fldPath.Child("min"), 0, maxVlanID)
+	allErrs = append(allErrs, validateNumber(idRange.Max, fldPath.Child("max"), 0, maxVlanID)...)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	minID, _ := idRange.Min.Int64()
+	maxID, _ := idRange.Max.Int64()
+	if minID > maxID {
+		allErrs = append(allErrs, field.Invalid(fldPath, fmt.Sprintf("%d-%d", minID, maxID), "min must not be greater than max"))
+	}
+	return allErrs
+}
+
+func (v *validator) validateLinkAggregation(linkAggregation *LinkAggregation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if linkAggregation.Mode != "" && !knownBondModes.Has(linkAggregation.Mode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), linkAggregation.Mode, supportedValues(knownBondModes)))
+	}
+	ports := sets.New[string]()
+	for i, port := range linkAggregation.Port {
+		portPath := fldPath.Child("port").Index(i)
+		if ports.Has(port) {
+			allErrs = append(allErrs, field.Duplicate(portPath, port))
+		}
+		ports.Insert(port)
+		allErrs = append(allErrs, v.validateReference(port, portPath)...)
+	}
+	return allErrs
+}
+
+func (v *validator) validateVlan(name string, vlan *Vlan, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if vlan.BaseIface == "" {
+		allErrs = append(allErrs, v.requiredToCreate(name, fldPath.Child("base-iface"))...)
+	} else {
+		allErrs = append(allErrs, v.validateReference(vlan.BaseIface, fldPath.Child("base-iface"))...)
+	}
+	if vlan.ID == nil {
+		allErrs = append(allErrs, v.requiredToCreate(name, fldPath.Child("id"))...)
+	}
+	return append(allErrs, validateNumber(vlan.ID, fldPath.Child("id"), 0, maxVlanID)...)
+}
+
+func (v *validator) validateVxlan(name string, vxlan *Vxlan, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if vxlan.BaseIface != "" {
+		allErrs = append(allErrs, v.validateReference(vxlan.BaseIface, fldPath.Child("base-iface"))...)
+	}
+	if vxlan.ID == nil {
+		allErrs = append(allErrs, v.requiredToCreate(name, fldPath.Child("id"))...)
+	}
+	allErrs = append(allErrs, validateNumber(vxlan.ID, fldPath.Child("id"), 0, maxVxlanID)...)
+	if vxlan.Remote != "" && net.ParseIP(vxlan.Remote) == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("remote"), vxlan.Remote, "must be an IP address"))
+	}
+	return append(allErrs, validateNumber(vxlan.DestinationPort, fldPath.Child("destination-port"), 0, maxPort)...)
+}
+
+func (v *validator) validateRoutes(routes []Route, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range routes {
+		route := &routes[i]
+		routePath := fldPath.Index(i)
+		if route.State != "" && route.State != "absent" {
+			allErrs = append(allErrs, field.NotSupported(routePath.Child("state"), route.State, []string{"absent"}))
+		}
+		allErrs = append(allErrs, validateNumber(route.Metric, routePath.Child("metric"), 0, math.MaxUint32)...)
+		allErrs = append(allErrs, validateNumber(route.TableID, routePath.Child("table-id"), 0, math.MaxUint32)...)
+		var destination net.IP
+		if route.Destination == "" {
+			// Absent routes can be wildcards matching any destination
+			if route.State != "absent" {
+				allErrs = append(allErrs, field.Required(routePath.Child("destination"), ""))
+			}
+		} else if destination = parseIPOrCIDR(route.Destination); destination == nil {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("destination"), route.Destination, "must be an IP address or CIDR"))
+		}
+		if route.NextHopAddress != "" {
+			nextHop := net.ParseIP(route.NextHopAddress)
+			switch {
+			case nextHop == nil:
+				allErrs = append(allErrs, field.Invalid(routePath.Child("next-hop-address"), route.NextHopAddress, "must be an IP address"))
+			case destination != nil && (destination.To4() == nil) != (nextHop.To4() == nil):
+				allErrs = append(allErrs, field.Invalid(routePath.Child("next-hop-address"), route.NextHopAddress,
+					fmt.Sprintf("must be an %s address like the destination", ipFamily(destination.To4() == nil))))
+			}
+		}
+		if route.NextHopInterface != "" && route.State != "absent" {
+			allErrs = append(allErrs, v.validateReference(route.NextHopInterface, routePath.Child("next-hop-interface"))...)
+		}
+	}
+	return allErrs
+}
+
+func validateRouteRules(routeRules []RouteRule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range routeRules {
+		routeRule := &routeRules[i]
+		routeRulePath := fldPath.Index(i)
+		if routeRule.IPFrom != "" && parseIPOrCIDR(routeRule.IPFrom) == nil {
+			allErrs = append(allErrs, field.Invalid(routeRulePath.Child("ip-from"), routeRule.IPFrom, "must be an IP address or CIDR"))
+		}
+		if routeRule.IPTo != "" && parseIPOrCIDR(routeRule.IPTo) == nil {
+			allErrs = append(allErrs, field.Invalid(routeRulePath.Child("ip-to"), routeRule.IPTo, "must be an IP address or CIDR"))
+		}
+		allErrs = append(allErrs, validateNumber(routeRule.Priority, routeRulePath.Child("priority"), 0, math.MaxUint32)...)
+		allErrs = append(allErrs, validateNumber(routeRule.RouteTable, routeRulePath.Child("route-table"), 0, math.MaxUint32)...)
+	}
+	return allErrs
+}
+
+func validateNameServers(servers []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, server := range servers {
+		// IPv6 link local servers can have the interface as "fe80::1%eth1"
+		address, _, _ := strings.Cut(server, "%")
+		if net.ParseIP(address) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), server, "must be an IP address"))
+		}
+	}
+	return allErrs
+}
+
+// validateReference checks that the referenced interface is not removed by
+// the state and, if it is not defined there, that it exists at the nodes.
+func (v *validator) validateReference(name string, fldPath *field.Path) field.ErrorList {
+	if iface, found := v.interfaces[name]; found {
+		if iface.State == InterfaceStateAbsent {
+			return field.ErrorList{field.Invalid(fldPath, name, "references an interface removed at the desired state")}
+		}
+		return nil
+	}
+	if v.missingAt == nil {
+		return nil
+	}
+	nodes := v.missingAt(name)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return field.ErrorList{
+		field.Invalid(fldPath, name, fmt.Sprintf("interface not defined at the desired state nor found at nodes %s", listNodes(nodes))),
+	}
+}
+
+// requiredToCreate rejects the missing field if the interface does not
+// exist yet at some nodes, the existing interfaces are changed without it.
+func (v *validator) requiredToCreate(name string, fldPath *field.Path) field.ErrorList {
+	if v.missingAt == nil {
+		return nil
+	}
+	nodes := v.missingAt(name)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return field.ErrorList{field.Required(fldPath, fmt.Sprintf("needed to create the interface at nodes %s", listNodes(nodes)))}
+}
+
+func listNodes(nodes []string) string {
+	sort.Strings(nodes)
+	if len(nodes) > maxListedNodes {
+		return fmt.Sprintf("%s and %d more", strings.Join(nodes[:maxListedNodes], ", "), len(nodes)-maxListedNodes)
+	}
+	return strings.Join(nodes, ", ")
+}
+
+func validateNumber(number *Number, fldPath *field.Path, minValue, maxValue int64) field.ErrorList {
+	if number == nil {
+		return nil
+	}
+	value, err := number.Int64()
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, number.String(), "must be a number")}
+	}
+	if value < minValue || value > maxValue {
+		return field.ErrorList{field.Invalid(fldPath, value, fmt.Sprintf("must be between %d and %d", minValue, maxValue))}
+	}
+	return nil
+}
+
+// unknownFields returns an error for each extra field that is neither part
+// of typed nor known, suggesting the closest field name for typos.
+func unknownFields(extra map[string]interface{}, typed interface{}, known sets.Set[string], fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	candidates := known.Union(sets.New(jsonFieldNames(typed)...))
+	names := []string{}
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if candidates.Has(name) {
+			continue
+		}
+		detail := "unknown field"
+		if suggestion := closestName(name, sets.List(candidates)); suggestion != "" {
+			detail = fmt.Sprintf("unknown field, did you mean %q?", suggestion)
+		}
+		allErrs = append(allErrs, field.Invalid(fldPath.Child(name), name, detail))
+	}
+	return allErrs
+}
+
+// closestName returns the candidate at an edit distance of at most two from
+// name, or an empty string if there is none.
+func closestName(name string, candidates []string) string {
+	const maxDistance = 2
+	closest := ""
+	closestDistance := maxDistance + 1
+	for _, candidate := range candidates {
+		if distance := editDistance(name, candidate); distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+	return closest
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func supportedValues[T ~string](values sets.Set[T]) []string {
+	result := []string{}
+	for _, value := range sets.List(values) {
+		result = append(result, string(value))
+	}
+	return result
+}
+
+func parseIPOrCIDR(value string) net.IP {
+	if ip, _, err := net.ParseCIDR(value); err == nil {
+		return ip
+	}
+	return net.ParseIP(value)
+}
+
+func ipFamily(isIPv6 bool) string {
+	if isIPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
@@ -0,0 +1,324 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("nmstate schema validation", func() {
+	missingAtNode02 := func(name string) []string {
+		if name == "eth1" {
+			return nil
+		}
+		return []string{"node02"}
+	}
+	DescribeTable("Validate",
+		func(desiredState string, missingAt MissingInterfaceFunc, expectedErrors []string) {
+			state, err := FromState(shared.NewState(desiredState))
+			Expect(err).ToNot(HaveOccurred())
+			errs := []string{}
+			for _, err := range Validate(state, field.NewPath("desiredState"), missingAt) {
+				errs = append(errs, err.Error())
+			}
+			Expect(errs).To(ConsistOf(expectedErrors))
+		},
+		Entry("with a valid state", `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+- name: br1
+  type: linux-bridge
+  state: up
+  mtu: 1500
+  ethtool:
+    feature:
+      tx-checksum-ip-generic: false
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+    - ip: 192.168.2.10/24
+  ipv6:
+    enabled: true
+    address:
+    - ip: 2001:db8::10
+      prefix-length: 64
+  bridge:
+    port:
+    - name: eth1
+      vlan:
+        mode: trunk
+        trunk-tags:
+        - id: 100
+        - id-range: {min: 200, max: 299}
+- name: br1.100
+  type: vlan
+  state: up
+  vlan:
+    base-iface: br1
+    id: 100
+- name: br-ex
+  type: ovs-bridge
+  state: up
+  bridge:
+    port:
+    - name: br-ex
+- name: br-ex
+  type: ovs-interface
+  state: up
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+    next-hop-interface: br1
+    table-id: 254
+  - next-hop-interface: eth2
+    state: absent
+route-rules:
+  config:
+  - ip-from: 192.168.2.0/24
+    route-table: 200
+dns-resolver:
+  config:
+    server:
+    - 192.168.1.1
+    - fe80::1%br1
+`, nil, []string{}),
+		Entry("with typos at field names", `
+hostnam:
+  config: node01
+interfaces:
+- name: eth1
+  stat: up
+  ipv4:
+    enabled: true
+`, nil, []string{
+			`desiredState.hostnam: Invalid value: "hostnam": unknown field, did you mean "hostname"?`,
+			`desiredState.interfaces[0].stat: Invalid value: "stat": unknown field, did you mean "state"?`,
+		}),
+		Entry("with wrong interface names, types and states", `
+interfaces:
+- type: ethernet
+- name: eth/1
+- name: averyveryverylongname
+  type: ethernet
+- name: averyveryverylongbridge
+  type: ovs-bridge
+- name: eth2
+  type: ethernett
+  state: upp
+`, nil, []string{
+			`desiredState.interfaces[0].name: Required value`,
+			`desiredState.interfaces[1].name: Invalid value: "eth/1": must not be '.' or '..' nor contain '/', ':' or whitespaces`,
+			`desiredState.interfaces[2].name: Too long: must have at most 15 bytes`,
+			`desiredState.interfaces[4].type: Unsupported value: "ethernett": supported values: ` +
+				`"bond", "dummy", "ethernet", "hsr", "infiniband", "ipsec", "ipvlan", "linux-bridge", "loopback", "mac-vlan", ` +
+				`"mac-vtap", "macsec", "ovs-bridge", "ovs-interface", "team", "unknown", "veth", "vlan", "vrf", "vxlan", "xfrm"`,
+			`desiredState.interfaces[4].state: Unsupported value: "upp": supported values: "absent", "down", "ignore", "up"`,
+		}),
+		Entry("with duplicated interfaces", `
+interfaces:
+- name: eth1
+  type: ethernet
+- name: eth1
+  type: ethernet
+- name: br-ex
+  type: ovs-bridge
+- name: br-ex
+  type: ovs-interface
+- name: br-ex
+`, nil, []string{
+			`desiredState.interfaces[1].name: Duplicate value: "eth1"`,
+			`desiredState.interfaces[4].name: Duplicate value: "br-ex"`,
+		}),
+		Entry("with wrong vlan ids", `
+interfaces:
+- name: eth1.4095
+  type: vlan
+  vlan:
+    base-iface: eth1
+    id: 4095
+- name: eth1.x
+  type: vlan
+  vlan:
+    id: x
+- name: eth1.y
+  type: vlan
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: eth1
+      vlan:
+        mode: trunk
+        tag: 5000
+        trunk-tags:
+        - id-range: {min: 300, max: 200}
+        - {}
+`, nil, []string{
+			`desiredState.interfaces[0].vlan.id: Invalid value: 4095: must be between 0 and 4094`,
+			`desiredState.interfaces[1].vlan.id: Invalid value: "x": must be a number`,
+			`desiredState.interfaces[3].bridge.port[0].vlan.tag: Invalid value: 5000: must be between 0 and 4094`,
+			`desiredState.interfaces[3].bridge.port[0].vlan.trunk-tags[0].id-range: Invalid value: "300-200": min must not be greater than max`,
+			`desiredState.interfaces[3].bridge.port[0].vlan.trunk-tags[1]: Required value: id or id-range is required`,
+		}),
+		Entry("with vlans and vxlans without the fields needed to create them", `
+interfaces:
+- name: eth1.100
+  type: vlan
+  mtu: 1400
+- name: eth1.101
+  type: vlan
+  vlan:
+    id: 101
+- name: eth1.102
+  type: vlan
+- name: vxlan0
+  type: vxlan
+  vxlan:
+    remote: 192.168.1.2
+- name: vxlan1
+  type: vxlan
+  vxlan:
+    base-iface: eth1
+`, func(name string) []string {
+			if name == "eth1" || name == "eth1.100" || name == "vxlan0" {
+				return nil
+			}
+			return []string{"node02", "node01"}
+		}, []string{
+			`desiredState.interfaces[1].vlan.base-iface: Required value: needed to create the interface at nodes node01, node02`,
+			`desiredState.interfaces[2].vlan: Required value: needed to create the interface at nodes node01, node02`,
+			`desiredState.interfaces[4].vxlan.id: Required value: needed to create the interface at nodes node01, node02`,
+		}),
+		Entry("with wrong IP addresses", `
+interfaces:
+- name: eth1
+  ipv4:
+    address:
+    - ip: 192.168.1.300
+      prefix-length: 24
+    - ip: 2001:db8::1
+      prefix-length: 64
+    - ip: 192.168.1.10
+      prefix-length: 33
+    - ip: 192.168.1.11
+    - ip: 192.168.1.12/40
+  ipv6:
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+  mac-address: 02:42:BB:10:B8
+`, nil, []string{
+			`desiredState.interfaces[0].mac-address: Invalid value: "02:42:BB:10:B8": must be a MAC address`,
+			`desiredState.interfaces[0].ipv4.address[0].ip: Invalid value: "192.168.1.300": must be an IPv4 address`,
+			`desiredState.interfaces[0].ipv4.address[1].ip: Invalid value: "2001:db8::1": must be an IPv4 address`,
+			`desiredState.interfaces[0].ipv4.address[2].prefix-length: Invalid value: 33: must be between 0 and 32`,
+			`desiredState.interfaces[0].ipv4.address[3].prefix-length: Required value`,
+			`desiredState.interfaces[0].ipv4.address[4].ip: Invalid value: "192.168.1.12/40": must be an IP address or CIDR`,
+			`desiredState.interfaces[0].ipv6.address[0].ip: Invalid value: "192.168.1.10": must be an IPv6 address`,
+		}),
+		Entry("with wrong routes", `
+routes:
+  config:
+  - destination: 192.168.3.0/33
+    next-hop-address: 192.168.1.1
+  - destination: 2001:db8::/64
+    next-hop-address: 192.168.1.1
+  - next-hop-address: 192.168.1.1.1
+    metric: -1
+    state: present
+route-rules:
+  config:
+  - ip-to: 10.0.0.0/x
+dns-resolver:
+  config:
+    server:
+    - dns.example.com
+`, nil, []string{
+			`desiredState.routes.config[0].destination: Invalid value: "192.168.3.0/33": must be an IP address or CIDR`,
+			`desiredState.routes.config[1].next-hop-address: Invalid value: "192.168.1.1": must be an IPv6 address like the destination`,
+			`desiredState.routes.config[2].state: Unsupported value: "present": supported values: "absent"`,
+			`desiredState.routes.config[2].metric: Invalid value: -1: must be between 0 and 4294967295`,
+			`desiredState.routes.config[2].destination: Required value`,
+			`desiredState.routes.config[2].next-hop-address: Invalid value: "192.168.1.1.1": must be an IP address`,
+			`desiredState.route-rules.config[0].ip-to: Invalid value: "10.0.0.0/x": must be an IP address or CIDR`,
+			`desiredState.dns-resolver.config.server[0]: Invalid value: "dns.example.com": must be an IP address`,
+		}),
+		Entry("with references to removed or missing interfaces", `
+interfaces:
+- name: eth2
+  state: absent
+- name: bond1
+  type: bond
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth1
+    - eth2
+    - eth3
+    - eth3
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: bond1
+    - name: eth4
+- name: eth5.10
+  type: vlan
+  vlan:
+    base-iface: eth5
+    id: 10
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth2
+`, missingAtNode02, []string{
+			`desiredState.interfaces[1].link-aggregation.port[1]: Invalid value: "eth2": references an interface removed at the desired state`,
+			`desiredState.interfaces[1].link-aggregation.port[2]: Invalid value: "eth3": ` +
+				`interface not defined at the desired state nor found at nodes node02`,
+			`desiredState.interfaces[1].link-aggregation.port[3]: Duplicate value: "eth3"`,
+			`desiredState.interfaces[1].link-aggregation.port[3]: Invalid value: "eth3": ` +
+				`interface not defined at the desired state nor found at nodes node02`,
+			`desiredState.interfaces[2].bridge.port[1].name: Invalid value: "eth4": ` +
+				`interface not defined at the desired state nor found at nodes node02`,
+			`desiredState.interfaces[3].vlan.base-iface: Invalid value: "eth5": ` +
+				`interface not defined at the desired state nor found at nodes node02`,
+			`desiredState.routes.config[0].next-hop-interface: Invalid value: "eth2": references an interface removed at the desired state`,
+		}),
+	)
+	It("should limit the number of nodes listed for missing interfaces", func() {
+		state, err := FromState(shared.NewState("interfaces: [{name: br1, bridge: {port: [{name: eth9}]}}]"))
+		Expect(err).ToNot(HaveOccurred())
+		missingAt := func(string) []string {
+			return []string{"node07", "node06", "node05", "node04", "node03", "node02", "node01"}
+		}
+		Expect(Validate(state, field.NewPath("desiredState"), missingAt).ToAggregate()).To(MatchError(
+			`desiredState.interfaces[0].bridge.port[0].name: Invalid value: "eth9": ` +
+				`interface not defined at the desired state nor found at nodes node01, node02, node03, node04, node05 and 2 more`,
+		))
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Bool is a boolean that nmstate also accepts as "true" or "false" strings
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	var value bool
+	if err := json.Unmarshal(data, &value); err == nil {
+		*b = Bool(value)
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("expected a boolean, got %s", data)
+	}
+	value, err := strconv.ParseBool(str)
+	if err != nil {
+		return fmt.Errorf("expected a boolean, got %q", str)
+	}
+	*b = Bool(value)
+	return nil
+}
+
+// IsTrue returns false for nil
+func (b *Bool) IsTrue() bool {
+	return b != nil && bool(*b)
+}
+
+// NewBool returns a pointer to a Bool with value
+func NewBool(value bool) *Bool {
+	b := Bool(value)
+	return &b
+}
+
+// Number is a numeric field that nmstate also accepts as a numeric string,
+// it is encoded back as it was decoded.
+type Number struct {
+	value  string
+	quoted bool
+}
+
+// NewNumber returns a pointer to a Number with value
+func NewNumber(value int64) *Number {
+	return &Number{value: strconv.FormatInt(value, 10)}
+}
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*n = Number{value: str, quoted: true}
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("expected a number, got %s", data)
+	}
+	*n = Number{value: number.String()}
+	return nil
+}
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	if n.quoted {
+		return json.Marshal(n.value)
+	}
+	return []byte(n.value), nil
+}
+
+// Int64 returns the value, it fails if it is a string that is not a number
+func (n *Number) Int64() (int64, error) {
+	if n == nil {
+		return 0, fmt.Errorf("missing number")
+	}
+	return strconv.ParseInt(n.value, 10, 64)
+}
+
+func (n *Number) String() string {
+	if n == nil {
+		return ""
+	}
+	return n.value
+}
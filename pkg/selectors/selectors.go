@@ -27,12 +27,12 @@ import (
 )
 
 type Selectors struct {
-	client client.Client
+	client client.Reader
 	policy nmstatev1.NodeNetworkConfigurationPolicy
 	logger logr.Logger
 }
 
-func NewFromPolicy(cli client.Client, policy *nmstatev1.NodeNetworkConfigurationPolicy) Selectors {
+func NewFromPolicy(cli client.Reader, policy *nmstatev1.NodeNetworkConfigurationPolicy) Selectors {
 	selectors := Selectors{
 		client: cli,
 		policy: *policy,
@@ -20,8 +20,7 @@ package state
 import (
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
-
-	yaml "sigs.k8s.io/yaml"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
 )
 
 const (
@@ -38,8 +37,11 @@ func FilterOut(currentState shared.State) (shared.State, error) {
 	return filterOut(currentState)
 }
 
-func filterOutRoutes(routes []routeState, filteredInterfaces []interfaceState) []routeState {
-	filteredRoutes := []routeState{}
+func filterOutRoutes(routes []schema.Route, filteredInterfaces []schema.Interface) []schema.Route {
+	if routes == nil {
+		return nil
+	}
+	filteredRoutes := []schema.Route{}
 	for _, route := range routes {
 		name := route.NextHopInterface
 		if isInInterfaces(name, filteredInterfaces) {
@@ -49,7 +51,7 @@ func filterOutRoutes(routes []routeState, filteredInterfaces []interfaceState) [
 	return filteredRoutes
 }
 
-func isInInterfaces(interfaceName string, interfaces []interfaceState) bool {
+func isInInterfaces(interfaceName string, interfaces []schema.Interface) bool {
 	for _, iface := range interfaces {
 		if iface.Name == interfaceName {
 			return true
@@ -58,74 +60,60 @@ func isInInterfaces(interfaceName string, interfaces []interfaceState) bool {
 	return false
 }
 
-func filterOutDynamicAttributes(iface map[string]interface{}) {
+func filterOutDynamicAttributes(iface *schema.Interface) {
 	// The gc-timer and hello-time are deep into linux-bridge like this
 	//    - bridge:
 	//        options:
 	//          gc-timer: 13715
 	//          hello-timer: 0
-	if iface["type"] != "linux-bridge" {
-		return
-	}
-
-	bridgeRaw, hasBridge := iface["bridge"]
-	if !hasBridge {
-		return
-	}
-	bridge, ok := bridgeRaw.(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	optionsRaw, hasOptions := bridge["options"]
-	if !hasOptions {
-		return
-	}
-	options, ok := optionsRaw.(map[string]interface{})
-	if !ok {
+	if iface.Type != schema.InterfaceTypeLinuxBridge || iface.Bridge == nil || iface.Bridge.Options == nil {
 		return
 	}
-
-	delete(options, "gc-timer")
-	delete(options, "hello-timer")
+	iface.Bridge.Options.GCTimer = nil
+	iface.Bridge.Options.HelloTimer = nil
 }
 
-func filterOutInterfaces(ifacesState []interfaceState) []interfaceState {
-	filteredInterfaces := []interfaceState{}
-	for _, iface := range ifacesState {
-		if isVeth(iface.Data) && isUnmanaged(iface.Data) {
+func filterOutInterfaces(ifacesState []schema.Interface) []schema.Interface {
+	filteredInterfaces := []schema.Interface{}
+	for i := range ifacesState {
+		iface := ifacesState[i]
+		if isVeth(&iface) && isUnmanaged(&iface) {
 			continue
 		}
-		filterOutDynamicAttributes(iface.Data)
+		filterOutDynamicAttributes(&iface)
 		filteredInterfaces = append(filteredInterfaces, iface)
 	}
 	return filteredInterfaces
 }
 
-func isVeth(ifaceData map[string]interface{}) bool {
-	return ifaceData["type"] == "veth"
+func isVeth(iface *schema.Interface) bool {
+	return iface.Type == schema.InterfaceTypeVeth
 }
 
-func isUnmanaged(ifaceData map[string]interface{}) bool {
-	return ifaceData["state"] == "ignore"
+func isUnmanaged(iface *schema.Interface) bool {
+	return iface.State == schema.InterfaceStateIgnore
 }
 
 func filterOut(currentState shared.State) (shared.State, error) {
-	var state rootState
-	if err := yaml.Unmarshal(currentState.Raw, &state); err != nil {
+	state, err := schema.FromState(currentState)
+	if err != nil {
 		return currentState, err
 	}
 
-	state.Interfaces = filterOutInterfaces(state.Interfaces)
-	if state.Routes != nil {
-		state.Routes.Running = filterOutRoutes(state.Routes.Running, state.Interfaces)
-		state.Routes.Config = filterOutRoutes(state.Routes.Config, state.Interfaces)
+	// Only these sections are reported at the NodeNetworkState
+	filteredState := schema.State{
+		Interfaces:  filterOutInterfaces(state.Interfaces),
+		Routes:      state.Routes,
+		DNSResolver: state.DNSResolver,
+		OVN:         state.OVN,
 	}
-
-	filteredState, err := yaml.Marshal(state)
-	if err != nil {
-		return currentState, err
+	if filteredState.Routes != nil {
+		filteredState.Routes.Running = filterOutRoutes(state.Routes.Running, filteredState.Interfaces)
+		filteredState.Routes.Config = filterOutRoutes(state.Routes.Config, filteredState.Interfaces)
+	}
+	if filteredState.OVN != nil && len(filteredState.OVN.BridgeMappings) == 0 {
+		filteredState.OVN.BridgeMappings = nil
 	}
 
-	return shared.NewState(string(filteredState)), nil
+	return filteredState.ToState()
 }
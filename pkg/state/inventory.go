@@ -0,0 +1,162 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"net"
+	"sort"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+)
+
+const (
+	// MaxInventoryInterfaces limits the interfaces of a node at the
+	// inventory so the metrics built from it have a bounded cardinality.
+	MaxInventoryInterfaces = 128
+
+	// OtherInterfaceType is the type reported for the interfaces of a type
+	// not in InventoryInterfaceTypes.
+	OtherInterfaceType = "other"
+)
+
+// InventoryInterfaceTypes are the interface types reported by the inventory,
+// so labeling metrics with them keeps a bounded label set.
+var InventoryInterfaceTypes = []schema.InterfaceType{
+	schema.InterfaceTypeEthernet,
+	schema.InterfaceTypeBond,
+	schema.InterfaceTypeLinuxBridge,
+	schema.InterfaceTypeOVSBridge,
+	schema.InterfaceTypeOVSInterface,
+	schema.InterfaceTypeVlan,
+	schema.InterfaceTypeVxlan,
+	schema.InterfaceTypeVeth,
+	schema.InterfaceTypeDummy,
+	schema.InterfaceTypeLoopback,
+}
+
+// InterfaceInventory has the facts about a node interface
+type InterfaceInventory struct {
+	Name string
+	// Type is one of InventoryInterfaceTypes or OtherInterfaceType
+	Type          string
+	Up            bool
+	MTU           *int64
+	IPv4Addresses int
+	IPv6Addresses int
+	BondPorts     int
+}
+
+// Inventory has the facts about the network of a node that can be
+// monitored without inspecting its whole state.
+type Inventory struct {
+	// Interfaces are sorted by name and do not include the interfaces
+	// not managed by nmstate
+	Interfaces       []InterfaceInventory
+	DefaultRouteIPv4 bool
+	DefaultRouteIPv6 bool
+	DNSServers       int
+}
+
+// NewInventory builds the inventory of the current state reported at a
+// NodeNetworkState.
+func NewInventory(currentState shared.State) (*Inventory, error) {
+	state, err := schema.FromState(currentState)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{Interfaces: []InterfaceInventory{}}
+	for i := range state.Interfaces {
+		iface := &state.Interfaces[i]
+		if isUnmanaged(iface) {
+			continue
+		}
+		inventory.Interfaces = append(inventory.Interfaces, interfaceInventory(iface))
+	}
+	sort.Slice(inventory.Interfaces, func(i, j int) bool {
+		return inventory.Interfaces[i].Name < inventory.Interfaces[j].Name
+	})
+	if len(inventory.Interfaces) > MaxInventoryInterfaces {
+		inventory.Interfaces = inventory.Interfaces[:MaxInventoryInterfaces]
+	}
+
+	if state.Routes != nil {
+		for _, route := range state.Routes.Running {
+			switch route.Destination {
+			case "0.0.0.0/0":
+				inventory.DefaultRouteIPv4 = true
+			case "::/0":
+				inventory.DefaultRouteIPv6 = true
+			}
+		}
+	}
+
+	if state.DNSResolver != nil && state.DNSResolver.Running != nil {
+		inventory.DNSServers = len(state.DNSResolver.Running.Server)
+	}
+	return inventory, nil
+}
+
+func interfaceInventory(iface *schema.Interface) InterfaceInventory {
+	inventory := InterfaceInventory{
+		Name: iface.Name,
+		Type: inventoryInterfaceType(iface.Type),
+		Up:   iface.State == schema.InterfaceStateUp,
+	}
+	if iface.MTU != nil {
+		if mtu, err := iface.MTU.Int64(); err == nil {
+			inventory.MTU = &mtu
+		}
+	}
+	inventory.IPv4Addresses = countAddresses(iface.IPv4, false)
+	inventory.IPv6Addresses = countAddresses(iface.IPv6, true)
+	if iface.Type == schema.InterfaceTypeBond && iface.LinkAggregation != nil {
+		inventory.BondPorts = len(iface.LinkAggregation.Port)
+	}
+	return inventory
+}
+
+func inventoryInterfaceType(interfaceType schema.InterfaceType) string {
+	for _, inventoryType := range InventoryInterfaceTypes {
+		if interfaceType == inventoryType {
+			return string(interfaceType)
+		}
+	}
+	return OtherInterfaceType
+}
+
+// countAddresses counts the addresses of the family, the IPv6 link-local
+// ones are not counted since every interface has them.
+func countAddresses(ip *schema.IP, isIPv6 bool) int {
+	if ip == nil || (ip.Enabled != nil && !ip.Enabled.IsTrue()) {
+		return 0
+	}
+	count := 0
+	for _, address := range ip.Address {
+		parsed := net.ParseIP(address.IP)
+		if parsed == nil || (parsed.To4() == nil) != isIPv6 {
+			continue
+		}
+		if isIPv6 && parsed.IsLinkLocalUnicast() {
+			continue
+		}
+		count++
+	}
+	return count
+}
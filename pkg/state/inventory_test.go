@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/pointer"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("NewInventory", func() {
+	It("should report the interfaces, default routes and DNS servers", func() {
+		inventory, err := NewInventory(nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+  ipv6:
+    enabled: true
+    address:
+    - ip: fe80::1
+      prefix-length: 64
+    - ip: 2001:db8::10
+      prefix-length: 64
+- name: bond0
+  type: bond
+  state: up
+  mtu: 9000
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth2
+    - eth3
+- name: eth2
+  type: ethernet
+  state: down
+  ipv4:
+    enabled: false
+    address:
+    - ip: 10.0.0.1
+      prefix-length: 24
+- name: ib0
+  type: infiniband
+  state: up
+- name: veth1
+  type: veth
+  state: ignore
+routes:
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.1
+    next-hop-interface: eth1
+  - destination: 192.168.1.0/24
+    next-hop-interface: eth1
+dns-resolver:
+  running:
+    server:
+    - 192.168.1.1
+    - 8.8.8.8
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inventory).To(Equal(&Inventory{
+			Interfaces: []InterfaceInventory{
+				{Name: "bond0", Type: "bond", Up: true, MTU: pointer.Int64(9000), BondPorts: 2},
+				{Name: "eth1", Type: "ethernet", Up: true, MTU: pointer.Int64(1500), IPv4Addresses: 1, IPv6Addresses: 1},
+				{Name: "eth2", Type: "ethernet", Up: false},
+				{Name: "ib0", Type: OtherInterfaceType, Up: true},
+			},
+			DefaultRouteIPv4: true,
+			DefaultRouteIPv6: false,
+			DNSServers:       2,
+		}))
+	})
+	It("should limit the number of interfaces", func() {
+		interfaces := ""
+		for i := 0; i < MaxInventoryInterfaces+10; i++ {
+			interfaces += fmt.Sprintf("- name: dummy%03d\n  type: dummy\n  state: up\n", i)
+		}
+		inventory, err := NewInventory(nmstate.NewState("interfaces:\n" + interfaces))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inventory.Interfaces).To(HaveLen(MaxInventoryInterfaces))
+		Expect(inventory.Interfaces[0].Name).To(Equal("dummy000"))
+	})
+	It("should fail with a malformed state", func() {
+		_, err := NewInventory(nmstate.NewState("interfaces: {"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,42 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// ClusterPolicyName returns the name of the cluster policy applying a
+// namespaced policy at the nodes
+func ClusterPolicyName(namespace, name string) string {
+	return namespace + "." + name
+}
+
+// ClusterPolicySpec returns the spec of the cluster policy applying a
+// namespaced policy, the dependencies are other namespaced policies of the
+// same namespace.
+func ClusterPolicySpec(namespace string, spec *shared.NodeNetworkConfigurationPolicySpec) shared.NodeNetworkConfigurationPolicySpec {
+	clusterSpec := *spec.DeepCopy()
+	if len(spec.DependsOn) > 0 {
+		clusterSpec.DependsOn = []string{}
+		for _, dependency := range spec.DependsOn {
+			clusterSpec.DependsOn = append(clusterSpec.DependsOn, ClusterPolicyName(namespace, dependency))
+		}
+	}
+	return clusterSpec
+}
@@ -0,0 +1,252 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+)
+
+const mainRouteTableID = 254
+
+// ForNamespace returns the quotas limiting the namespaced policies of the
+// namespace sorted by name.
+func ForNamespace(quotas []nmstatev1beta1.NodeNetworkConfigurationQuota, namespace string) []nmstatev1beta1.NodeNetworkConfigurationQuota {
+	namespaceQuotas := []nmstatev1beta1.NodeNetworkConfigurationQuota{}
+	for i := range quotas {
+		for _, quotaNamespace := range quotas[i].Spec.Namespaces {
+			if quotaNamespace == namespace {
+				namespaceQuotas = append(namespaceQuotas, quotas[i])
+				break
+			}
+		}
+	}
+	sort.Slice(namespaceQuotas, func(i, j int) bool { return namespaceQuotas[i].Name < namespaceQuotas[j].Name })
+	return namespaceQuotas
+}
+
+// Validate checks that the namespaced policy spec only selects the nodes and
+// configures the interfaces and routes granted by one of the namespace quotas. If none
+// grants them, the errors of the quota closest to granting them are
+// returned.
+func Validate(
+	quotas []nmstatev1beta1.NodeNetworkConfigurationQuota,
+	namespace string,
+	spec *shared.NodeNetworkConfigurationPolicySpec,
+	fldPath *field.Path,
+) field.ErrorList {
+	allErrs := validateSpec(spec, fldPath)
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	namespaceQuotas := ForNamespace(quotas, namespace)
+	if len(namespaceQuotas) == 0 {
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("namespace %s has no NodeNetworkConfigurationQuota", namespace))}
+	}
+	state, err := schema.FromState(spec.DesiredState)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath.Child("desiredState"), "", fmt.Sprintf("invalid desired state: %v", err))}
+	}
+	var closestErrs field.ErrorList
+	for i := range namespaceQuotas {
+		quotaErrs := validateQuota(&namespaceQuotas[i], spec, state, fldPath)
+		if len(quotaErrs) == 0 {
+			return nil
+		}
+		if closestErrs == nil || len(quotaErrs) < len(closestErrs) {
+			closestErrs = quotaErrs
+		}
+	}
+	return closestErrs
+}
+
+// validateSpec rejects the policy fields that affect more than the granted
+// interfaces, whatever the quota is.
+func validateSpec(spec *shared.NodeNetworkConfigurationPolicySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(spec.Capture) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("capture"),
+			"namespaced policies cannot capture the node state"))
+	}
+	if spec.DrainBeforeApply {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("drainBeforeApply"),
+			"namespaced policies cannot drain nodes"))
+	}
+	return allErrs
+}
+
+func validateQuota(
+	quota *nmstatev1beta1.NodeNetworkConfigurationQuota,
+	spec *shared.NodeNetworkConfigurationPolicySpec,
+	state *schema.State,
+	fldPath *field.Path,
+) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allErrs = append(allErrs, validateNodeSelector(quota, spec.NodeSelector, fldPath.Child("nodeSelector"))...)
+	allErrs = append(allErrs, validateDesiredState(quota, state, fldPath.Child("desiredState"))...)
+	return allErrs
+}
+
+func validateNodeSelector(
+	quota *nmstatev1beta1.NodeNetworkConfigurationQuota,
+	nodeSelector map[string]string,
+	fldPath *field.Path,
+) field.ErrorList {
+	allErrs := field.ErrorList{}
+	keys := []string{}
+	for key := range quota.Spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := quota.Spec.NodeSelector[key]
+		if nodeSelector[key] != value {
+			allErrs = append(allErrs, field.Forbidden(fldPath,
+				fmt.Sprintf("has to include %s=%s granted by NodeNetworkConfigurationQuota %s", key, value, quota.Name)))
+		}
+	}
+	return allErrs
+}
+
+func validateDesiredState(quota *nmstatev1beta1.NodeNetworkConfigurationQuota, state *schema.State, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	forbidden := func(path *field.Path) {
+		allErrs = append(allErrs, field.Forbidden(path, "namespaced policies can only configure interfaces and routes"))
+	}
+	if state.RouteRules != nil {
+		forbidden(fldPath.Child("route-rules"))
+	}
+	if state.DNSResolver != nil {
+		forbidden(fldPath.Child("dns-resolver"))
+	}
+	if state.OVSDB != nil {
+		forbidden(fldPath.Child("ovs-db"))
+	}
+	if state.OVN != nil {
+		forbidden(fldPath.Child("ovn"))
+	}
+	extraKeys := []string{}
+	for key := range state.Extra {
+		extraKeys = append(extraKeys, key)
+	}
+	sort.Strings(extraKeys)
+	for _, key := range extraKeys {
+		forbidden(fldPath.Child(key))
+	}
+
+	checkInterface := func(name string, path *field.Path) {
+		if !granted(quota, name) {
+			allErrs = append(allErrs, field.Forbidden(path,
+				fmt.Sprintf("interface %s is not granted by NodeNetworkConfigurationQuota %s", name, quota.Name)))
+		}
+	}
+	for i := range state.Interfaces {
+		iface := &state.Interfaces[i]
+		ifacePath := fldPath.Child("interfaces").Index(i)
+		checkInterface(iface.Name, ifacePath.Child("name"))
+		for _, reference := range iface.References(ifacePath) {
+			checkInterface(reference.Name, reference.Path)
+		}
+	}
+	if state.Routes != nil {
+		for i := range state.Routes.Config {
+			route := &state.Routes.Config[i]
+			routePath := fldPath.Child("routes", "config").Index(i)
+			if route.NextHopInterface != "" {
+				checkInterface(route.NextHopInterface, routePath.Child("next-hop-interface"))
+			}
+			allErrs = append(allErrs, validateRoute(quota, route, routePath)...)
+		}
+	}
+	return allErrs
+}
+
+// validateRoute checks that the route destination is within one of the quota
+// destinations and that its table is one of the quota tables.
+func validateRoute(quota *nmstatev1beta1.NodeNetworkConfigurationQuota, route *schema.Route, fldPath *field.Path) field.ErrorList {
+	if quota.Spec.Routes == nil {
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("routes are not granted by NodeNetworkConfigurationQuota %s", quota.Name))}
+	}
+	allErrs := field.ErrorList{}
+	if !grantedDestination(quota, route.Destination) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("destination"),
+			fmt.Sprintf("route destination %q is not granted by NodeNetworkConfigurationQuota %s", route.Destination, quota.Name)))
+	}
+	// nmstate configures the routes without table-id or with table-id 0 at
+	// the main table
+	tableID := route.TableID.String()
+	if tableID == "" || tableID == "0" {
+		tableID = strconv.Itoa(mainRouteTableID)
+	}
+	if !grantedTable(quota, tableID) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("table-id"),
+			fmt.Sprintf("route table %s is not granted by NodeNetworkConfigurationQuota %s", tableID, quota.Name)))
+	}
+	return allErrs
+}
+
+// grantedDestination returns true if the destination CIDR is within one of
+// the quota destinations
+func grantedDestination(quota *nmstatev1beta1.NodeNetworkConfigurationQuota, destination string) bool {
+	_, destinationNet, err := net.ParseCIDR(destination)
+	if err != nil {
+		return false
+	}
+	destinationOnes, destinationBits := destinationNet.Mask.Size()
+	for _, grantedDestination := range quota.Spec.Routes.Destinations {
+		_, grantedNet, err := net.ParseCIDR(grantedDestination)
+		if err != nil {
+			continue
+		}
+		grantedOnes, grantedBits := grantedNet.Mask.Size()
+		if grantedBits == destinationBits && grantedOnes <= destinationOnes && grantedNet.Contains(destinationNet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantedTable returns true if the route table is one of the quota tables
+func grantedTable(quota *nmstatev1beta1.NodeNetworkConfigurationQuota, tableID string) bool {
+	for _, grantedTableID := range quota.Spec.Routes.TableIDs {
+		if strconv.FormatInt(grantedTableID, 10) == tableID {
+			return true
+		}
+	}
+	return false
+}
+
+// granted returns true if the interface name matches one of the quota
+// interface patterns
+func granted(quota *nmstatev1beta1.NodeNetworkConfigurationQuota, name string) bool {
+	for _, pattern := range quota.Spec.Interfaces {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var _ = Describe("NodeNetworkConfigurationQuota", func() {
+	quotas := []nmstatev1beta1.NodeNetworkConfigurationQuota{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a-rack1"},
+			Spec: shared.NodeNetworkConfigurationQuotaSpec{
+				Namespaces:   []string{"team-a"},
+				NodeSelector: map[string]string{"rack": "r1"},
+				Interfaces:   []string{"eth1", "eth1.*", "br-team-a*"},
+				Routes: &shared.NodeNetworkConfigurationQuotaRoutes{
+					Destinations: []string{"10.0.0.0/8", "fd00::/8"},
+					TableIDs:     []int64{254, 100},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a-rack2"},
+			Spec: shared.NodeNetworkConfigurationQuotaSpec{
+				Namespaces:   []string{"team-a", "team-b"},
+				NodeSelector: map[string]string{"rack": "r2", "pool": "tenants"},
+				Interfaces:   []string{"eth2.*"},
+			},
+		},
+	}
+	spec := func(nodeSelector map[string]string, desiredState string) *shared.NodeNetworkConfigurationPolicySpec {
+		return &shared.NodeNetworkConfigurationPolicySpec{
+			NodeSelector: nodeSelector,
+			DesiredState: shared.NewState(desiredState),
+		}
+	}
+	It("ForNamespace should return the namespace quotas", func() {
+		Expect(ForNamespace(quotas, "team-b")).To(HaveLen(1))
+		Expect(ForNamespace(quotas, "team-b")[0].Name).To(Equal("team-a-rack2"))
+		Expect(ForNamespace(quotas, "team-c")).To(BeEmpty())
+	})
+	DescribeTable("Validate",
+		func(namespace string, policySpec *shared.NodeNetworkConfigurationPolicySpec, expectedErrs field.ErrorList) {
+			Expect(Validate(quotas, namespace, policySpec, field.NewPath("spec"))).To(Equal(expectedErrs))
+		},
+		Entry("should allow granted nodes and interfaces",
+			"team-a",
+			spec(map[string]string{"rack": "r1", "role": "worker"}, `
+interfaces:
+- name: eth1.100
+  type: vlan
+  vlan:
+    base-iface: eth1
+    id: 100
+- name: br-team-a
+  type: linux-bridge
+  bridge:
+    port:
+    - name: eth1.100
+routes:
+  config:
+  - destination: 10.0.0.0/8
+    next-hop-interface: br-team-a
+`),
+			nil),
+		Entry("should allow the nodes and interfaces granted by any of the quotas",
+			"team-a",
+			spec(map[string]string{"rack": "r2", "pool": "tenants"}, `
+interfaces:
+- name: eth2.100
+  type: vlan
+`),
+			nil),
+		Entry("should reject namespaces without quota",
+			"team-c",
+			spec(map[string]string{"rack": "r1"}, `interfaces: [{name: eth1}]`),
+			field.ErrorList{field.Forbidden(field.NewPath("spec"), "namespace team-c has no NodeNetworkConfigurationQuota")}),
+		Entry("should reject capture and drain",
+			"team-a",
+			&shared.NodeNetworkConfigurationPolicySpec{
+				Capture:          map[string]string{"eth0": `interfaces.name=="eth0"`},
+				DrainBeforeApply: true,
+			},
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "capture"), "namespaced policies cannot capture the node state"),
+				field.Forbidden(field.NewPath("spec", "drainBeforeApply"), "namespaced policies cannot drain nodes"),
+			}),
+		Entry("should reject nodes and interfaces not granted, with the errors of the closest quota",
+			"team-a",
+			spec(map[string]string{"rack": "r1"}, `
+interfaces:
+- name: br-team-a
+  type: linux-bridge
+  bridge:
+    port:
+    - name: eth0
+- name: bond0
+  type: bond
+  link-aggregation:
+    port:
+    - eth1
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth0
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+`),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "dns-resolver"),
+					"namespaced policies can only configure interfaces and routes"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(0).Child("bridge", "port").Index(0).Child("name"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(1).Child("name"),
+					"interface bond0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0).Child("next-hop-interface"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0).Child("destination"),
+					`route destination "0.0.0.0/0" is not granted by NodeNetworkConfigurationQuota team-a-rack1`),
+			}),
+		Entry("should reject VLAN and VXLAN on top of interfaces not granted",
+			"team-a",
+			spec(map[string]string{"rack": "r1"}, `
+interfaces:
+- name: eth1.100
+  type: vlan
+  vlan:
+    base-iface: eth0
+    id: 100
+- name: eth1.vx
+  type: vxlan
+  vxlan:
+    base-iface: eth0
+    id: 10
+    remote: 192.168.1.1
+`),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(0).Child("vlan", "base-iface"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(1).Child("vxlan", "base-iface"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+			}),
+		Entry("should reject the interfaces not granted at the sections of the types that are not modeled",
+			"team-a",
+			spec(map[string]string{"rack": "r1"}, `
+interfaces:
+- name: br-team-a-vrf
+  type: vrf
+  vrf:
+    port: [eth0, eth1]
+    route-table-id: 100
+- name: br-team-a-team
+  type: team
+  team:
+    port:
+    - name: eth0
+- name: br-team-a-mv
+  type: mac-vlan
+  mac-vlan:
+    base-iface: eth0
+    mode: bridge
+- name: br-team-a-hsr
+  type: hsr
+  hsr:
+    port1: eth1
+    port2: eth0
+- name: br-team-a-ovs
+  type: ovs-bridge
+  bridge:
+    port:
+    - name: bond1
+      link-aggregation:
+        port:
+        - name: eth0
+`),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(0).Child("vrf", "port").Index(0),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(1).Child("team", "port").Index(0).Child("name"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(2).Child("mac-vlan", "base-iface"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(3).Child("hsr", "port2"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(4).Child("bridge", "port").Index(0).Child("name"),
+					"interface bond1 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "interfaces").Index(4).Child("bridge", "port").Index(0).
+					Child("link-aggregation", "port").Index(0).Child("name"),
+					"interface eth0 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+			}),
+		Entry("should allow routes within the granted destinations and tables",
+			"team-a",
+			spec(map[string]string{"rack": "r1"}, `
+routes:
+  config:
+  - destination: 10.1.0.0/16
+    next-hop-interface: eth1.100
+    table-id: 100
+  - destination: fd00:1::/64
+    next-hop-interface: eth1.100
+`),
+			nil),
+		Entry("should reject routes outside the granted destinations and tables",
+			"team-a",
+			spec(map[string]string{"rack": "r1"}, `
+routes:
+  config:
+  - destination: 10.0.0.0/7
+    next-hop-interface: eth1.100
+  - destination: 192.168.0.0/24
+    next-hop-interface: eth1.100
+    table-id: 200
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth1.100
+    state: absent
+  - next-hop-interface: eth1.100
+    state: absent
+`),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0).Child("destination"),
+					`route destination "10.0.0.0/7" is not granted by NodeNetworkConfigurationQuota team-a-rack1`),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(1).Child("destination"),
+					`route destination "192.168.0.0/24" is not granted by NodeNetworkConfigurationQuota team-a-rack1`),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(1).Child("table-id"),
+					"route table 200 is not granted by NodeNetworkConfigurationQuota team-a-rack1"),
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(3).Child("destination"),
+					`route destination "" is not granted by NodeNetworkConfigurationQuota team-a-rack1`),
+			}),
+		Entry("should reject routes if the quota does not grant any",
+			"team-b",
+			spec(map[string]string{"rack": "r2", "pool": "tenants"}, `
+routes:
+  config:
+  - destination: 10.0.0.0/8
+    next-hop-interface: eth2.100
+`),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "desiredState", "routes", "config").Index(0),
+					"routes are not granted by NodeNetworkConfigurationQuota team-a-rack2"),
+			}),
+		Entry("should reject node selectors selecting nodes not granted",
+			"team-b",
+			spec(map[string]string{"rack": "r2"}, `interfaces: [{name: eth2.100}]`),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "nodeSelector"),
+					"has to include pool=tenants granted by NodeNetworkConfigurationQuota team-a-rack2"),
+			}),
+	)
+})
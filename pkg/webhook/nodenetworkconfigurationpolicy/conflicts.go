@@ -0,0 +1,176 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nodetemplate"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+)
+
+// causeTypeWarning causes do not deny the request, they are returned as
+// admission warnings.
+const causeTypeWarning metav1.CauseType = "Warning"
+
+const maxConflictingNodes = 3
+
+// validatePolicyConflicts rejects policies configuring the same interfaces,
+// routes or dns as another policy with different values at the same nodes.
+// If it is not sure that both policies end up at the same node with those
+// values, because no node is selected by both yet, one of them uses capture
+// or node templates or one depends on the other, the conflict is a warning.
+// Removing or cleaning up what the other policy configures is a warning too,
+// like a policy detaching a bridge after another one created it.
+func validatePolicyConflicts(cli client.Client) validator {
+	return func(policy, _ *nmstatev1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := []metav1.StatusCause{}
+		if len(policy.Spec.DesiredState.Raw) == 0 {
+			return causes
+		}
+		state, err := schema.FromState(policy.Spec.DesiredState)
+		if err != nil {
+			// reported by validatePolicyDesiredState
+			return causes
+		}
+		policyList := nmstatev1.NodeNetworkConfigurationPolicyList{}
+		if err := cli.List(context.TODO(), &policyList); err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    causeTypeWarning,
+				Message: fmt.Sprintf("failed listing policies to check conflicts: %v", err),
+			})
+		}
+		sort.Slice(policyList.Items, func(i, j int) bool { return policyList.Items[i].Name < policyList.Items[j].Name })
+		for i := range policyList.Items {
+			other := &policyList.Items[i]
+			if other.Name == policy.Name || len(other.Spec.DesiredState.Raw) == 0 {
+				continue
+			}
+			nodeSelector, overlap := mergeNodeSelectors(policy.Spec.NodeSelector, other.Spec.NodeSelector)
+			if !overlap {
+				continue
+			}
+			otherState, err := schema.FromState(other.Spec.DesiredState)
+			if err != nil {
+				continue
+			}
+			conflicts := schema.Conflicts(state, otherState, fmt.Sprintf("policy %q", other.Name), field.NewPath("spec", "desiredState"))
+			if len(conflicts) == 0 {
+				continue
+			}
+			causeType, reason := conflictCertainty(cli, policy, other, nodeSelector)
+			for _, conflict := range conflicts {
+				conflictCauseType, conflictReason := causeType, reason
+				if conflict.Removal {
+					conflictCauseType = causeTypeWarning
+					conflictReason = "one of the policies removes it, they are expected to be applied one after the other"
+				}
+				causes = append(causes, metav1.StatusCause{
+					Type:    conflictCauseType,
+					Message: fmt.Sprintf("%s, %s", conflict.Err.Error(), conflictReason),
+					Field:   conflict.Err.Field,
+				})
+			}
+		}
+		return causes
+	}
+}
+
+// mergeNodeSelectors returns the selector matching the nodes selected by
+// both, or false if no node can match them.
+func mergeNodeSelectors(nodeSelector, other map[string]string) (map[string]string, bool) {
+	merged := map[string]string{}
+	for key, value := range nodeSelector {
+		merged[key] = value
+	}
+	for key, value := range other {
+		if mergedValue, found := merged[key]; found && mergedValue != value {
+			return nil, false
+		}
+		merged[key] = value
+	}
+	return merged, true
+}
+
+// conflictCertainty returns the cause type for a conflict between the
+// policies and the reason for it.
+func conflictCertainty(
+	cli client.Client,
+	policy, other *nmstatev1.NodeNetworkConfigurationPolicy,
+	nodeSelector map[string]string,
+) (metav1.CauseType, string) {
+	if len(policy.Spec.Capture) > 0 || len(other.Spec.Capture) > 0 {
+		return causeTypeWarning, "the captured values may differ"
+	}
+	if nodetemplate.HasTemplates(policy.Spec.DesiredState) || nodetemplate.HasTemplates(other.Spec.DesiredState) {
+		return causeTypeWarning, "the node template values may differ"
+	}
+	if dependsOn(policy, other.Name) || dependsOn(other, policy.Name) {
+		return causeTypeWarning, "the policies are applied in dependency order"
+	}
+	nodes := corev1.NodeList{}
+	if err := cli.List(context.TODO(), &nodes, client.MatchingLabels(nodeSelector)); err != nil {
+		return causeTypeWarning, fmt.Sprintf("failed listing the nodes selected by both policies: %v", err)
+	}
+	if len(nodes.Items) == 0 {
+		return causeTypeWarning, "no node is selected by both policies yet"
+	}
+	nodeNames := []string{}
+	for i := range nodes.Items {
+		nodeNames = append(nodeNames, nodes.Items[i].Name)
+	}
+	sort.Strings(nodeNames)
+	listedNodes := strings.Join(nodeNames, ", ")
+	if len(nodeNames) > maxConflictingNodes {
+		listedNodes = fmt.Sprintf("%s and %d more", strings.Join(nodeNames[:maxConflictingNodes], ", "), len(nodeNames)-maxConflictingNodes)
+	}
+	return metav1.CauseTypeFieldValueInvalid, fmt.Sprintf("both policies select nodes %s", listedNodes)
+}
+
+func dependsOn(policy *nmstatev1.NodeNetworkConfigurationPolicy, name string) bool {
+	for _, dependency := range policy.Spec.DependsOn {
+		if dependency == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWarnings returns the causes that are not warnings and the messages
+// of the warnings.
+func splitWarnings(causes []metav1.StatusCause) ([]metav1.StatusCause, []string) {
+	errCauses := []metav1.StatusCause{}
+	warnings := []string{}
+	for _, cause := range causes {
+		if cause.Type == causeTypeWarning {
+			warnings = append(warnings, cause.Message)
+			continue
+		}
+		errCauses = append(errCauses, cause)
+	}
+	return errCauses, warnings
+}
@@ -90,14 +90,23 @@ func validatePolicyHandler(
 			return admission.Allowed("validation not needed")
 		}
 
-		errCauses := []metav1.StatusCause{}
+		causes := []metav1.StatusCause{}
 		for _, validate := range validators {
-			errCauses = append(errCauses, validate(&policy, &currentPolicy)...)
+			causes = append(causes, validate(&policy, &currentPolicy)...)
 		}
+		errCauses, warnings := splitWarnings(causes)
 		if len(errCauses) > 0 {
-			return admission.Denied(handlePolicyCauses(errCauses, policy.Name))
+			response := admission.Denied(handlePolicyCauses(errCauses, policy.Name)).WithWarnings(warnings...)
+			// Keep the causes so clients get the wrong fields
+			response.Result.Details = &metav1.StatusDetails{
+				Name:   policy.Name,
+				Group:  nmstatev1.GroupVersion.Group,
+				Kind:   "NodeNetworkConfigurationPolicy",
+				Causes: errCauses,
+			}
+			return response
 		}
-		return admission.Allowed("")
+		return admission.Allowed("").WithWarnings(warnings...)
 	}
 }
 
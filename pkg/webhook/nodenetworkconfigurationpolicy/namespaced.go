@@ -0,0 +1,145 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/tenancy"
+)
+
+// namespacedValidator validates a namespaced policy
+type namespacedValidator func(*nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy) []metav1.StatusCause
+
+func validateNamespacedPolicyHandler(validators ...namespacedValidator) admission.HandlerFunc {
+	log := logf.Log.WithName("webhook/namespacednodenetworkconfigurationpolicy/validator")
+	return func(ctx context.Context, req webhook.AdmissionRequest) webhook.AdmissionResponse {
+		policy := nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{}
+		if err := json.Unmarshal(req.Object.Raw, &policy); err != nil {
+			return admission.Errored(http.StatusInternalServerError, errors.Wrapf(err, "failed decoding policy: %s", string(req.Object.Raw)))
+		}
+		if req.Operation == admissionv1.Update {
+			currentPolicy := nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{}
+			if err := json.Unmarshal(req.OldObject.Raw, &currentPolicy); err != nil {
+				return admission.Errored(http.StatusInternalServerError,
+					errors.Wrapf(err, "failed decoding policy: %s", string(req.OldObject.Raw)))
+			}
+			if reflect.DeepEqual(policy.Spec, currentPolicy.Spec) {
+				return admission.Allowed("validation not needed")
+			}
+		}
+
+		causes := []metav1.StatusCause{}
+		for _, validate := range validators {
+			causes = append(causes, validate(&policy)...)
+		}
+		errCauses, warnings := splitWarnings(causes)
+		if len(errCauses) > 0 {
+			messages := []string{}
+			for _, cause := range errCauses {
+				messages = append(messages, fmt.Sprintf("message: %s. ", cause.Message))
+			}
+			errMsg := fmt.Sprintf("failed to admit NamespacedNodeNetworkConfigurationPolicy %s/%s: %s",
+				policy.Namespace, policy.Name, strings.Join(messages, ""))
+			log.Info(errMsg)
+			response := admission.Denied(errMsg).WithWarnings(warnings...)
+			response.Result.Details = &metav1.StatusDetails{
+				Name:   policy.Name,
+				Group:  nmstatev1beta1.GroupVersion.Group,
+				Kind:   "NamespacedNodeNetworkConfigurationPolicy",
+				Causes: errCauses,
+			}
+			return response
+		}
+		return admission.Allowed("").WithWarnings(warnings...)
+	}
+}
+
+// validateNamespacedPolicyQuota rejects namespaced policies selecting nodes
+// or configuring interfaces not granted by the namespace quotas.
+func validateNamespacedPolicyQuota(cli client.Client) namespacedValidator {
+	return func(policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		quotaList := nmstatev1beta1.NodeNetworkConfigurationQuotaList{}
+		if err := cli.List(context.TODO(), &quotaList); err != nil {
+			return []metav1.StatusCause{{
+				Message: fmt.Sprintf("failed listing NodeNetworkConfigurationQuotas: %v", err),
+			}}
+		}
+		return fieldErrorsToCauses(tenancy.Validate(quotaList.Items, policy.Namespace, &policy.Spec, field.NewPath("spec")))
+	}
+}
+
+// validateNamespacedPolicyName rejects namespaced policies whose cluster
+// policy name is not valid.
+func validateNamespacedPolicyName(policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	clusterPolicyName := tenancy.ClusterPolicyName(policy.Namespace, policy.Name)
+	if validationErrors := validation.IsValidLabelValue(clusterPolicyName); len(validationErrors) > 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid policy name: %q: %s", clusterPolicyName, strings.Join(validationErrors, "; ")),
+			Field:   "name",
+		})
+	}
+	return causes
+}
+
+// asClusterPolicy validates the namespaced policy with a cluster policy
+// validator, as the cluster policy applying it.
+func asClusterPolicy(validate validator) namespacedValidator {
+	return func(policy *nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		clusterPolicy := nmstatev1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: tenancy.ClusterPolicyName(policy.Namespace, policy.Name)},
+			Spec:       tenancy.ClusterPolicySpec(policy.Namespace, &policy.Spec),
+		}
+		return validate(&clusterPolicy, &nmstatev1.NodeNetworkConfigurationPolicy{})
+	}
+}
+
+func validateNamespacedPolicyHook(cli client.Client) *webhook.Admission {
+	return &webhook.Admission{
+		Handler: validateNamespacedPolicyHandler(
+			validateNamespacedPolicyName,
+			validateNamespacedPolicyQuota(cli),
+			asClusterPolicy(validatePolicyProbes),
+			asClusterPolicy(validatePolicyRollout),
+			asClusterPolicy(validatePolicyMaintenanceWindow),
+			asClusterPolicy(validatePolicyNodeTemplates(cli)),
+			asClusterPolicy(validatePolicyDesiredState(cli)),
+			asClusterPolicy(validatePolicyProtected(cli)),
+			asClusterPolicy(validatePolicyConflicts(cli)),
+		),
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func requestForNamespacedPolicy(policy nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy) webhook.AdmissionRequest {
+	data, err := json.Marshal(policy)
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	request := webhook.AdmissionRequest{}
+	request.Operation = admissionv1.Create
+	request.Object = runtime.RawExtension{
+		Raw: data,
+	}
+	return request
+}
+
+var _ = Describe("NamespacedNodeNetworkConfigurationPolicy Validation Admission Webhook", func() {
+	var (
+		cli    client.Client
+		policy nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy
+		quota  = nmstatev1beta1.NodeNetworkConfigurationQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+			Spec: shared.NodeNetworkConfigurationQuotaSpec{
+				Namespaces:   []string{"team-a"},
+				NodeSelector: map[string]string{"pool": "team-a"},
+				Interfaces:   []string{"eth1", "eth1.*"},
+			},
+		}
+	)
+	BeforeEach(func() {
+		policy = nmstatev1beta1.NamespacedNodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "vlan100"},
+			Spec: shared.NodeNetworkConfigurationPolicySpec{
+				NodeSelector: map[string]string{"pool": "team-a"},
+				DesiredState: shared.NewState(`
+interfaces:
+- name: eth1.100
+  type: vlan
+  state: up
+  vlan:
+    base-iface: eth1
+    id: 100
+`),
+			},
+		}
+		s := runtime.NewScheme()
+		s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
+		s.AddKnownTypes(nmstatev1.GroupVersion,
+			&nmstatev1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1.NodeNetworkConfigurationPolicyList{},
+			&nmstatev1.NMStateList{},
+		)
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkStateList{},
+			&nmstatev1beta1.NodeIPPool{},
+			&nmstatev1beta1.NodeNetworkConfigurationQuota{},
+			&nmstatev1beta1.NodeNetworkConfigurationQuotaList{},
+		)
+		cli = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(
+			&quota,
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node01", Labels: map[string]string{"pool": "team-a"}}},
+		).Build()
+	})
+	It("should allow namespaced policies granted by the namespace quota", func() {
+		response := validateNamespacedPolicyHook(cli).Handle(context.TODO(), requestForNamespacedPolicy(policy))
+		Expect(response.Allowed).To(BeTrue(), string(response.Result.Reason))
+	})
+	It("should reject namespaced policies selecting nodes not granted by the namespace quota", func() {
+		policy.Spec.NodeSelector = map[string]string{"role": "worker"}
+		response := validateNamespacedPolicyHook(cli).Handle(context.TODO(), requestForNamespacedPolicy(policy))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(Equal("failed to admit NamespacedNodeNetworkConfigurationPolicy team-a/vlan100: " +
+			"message: spec.nodeSelector: Forbidden: has to include pool=team-a granted by NodeNetworkConfigurationQuota team-a. "))
+	})
+	It("should reject namespaced policies configuring interfaces not granted by the namespace quota", func() {
+		policy.Spec.DesiredState = shared.NewState(`
+interfaces:
+- name: eth0
+  type: ethernet
+  state: down
+`)
+		response := validateNamespacedPolicyHook(cli).Handle(context.TODO(), requestForNamespacedPolicy(policy))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Details.Causes).To(ConsistOf(metav1.StatusCause{
+			Type:    metav1.CauseType(field.ErrorTypeForbidden),
+			Message: "spec.desiredState.interfaces[0].name: Forbidden: interface eth0 is not granted by NodeNetworkConfigurationQuota team-a",
+			Field:   "spec.desiredState.interfaces[0].name",
+		}))
+	})
+	It("should reject namespaced policies in namespaces without quota", func() {
+		policy.Namespace = "team-b"
+		response := validateNamespacedPolicyHook(cli).Handle(context.TODO(), requestForNamespacedPolicy(policy))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(ContainSubstring("namespace team-b has no NodeNetworkConfigurationQuota"))
+	})
+	It("should reject namespaced policies with a desired state not valid for nmstate", func() {
+		policy.Spec.DesiredState = shared.NewState(`
+interfaces:
+- name: eth1.100
+  type: vlan
+  state: up
+  mtu: foo
+  vlan:
+    base-iface: eth1
+    id: 100
+`)
+		response := validateNamespacedPolicyHook(cli).Handle(context.TODO(), requestForNamespacedPolicy(policy))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(ContainSubstring(`spec.desiredState.interfaces[0].mtu: Invalid value: "foo": must be a number`))
+	})
+	It("should skip the validation when the spec does not change", func() {
+		policy.Namespace = "team-b"
+		request := requestForNamespacedPolicy(policy)
+		request.Operation = admissionv1.Update
+		request.OldObject = request.Object
+		response := validateNamespacedPolicyHook(cli).Handle(context.TODO(), request)
+		Expect(response.Allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nodetemplate"
+)
+
+// validatePolicyNodeTemplates rejects node templates with wrong syntax, the
+// selected nodes missing the templated values are warnings since the nodes
+// may be labeled after the policy is created.
+func validatePolicyNodeTemplates(cli client.Client) validator {
+	return func(policy, _ *nmstatev1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := fieldErrorsToCauses(nodetemplate.Validate(policy.Spec.DesiredState, field.NewPath("spec", "desiredState")))
+		if len(causes) > 0 || !nodetemplate.HasTemplates(policy.Spec.DesiredState) {
+			return causes
+		}
+		nodes, err := selectedNodes(cli, policy)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    causeTypeWarning,
+				Message: fmt.Sprintf("failed listing the selected nodes to render node templates: %v", err),
+			})
+		}
+		failures := 0
+		for i := range nodes {
+			if _, err := nodetemplate.Render(policy.Spec.DesiredState, &nodes[i]); err != nil {
+				failures++
+				if failures > maxConflictingNodes {
+					continue
+				}
+				causes = append(causes, metav1.StatusCause{
+					Type:    causeTypeWarning,
+					Message: fmt.Sprintf("failed rendering node templates: %v", err),
+					Field:   "spec.desiredState",
+				})
+			}
+		}
+		if failures > maxConflictingNodes {
+			causes = append(causes, metav1.StatusCause{
+				Type:    causeTypeWarning,
+				Message: fmt.Sprintf("failed rendering node templates at %d more nodes", failures-maxConflictingNodes),
+				Field:   "spec.desiredState",
+			})
+		}
+		return causes
+	}
+}
+
+// renderNodeTemplatesAtSelectedNode renders the node templates with the
+// first selected node having all the templated values, so the desired state
+// can be validated. It returns false if there is no such node.
+func renderNodeTemplatesAtSelectedNode(cli client.Client, policy *nmstatev1.NodeNetworkConfigurationPolicy) (shared.State, bool) {
+	if !nodetemplate.HasTemplates(policy.Spec.DesiredState) {
+		return policy.Spec.DesiredState, true
+	}
+	nodes, err := selectedNodes(cli, policy)
+	if err != nil {
+		return policy.Spec.DesiredState, false
+	}
+	for i := range nodes {
+		if desiredState, err := nodetemplate.Render(policy.Spec.DesiredState, &nodes[i]); err == nil {
+			return desiredState, true
+		}
+	}
+	return policy.Spec.DesiredState, false
+}
+
+func selectedNodes(cli client.Client, policy *nmstatev1.NodeNetworkConfigurationPolicy) ([]corev1.Node, error) {
+	nodes := corev1.NodeList{}
+	if err := cli.List(context.TODO(), &nodes, client.MatchingLabels(policy.Spec.NodeSelector)); err != nil {
+		return nil, err
+	}
+	sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+	return nodes.Items, nil
+}
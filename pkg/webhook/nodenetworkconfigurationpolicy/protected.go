@@ -0,0 +1,77 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenetworkconfigurationpolicy
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/protection"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
+)
+
+// validatePolicyProtected rejects policies modifying the interfaces and
+// routes protected at the NMState, unless they have the override
+// annotation. The protection limited to some nodes applies if any of the
+// selected nodes has their labels, the handler checks it again at nodes
+// labeled later.
+func validatePolicyProtected(cli client.Client) validator {
+	return func(policy, _ *nmstatev1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := []metav1.StatusCause{}
+		if policy.OverridesProtected() || len(policy.Spec.DesiredState.Raw) == 0 {
+			return causes
+		}
+		nmstate, err := protection.Get(cli)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Message: fmt.Sprintf("failed retrieving protected interfaces: %v", err),
+			})
+		}
+		if !protection.HasProtected(nmstate) {
+			return causes
+		}
+		state, err := schema.FromState(policy.Spec.DesiredState)
+		if err != nil {
+			// reported by validatePolicyDesiredState
+			return causes
+		}
+		return fieldErrorsToCauses(protection.Validate(nmstate, matchesSelectedNodes(cli, policy), state, field.NewPath("spec", "desiredState")))
+	}
+}
+
+// matchesSelectedNodes returns true for node selectors matching any of the
+// policy selected nodes, if they cannot be listed every node selector
+// matches.
+func matchesSelectedNodes(cli client.Client, policy *nmstatev1.NodeNetworkConfigurationPolicy) protection.NodeSelectorMatchFunc {
+	nodes, err := selectedNodes(cli, policy)
+	return func(nodeSelector map[string]string) bool {
+		if len(nodeSelector) == 0 || err != nil {
+			return true
+		}
+		for i := range nodes {
+			if protection.MatchesLabels(nodes[i].Labels)(nodeSelector) {
+				return true
+			}
+		}
+		return false
+	}
+}
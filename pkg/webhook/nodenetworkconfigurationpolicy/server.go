@@ -47,5 +47,6 @@ func Add(mgr manager.Manager) error {
 	server.Register("/nodenetworkconfigurationpolicies-timestamp-mutate", setTimestampAnnotationHook())
 	server.Register("/nodenetworkconfigurationpolicies-update-validate", validatePolicyUpdateHook(mgr.GetClient()))
 	server.Register("/nodenetworkconfigurationpolicies-create-validate", validatePolicyCreateHook(mgr.GetClient()))
+	server.Register("/namespacednodenetworkconfigurationpolicies-validate", validateNamespacedPolicyHook(mgr.GetClient()))
 	return mgr.Add(&server)
 }
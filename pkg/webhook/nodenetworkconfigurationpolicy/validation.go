@@ -18,19 +18,35 @@ limitations under the License.
 package nodenetworkconfigurationpolicy
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
 	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/ippool"
+	"github.com/nmstate/kubernetes-nmstate/pkg/maintenance"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmpolicy"
+	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
+	"github.com/nmstate/kubernetes-nmstate/pkg/schema"
 )
 
 func onPolicySpecChange(
@@ -127,6 +143,405 @@ func validatePolicyCaptureNotModified(
 	return causes
 }
 
+func validatePolicyProbes(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	_ *nmstatev1.NodeNetworkConfigurationPolicy,
+) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	probes := policy.Spec.Probes
+	if probes == nil {
+		return causes
+	}
+	probeNames := map[string]bool{}
+	for i := range probes.Custom {
+		customProbe := probes.Custom[i]
+		field := fmt.Sprintf("spec.probes.custom[%d]", i)
+		if customProbe.Name == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: "custom probe name is required",
+				Field:   field + ".name",
+			})
+		} else if probeNames[customProbe.Name] {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: fmt.Sprintf("duplicated custom probe name %q", customProbe.Name),
+				Field:   field + ".name",
+			})
+		}
+		probeNames[customProbe.Name] = true
+
+		if customProbe.Timeout != nil && customProbe.Timeout.Duration <= 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid custom probe timeout %q: must be positive", customProbe.Timeout.Duration),
+				Field:   field + ".timeout",
+			})
+		}
+
+		probeTypes := 0
+		for _, isSet := range []bool{customProbe.Ping != nil, customProbe.TCP != nil, customProbe.HTTP != nil, customProbe.DNS != nil} {
+			if isSet {
+				probeTypes++
+			}
+		}
+		if probeTypes != 1 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("custom probe %q must have exactly one of ping, tcp, http or dns", customProbe.Name),
+				Field:   field,
+			})
+		}
+		if customProbe.Ping != nil && net.ParseIP(customProbe.Ping.Address) == nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid ping address %q", customProbe.Ping.Address),
+				Field:   field + ".ping.address",
+			})
+		}
+		if customProbe.TCP != nil && (customProbe.TCP.Port <= 0 || customProbe.TCP.Port > 65535) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid tcp port %d", customProbe.TCP.Port),
+				Field:   field + ".tcp.port",
+			})
+		}
+		if customProbe.HTTP != nil {
+			if _, err := url.ParseRequestURI(customProbe.HTTP.URL); err != nil {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: fmt.Sprintf("invalid http url %q: %v", customProbe.HTTP.URL, err),
+					Field:   field + ".http.url",
+				})
+			}
+		}
+	}
+	if total := probe.CustomProbesTotalTimeout(probes); total > probe.MaxCustomProbesTotalTimeout {
+		causes = append(causes, metav1.StatusCause{
+			Type: metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("custom probes total timeout %s exceeds the maximum of %s, probes without timeout take %s",
+				total, probe.MaxCustomProbesTotalTimeout, probe.DefaultCustomProbeTimeout),
+			Field: "spec.probes.custom",
+		})
+	}
+	return causes
+}
+
+func validatePolicyRollout(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	_ *nmstatev1.NodeNetworkConfigurationPolicy,
+) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	rollout := policy.Spec.Rollout
+	if rollout == nil {
+		return causes
+	}
+	if len(rollout.Waves) == 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: "rollout needs at least one wave",
+			Field:   "spec.rollout.waves",
+		})
+	}
+	if rollout.SoakDuration != nil && rollout.SoakDuration.Duration < 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid rollout soak duration %q: must not be negative", rollout.SoakDuration.Duration),
+			Field:   "spec.rollout.soakDuration",
+		})
+	}
+	waveNames := map[string]bool{}
+	for i := range rollout.Waves {
+		wave := rollout.Waves[i]
+		field := fmt.Sprintf("spec.rollout.waves[%d]", i)
+		if wave.Name != "" && waveNames[wave.Name] {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: fmt.Sprintf("duplicated rollout wave name %q", wave.Name),
+				Field:   field + ".name",
+			})
+		}
+		waveNames[wave.Name] = true
+
+		if len(wave.NodeSelector) == 0 && wave.Nodes == nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: "rollout wave needs nodeSelector or nodes",
+				Field:   field,
+			})
+		}
+		if wave.Nodes != nil {
+			nodes, err := intstr.GetScaledValueFromIntOrPercent(wave.Nodes, 100, true)
+			if err != nil || nodes < 0 {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: fmt.Sprintf("invalid rollout wave nodes %q: must be a non negative number or percentage", wave.Nodes.String()),
+					Field:   field + ".nodes",
+				})
+			}
+		}
+	}
+	return causes
+}
+
+func validatePolicyMaintenanceWindow(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	_ *nmstatev1.NodeNetworkConfigurationPolicy,
+) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	window := policy.Spec.MaintenanceWindow
+	if window == nil {
+		return causes
+	}
+	if _, err := maintenance.ParseSchedule(window.Schedule); err != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid maintenance window schedule: %v", err),
+			Field:   "spec.maintenanceWindow.schedule",
+		})
+	}
+	if window.Duration.Duration <= 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid maintenance window duration %q: must be positive", window.Duration.Duration),
+			Field:   "spec.maintenanceWindow.duration",
+		})
+	}
+	if _, err := maintenance.Location(window); err != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid maintenance window time zone %q: %v", window.TimeZone, err),
+			Field:   "spec.maintenanceWindow.timeZone",
+		})
+	}
+	return causes
+}
+
+func validatePolicyDrain(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	_ *nmstatev1.NodeNetworkConfigurationPolicy,
+) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	drainTimeout := policy.Spec.DrainTimeout
+	if drainTimeout == nil {
+		return causes
+	}
+	if !policy.Spec.DrainBeforeApply {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: "drain timeout needs drainBeforeApply",
+			Field:   "spec.drainTimeout",
+		})
+	}
+	if drainTimeout.Duration <= 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid drain timeout %q: must be positive", drainTimeout.Duration),
+			Field:   "spec.drainTimeout",
+		})
+	}
+	return causes
+}
+
+// validatePolicyDesiredState rejects desired states that do not match the
+// nmstate schema. Node templates are rendered with one of the selected nodes
+// and the values referencing captures are opaque, they are resolved at the
+// nodes.
+func validatePolicyDesiredState(cli client.Client) validator {
+	return func(policy, _ *nmstatev1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := []metav1.StatusCause{}
+		if len(policy.Spec.DesiredState.Raw) == 0 {
+			return causes
+		}
+		desiredState, rendered := renderNodeTemplatesAtSelectedNode(cli, policy)
+		if !rendered {
+			// reported by validatePolicyNodeTemplates
+			return causes
+		}
+		desiredState, err := ippool.Render(desiredState, firstPoolAddress(cli))
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid desired state: %v", err),
+				Field:   "spec.desiredState",
+			})
+		}
+		fldPath := field.NewPath("spec", "desiredState")
+		desiredState, omitted, err := nmpolicy.OmitCaptureReferences(desiredState, fldPath)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid desired state: %v", err),
+				Field:   "spec.desiredState",
+			})
+		}
+		state, err := schema.FromState(desiredState)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid desired state: %v", err),
+				Field:   "spec.desiredState",
+			})
+		}
+		return fieldErrorsToCauses(nmpolicy.FilterOmitted(schema.Validate(state, fldPath, missingInterfacesAt(cli, policy)), omitted))
+	}
+}
+
+// firstPoolAddress resolves the NodeIPPool references with the first
+// address of the pool so the rendered desired state can be validated, the
+// nodes get their own address when applying the policy.
+func firstPoolAddress(cli client.Client) func(pool string) (ippool.Address, error) {
+	return func(pool string) (ippool.Address, error) {
+		instance := &nmstatev1beta1.NodeIPPool{}
+		if err := cli.Get(context.TODO(), types.NamespacedName{Name: pool}, instance); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ippool.Address{}, errors.New("not found")
+			}
+			return ippool.Address{}, err
+		}
+		poolRange, err := ippool.NewRange(instance.Spec.Range)
+		if err != nil {
+			return ippool.Address{}, err
+		}
+		return poolRange.Address(0)
+	}
+}
+
+// validatePolicyCapture rejects capture expressions with wrong syntax or
+// referencing undefined captures, and desired states referencing them.
+func validatePolicyCapture(
+	policy *nmstatev1.NodeNetworkConfigurationPolicy,
+	_ *nmstatev1.NodeNetworkConfigurationPolicy,
+) []metav1.StatusCause {
+	return fieldErrorsToCauses(nmpolicy.ValidateCapture(policy.Spec.Capture, policy.Spec.DesiredState, field.NewPath("spec")))
+}
+
+func fieldErrorsToCauses(errs field.ErrorList) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	for _, err := range errs {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseType(err.Type),
+			Message: err.Error(),
+			Field:   err.Field,
+		})
+	}
+	return causes
+}
+
+// missingInterfacesAt looks for the interfaces referenced by the desired
+// state at the NodeNetworkStates of the nodes selected by the policy, nodes
+// without NodeNetworkState are not checked.
+func missingInterfacesAt(cli client.Client, policy *nmstatev1.NodeNetworkConfigurationPolicy) schema.MissingInterfaceFunc {
+	nodes := corev1.NodeList{}
+	if err := cli.List(context.TODO(), &nodes, client.MatchingLabels(policy.Spec.NodeSelector)); err != nil {
+		return nil
+	}
+	nodeNetworkStates := nmstatev1beta1.NodeNetworkStateList{}
+	if err := cli.List(context.TODO(), &nodeNetworkStates); err != nil {
+		return nil
+	}
+	selectedNodes := sets.New[string]()
+	for i := range nodes.Items {
+		selectedNodes.Insert(nodes.Items[i].Name)
+	}
+	interfacesByNode := map[string]sets.Set[string]{}
+	for i := range nodeNetworkStates.Items {
+		nns := &nodeNetworkStates.Items[i]
+		if !selectedNodes.Has(nns.Name) {
+			continue
+		}
+		currentState, err := schema.FromState(nns.Status.CurrentState)
+		if err != nil {
+			continue
+		}
+		interfaces := sets.New[string]()
+		for j := range currentState.Interfaces {
+			interfaces.Insert(currentState.Interfaces[j].Name)
+		}
+		interfacesByNode[nns.Name] = interfaces
+	}
+	return func(name string) []string {
+		missingAt := []string{}
+		for nodeName, interfaces := range interfacesByNode {
+			if !interfaces.Has(name) {
+				missingAt = append(missingAt, nodeName)
+			}
+		}
+		return missingAt
+	}
+}
+
+// validatePolicyDependencies rejects policies depending on themselves or
+// closing a cycle with the dependencies of the existing policies.
+func validatePolicyDependencies(cli client.Client) validator {
+	return func(policy, _ *nmstatev1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := []metav1.StatusCause{}
+		if len(policy.Spec.DependsOn) == 0 {
+			return causes
+		}
+		for i, dependencyName := range policy.Spec.DependsOn {
+			if dependencyName == policy.Name {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: fmt.Sprintf("policy %s cannot depend on itself", policy.Name),
+					Field:   fmt.Sprintf("spec.dependsOn[%d]", i),
+				})
+			}
+		}
+		if len(causes) > 0 {
+			return causes
+		}
+
+		policyList := nmstatev1.NodeNetworkConfigurationPolicyList{}
+		err := cli.List(context.TODO(), &policyList)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Message: fmt.Sprintf("failed listing policies to check dependencies: %v", err),
+			})
+		}
+		dependencies := map[string][]string{}
+		for i := range policyList.Items {
+			dependencies[policyList.Items[i].Name] = policyList.Items[i].Spec.DependsOn
+		}
+		dependencies[policy.Name] = policy.Spec.DependsOn
+
+		cycle := findDependencyCycle(policy.Name, dependencies, []string{policy.Name})
+		if cycle != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("policy dependency cycle: %s", strings.Join(cycle, " -> ")),
+				Field:   "spec.dependsOn",
+			})
+		}
+		return causes
+	}
+}
+
+// findDependencyCycle walks the dependencies depth first from the last
+// policy at path returning the path that leads back to root, if any.
+func findDependencyCycle(root string, dependencies map[string][]string, path []string) []string {
+	for _, dependencyName := range dependencies[path[len(path)-1]] {
+		if dependencyName == root {
+			return append(path, root)
+		}
+		visited := false
+		for _, policyName := range path {
+			if policyName == dependencyName {
+				visited = true
+				break
+			}
+		}
+		if visited {
+			continue
+		}
+		cycle := findDependencyCycle(root, dependencies, append(path[:len(path):len(path)], dependencyName))
+		if cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
 func validatePolicyUpdateHook(cli client.Client) *webhook.Admission {
 	return &webhook.Admission{
 		Handler: admission.MultiValidatingHandler(
@@ -136,6 +551,16 @@ func validatePolicyUpdateHook(cli client.Client) *webhook.Admission {
 				validatePolicyNotInProgressHook,
 				validatePolicyNodeSelector,
 				validatePolicyCaptureNotModified,
+				validatePolicyProbes,
+				validatePolicyRollout,
+				validatePolicyMaintenanceWindow,
+				validatePolicyDrain,
+				validatePolicyCapture,
+				validatePolicyNodeTemplates(cli),
+				validatePolicyDesiredState(cli),
+				validatePolicyProtected(cli),
+				validatePolicyConflicts(cli),
+				validatePolicyDependencies(cli),
 			),
 		),
 	}
@@ -148,6 +573,16 @@ func validatePolicyCreateHook(cli client.Client) *webhook.Admission {
 				cli,
 				onCreate,
 				validatePolicyName,
+				validatePolicyProbes,
+				validatePolicyRollout,
+				validatePolicyMaintenanceWindow,
+				validatePolicyDrain,
+				validatePolicyCapture,
+				validatePolicyNodeTemplates(cli),
+				validatePolicyDesiredState(cli),
+				validatePolicyProtected(cli),
+				validatePolicyConflicts(cli),
+				validatePolicyDependencies(cli),
 			),
 		),
 	}
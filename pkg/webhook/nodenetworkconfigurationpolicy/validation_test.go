@@ -18,13 +18,26 @@ limitations under the License.
 package nodenetworkconfigurationpolicy
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
 
 	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1 "github.com/nmstate/kubernetes-nmstate/api/v1"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	"github.com/nmstate/kubernetes-nmstate/pkg/policyconditions"
 )
 
@@ -48,8 +61,125 @@ func p(
 	}
 }
 
+func dependentPolicy(name string, dependsOn ...string) nmstatev1.NodeNetworkConfigurationPolicy {
+	return nmstatev1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			DependsOn: dependsOn,
+		},
+	}
+}
+
+func clientWithPolicies(policies ...nmstatev1.NodeNetworkConfigurationPolicy) client.Client {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NodeNetworkConfigurationPolicy{}, &nmstatev1.NodeNetworkConfigurationPolicyList{})
+	objs := []runtime.Object{}
+	for i := range policies {
+		objs = append(objs, &policies[i])
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+}
+
+func clientWithNodeNetworkStates(interfacesByNode map[string][]string) client.Client {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
+	s.AddKnownTypes(nmstatev1beta1.GroupVersion, &nmstatev1beta1.NodeNetworkState{}, &nmstatev1beta1.NodeNetworkStateList{})
+	objs := []runtime.Object{}
+	for nodeName, interfaces := range interfacesByNode {
+		currentState := "interfaces:\n"
+		for _, iface := range interfaces {
+			currentState += fmt.Sprintf("- name: %s\n", iface)
+		}
+		objs = append(objs,
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName, Labels: map[string]string{"node": nodeName}}},
+			&nmstatev1beta1.NodeNetworkState{
+				ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+				Status:     shared.NodeNetworkStateStatus{CurrentState: shared.NewState(currentState)},
+			},
+		)
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+}
+
+func clientWithNodeIPPools(pools ...nmstatev1beta1.NodeIPPool) client.Client {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
+	s.AddKnownTypes(nmstatev1beta1.GroupVersion, &nmstatev1beta1.NodeNetworkStateList{}, &nmstatev1beta1.NodeIPPool{})
+	objs := []runtime.Object{}
+	for i := range pools {
+		objs = append(objs, &pools[i])
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+}
+
+func statePolicy(name string, nodeSelector map[string]string, desiredState string) nmstatev1.NodeNetworkConfigurationPolicy {
+	return nmstatev1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			NodeSelector: nodeSelector,
+			DesiredState: shared.NewState(desiredState),
+		},
+	}
+}
+
+func clientWithNodesAndPolicies(nodes map[string]map[string]string, policies ...nmstatev1.NodeNetworkConfigurationPolicy) client.Client {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
+	s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NodeNetworkConfigurationPolicy{}, &nmstatev1.NodeNetworkConfigurationPolicyList{})
+	objs := []runtime.Object{}
+	for nodeName, labels := range nodes {
+		objs = append(objs, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName, Labels: labels}})
+	}
+	for i := range policies {
+		objs = append(objs, &policies[i])
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+}
+
+func clientWithNodesAndProtected(nodes map[string]map[string]string, protectedInterfaces ...nmstatev1.ProtectedInterface) client.Client {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
+	s.AddKnownTypes(nmstatev1.GroupVersion, &nmstatev1.NMState{}, &nmstatev1.NMStateList{})
+	objs := []runtime.Object{&nmstatev1.NMState{
+		ObjectMeta: metav1.ObjectMeta{Name: "nmstate"},
+		Spec:       nmstatev1.NMStateSpec{ProtectedInterfaces: protectedInterfaces},
+	}}
+	for nodeName, labels := range nodes {
+		objs = append(objs, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName, Labels: labels}})
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build()
+}
+
 var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 	var allNodes = map[string]string{}
+	var canaryNodes = intstr.FromInt(1)
+	var wrongNodes = intstr.FromString("foo")
+	var workerNodes = map[string]map[string]string{
+		"node01": {"role": "worker", "rack": "r1"},
+		"node02": {"role": "worker", "rack": "r2"},
+	}
+	const eth1Up = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+`
+	const eth1Down = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: down
+`
+	const eth1Absent = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: absent
+`
 	var testPolicy = nmstatev1.NodeNetworkConfigurationPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "testPolicy",
@@ -86,6 +216,12 @@ var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 			validationFn:     validatePolicyNotInProgressHook,
 			validationResult: []metav1.StatusCause{},
 		}),
+		Entry("current policy paused", ValidationWebhookCase{
+			policy:           testPolicy,
+			currentPolicy:    p(allNodes, policyconditions.SetPolicyPaused, ""),
+			validationFn:     validatePolicyNotInProgressHook,
+			validationResult: []metav1.StatusCause{},
+		}),
 		Entry("current policy not matching", ValidationWebhookCase{
 			policy:           testPolicy,
 			currentPolicy:    p(allNodes, policyconditions.SetPolicyNotMatching, ""),
@@ -201,6 +337,609 @@ var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 				Field:   "capture",
 			}},
 		}),
+		Entry("policy depends on existing policies without cycles", ValidationWebhookCase{
+			policy:           dependentPolicy("vlan", "bond"),
+			validationFn:     validatePolicyDependencies(clientWithPolicies(dependentPolicy("bond", "nics"), dependentPolicy("nics"))),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy depends on itself", ValidationWebhookCase{
+			policy:       dependentPolicy("vlan", "bond", "vlan"),
+			validationFn: validatePolicyDependencies(clientWithPolicies()),
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "policy vlan cannot depend on itself",
+					Field:   "spec.dependsOn[1]",
+				},
+			},
+		}),
+		Entry("policy closes a dependency cycle", ValidationWebhookCase{
+			policy: dependentPolicy("nics", "vlan"),
+			validationFn: validatePolicyDependencies(
+				clientWithPolicies(dependentPolicy("vlan", "bond"), dependentPolicy("bond", "nics"), dependentPolicy("nics")),
+			),
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "policy dependency cycle: nics -> vlan -> bond -> nics",
+					Field:   "spec.dependsOn",
+				},
+			},
+		}),
+		Entry("policy has valid custom probes", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Probes: &shared.NodeNetworkConfigurationPolicyProbes{
+						DisableBuiltIn: []shared.BuiltInProbeName{shared.BuiltInProbeDNS},
+						Custom: []shared.CustomProbe{
+							{Name: "storage", TCP: &shared.TCPProbe{Host: "192.168.1.10", Port: 3260}},
+							{Name: "gw", Ping: &shared.PingProbe{Address: "192.168.1.1", Interface: "eth1"}},
+						},
+					},
+				},
+			},
+			validationFn:     validatePolicyProbes,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has custom probes with duplicated names and wrong fields", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Probes: &shared.NodeNetworkConfigurationPolicyProbes{
+						Custom: []shared.CustomProbe{
+							{Name: "storage", TCP: &shared.TCPProbe{Host: "192.168.1.10", Port: 0}},
+							{Name: "storage", Ping: &shared.PingProbe{Address: "foo"}, DNS: &shared.DNSProbe{Name: "example.com"}},
+						},
+					},
+				},
+			},
+			validationFn: validatePolicyProbes,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid tcp port 0",
+					Field:   "spec.probes.custom[0].tcp.port",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: "duplicated custom probe name \"storage\"",
+					Field:   "spec.probes.custom[1].name",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "custom probe \"storage\" must have exactly one of ping, tcp, http or dns",
+					Field:   "spec.probes.custom[1]",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid ping address \"foo\"",
+					Field:   "spec.probes.custom[1].ping.address",
+				},
+			},
+		}),
+		Entry("policy has custom probes exceeding the total timeout", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Probes: &shared.NodeNetworkConfigurationPolicyProbes{
+						Custom: []shared.CustomProbe{
+							{Name: "storage", TCP: &shared.TCPProbe{Host: "192.168.1.10", Port: 3260}},
+							{Name: "gw", Ping: &shared.PingProbe{Address: "192.168.1.1"}},
+							{Name: "registry", HTTP: &shared.HTTPProbe{URL: "http://192.168.1.20"},
+								Timeout: &metav1.Duration{Duration: 5 * time.Minute}},
+						},
+					},
+				},
+			},
+			validationFn: validatePolicyProbes,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "custom probes total timeout 9m0s exceeds the maximum of 8m0s, probes without timeout take 2m0s",
+					Field:   "spec.probes.custom",
+				},
+			},
+		}),
+		Entry("policy has valid rollout", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Rollout: &shared.RolloutStrategy{
+						Waves: []shared.RolloutWave{
+							{Name: "canary", Nodes: &canaryNodes},
+							{Name: "rack-r1", NodeSelector: map[string]string{"rack": "r1"}},
+						},
+						SoakDuration: &metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			validationFn:     validatePolicyRollout,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has rollout with duplicated wave names and wrong fields", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Rollout: &shared.RolloutStrategy{
+						Waves: []shared.RolloutWave{
+							{Name: "canary", Nodes: &wrongNodes},
+							{Name: "canary"},
+						},
+						SoakDuration: &metav1.Duration{Duration: -time.Hour},
+					},
+				},
+			},
+			validationFn: validatePolicyRollout,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid rollout soak duration \"-1h0m0s\": must not be negative",
+					Field:   "spec.rollout.soakDuration",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid rollout wave nodes \"foo\": must be a non negative number or percentage",
+					Field:   "spec.rollout.waves[0].nodes",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: "duplicated rollout wave name \"canary\"",
+					Field:   "spec.rollout.waves[1].name",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueRequired,
+					Message: "rollout wave needs nodeSelector or nodes",
+					Field:   "spec.rollout.waves[1]",
+				},
+			},
+		}),
+		Entry("policy has valid maintenance window", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					MaintenanceWindow: &shared.MaintenanceWindow{
+						Schedule: "0 22 * * 1-5",
+						Duration: metav1.Duration{Duration: 4 * time.Hour},
+						TimeZone: "Europe/Madrid",
+					},
+				},
+			},
+			validationFn:     validatePolicyMaintenanceWindow,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has maintenance window with wrong fields", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					MaintenanceWindow: &shared.MaintenanceWindow{
+						Schedule: "0 25 * * *",
+						TimeZone: "Mars/Olympus",
+					},
+				},
+			},
+			validationFn: validatePolicyMaintenanceWindow,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid maintenance window schedule: hour 25 out of range [0-23]",
+					Field:   "spec.maintenanceWindow.schedule",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid maintenance window duration \"0s\": must be positive",
+					Field:   "spec.maintenanceWindow.duration",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid maintenance window time zone \"Mars/Olympus\": unknown time zone Mars/Olympus",
+					Field:   "spec.maintenanceWindow.timeZone",
+				},
+			},
+		}),
+		Entry("policy has valid drain timeout", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DrainBeforeApply: true,
+					DrainTimeout:     &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+			validationFn:     validatePolicyDrain,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has wrong drain timeout without drainBeforeApply", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DrainTimeout: &metav1.Duration{Duration: -time.Minute},
+				},
+			},
+			validationFn: validatePolicyDrain,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "drain timeout needs drainBeforeApply",
+					Field:   "spec.drainTimeout",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid drain timeout \"-1m0s\": must be positive",
+					Field:   "spec.drainTimeout",
+				},
+			},
+		}),
+		Entry("policy has a valid desired state", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DesiredState: shared.NewState(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  mtu: "1500"
+  ipv4:
+    enabled: "true"
+    dhcp: false
+  bridge:
+    port:
+    - name: eth1
+`),
+				},
+			},
+			validationFn:     validatePolicyDesiredState(clientWithNodeNetworkStates(nil)),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has a desired state with wrong types", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DesiredState: shared.NewState(`
+interfaces:
+- name: eth1
+  ipv4:
+    enabled: maybe
+`),
+				},
+			},
+			validationFn: validatePolicyDesiredState(clientWithNodeNetworkStates(nil)),
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "invalid desired state: failed decoding state: expected a boolean, got \"maybe\"",
+				Field:   "spec.desiredState",
+			}},
+		}),
+		Entry("policy has a desired state with a typo", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DesiredState: shared.NewState(`
+interfaces:
+- name: eth1.100
+  type: vlan
+  stat: up
+  vlan:
+    base-iface: eth1
+    id: 4096
+`),
+				},
+			},
+			validationFn: validatePolicyDesiredState(clientWithNodeNetworkStates(nil)),
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `spec.desiredState.interfaces[0].stat: Invalid value: "stat": unknown field, did you mean "state"?`,
+					Field:   "spec.desiredState.interfaces[0].stat",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "spec.desiredState.interfaces[0].vlan.id: Invalid value: 4096: must be between 0 and 4094",
+					Field:   "spec.desiredState.interfaces[0].vlan.id",
+				},
+			},
+		}),
+		Entry("policy has a desired state with ports missing at the selected nodes", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					NodeSelector: map[string]string{"node": "node02"},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: bond1
+  type: bond
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth1
+    - eth2
+`),
+				},
+			},
+			validationFn: validatePolicyDesiredState(clientWithNodeNetworkStates(map[string][]string{
+				"node01": {"eth1"},
+				"node02": {"eth1", "eth3"},
+			})),
+			validationResult: []metav1.StatusCause{{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[0].link-aggregation.port[1]: Invalid value: "eth2": ` +
+					`interface not defined at the desired state nor found at nodes node02`,
+				Field: "spec.desiredState.interfaces[0].link-aggregation.port[1]",
+			}},
+		}),
+		Entry("policy has a desired state with ip pool references", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DesiredState: shared.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - "{{ ippool.storage }}"
+`),
+				},
+			},
+			validationFn: validatePolicyDesiredState(clientWithNodeIPPools(nmstatev1beta1.NodeIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+				Spec:       shared.NodeIPPoolSpec{Range: shared.NodeIPPoolRange{CIDR: "10.10.10.0/24"}},
+			})),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has a desired state referencing a missing ip pool", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					DesiredState: shared.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: "{{ ippool.storage.ip }}"
+      prefix-length: "{{ ippool.storage.prefix-length }}"
+`),
+				},
+			},
+			validationFn: validatePolicyDesiredState(clientWithNodeIPPools()),
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "invalid desired state: failed resolving NodeIPPool storage address: not found",
+				Field:   "spec.desiredState",
+			}},
+		}),
+		Entry("policy has node templates with values at the selected nodes", ValidationWebhookCase{
+			policy: statePolicy("rack-vlan", map[string]string{"role": "worker"}, `
+interfaces:
+- name: eth1.100
+  type: vlan
+  description: "{{ node.name }} at rack {{ node.labels['rack'] }}"
+`),
+			validationFn:     validatePolicyNodeTemplates(clientWithNodesAndPolicies(workerNodes)),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has node templates with wrong syntax", ValidationWebhookCase{
+			policy: statePolicy("rack-vlan", allNodes, `
+interfaces:
+- name: eth1.100
+  description: "{{ node.labels.rack }}"
+`),
+			validationFn: validatePolicyNodeTemplates(clientWithNodesAndPolicies(workerNodes)),
+			validationResult: []metav1.StatusCause{{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[0].description: Invalid value: "{{ node.labels.rack }}": ` +
+					`invalid node template: expected '[' after node.labels at position 13`,
+				Field: "spec.desiredState.interfaces[0].description",
+			}},
+		}),
+		Entry("policy has node templates with values missing at selected nodes", ValidationWebhookCase{
+			policy: statePolicy("zone-vlan", allNodes, `
+interfaces:
+- name: eth1.100
+  description: "{{ node.labels['zone'] }}"
+`),
+			validationFn: validatePolicyNodeTemplates(clientWithNodesAndPolicies(workerNodes)),
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    causeTypeWarning,
+					Message: `failed rendering node templates: node node01 has no label "zone"`,
+					Field:   "spec.desiredState",
+				},
+				{
+					Type:    causeTypeWarning,
+					Message: `failed rendering node templates: node node02 has no label "zone"`,
+					Field:   "spec.desiredState",
+				},
+			},
+		}),
+		Entry("policy has a desired state with node templates rendered at a selected node", ValidationWebhookCase{
+			policy: statePolicy("rack-vlan", allNodes, `
+interfaces:
+- name: eth1.vlan
+  type: vlan
+  vlan:
+    base-iface: eth1
+    id: "{{ node.labels['vlan'] | int }}"
+`),
+			validationFn: validatePolicyDesiredState(clientWithNodesAndPolicies(map[string]map[string]string{
+				"node01": {},
+				"node02": {"vlan": "4095"},
+			})),
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "spec.desiredState.interfaces[0].vlan.id: Invalid value: 4095: must be between 0 and 4094",
+				Field:   "spec.desiredState.interfaces[0].vlan.id",
+			}},
+		}),
+		Entry("policy with capture references at desired state", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Capture: map[string]string{
+						"base-iface": `interfaces.name=="eth1"`,
+					},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: eth1
+  ipv4: "{{ capture.base-iface.interfaces.0.ipv4 }}"
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: "{{ capture.base-iface.interfaces.0.name }}"
+    - "{{ capture.base-iface.interfaces.0.bridge.port.0 }}"
+- name: "{{ capture.base-iface.interfaces.0.name }}.100"
+  type: vlan
+  vlan:
+    base-iface: "{{ capture.base-iface.interfaces.0.name }}"
+    id: "{{ capture.base-iface.interfaces.0.vlan.id }}"
+routes:
+  config: "{{ capture.base-iface.routes.running }}"
+`),
+				},
+			},
+			validationFn:     validatePolicyDesiredState(clientWithNodeNetworkStates(nil)),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy with capture references and schema errors at desired state", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Capture: map[string]string{
+						"base-iface": `interfaces.name=="eth1"`,
+					},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: "{{ capture.base-iface.interfaces.0.name }}.100"
+  type: vlan
+  stat: up
+  vlan:
+    base-iface: "{{ capture.base-iface.interfaces.0.name }}"
+    id: 4095
+`),
+				},
+			},
+			validationFn: validatePolicyDesiredState(clientWithNodeNetworkStates(nil)),
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `spec.desiredState.interfaces[0].stat: Invalid value: "stat": unknown field, did you mean "state"?`,
+					Field:   "spec.desiredState.interfaces[0].stat",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "spec.desiredState.interfaces[0].vlan.id: Invalid value: 4095: must be between 0 and 4094",
+					Field:   "spec.desiredState.interfaces[0].vlan.id",
+				},
+			},
+		}),
+		Entry("policy has valid capture expressions", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Capture: map[string]string{
+						"default-gw": `routes.running.destination=="0.0.0.0/0"`,
+						"base-iface": `interfaces.name==capture.default-gw.routes.running.0.next-hop-interface`,
+					},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: "{{ capture.base-iface.interfaces.0.name }}"
+`),
+				},
+			},
+			validationFn:     validatePolicyCapture,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has wrong capture expressions", ValidationWebhookCase{
+			policy: nmstatev1.NodeNetworkConfigurationPolicy{
+				Spec: shared.NodeNetworkConfigurationPolicySpec{
+					Capture: map[string]string{
+						"default-gw": `routes.running.destination="0.0.0.0/0"`,
+					},
+					DesiredState: shared.NewState(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  bridge:
+    port:
+    - name: "{{ capture.base-iface.interfaces.0.name }}"
+`),
+				},
+			},
+			validationFn: validatePolicyCapture,
+			validationResult: []metav1.StatusCause{
+				{
+					Type: metav1.CauseTypeFieldValueInvalid,
+					Message: `spec.capture[default-gw]: Invalid value: "routes.running.destination=\"0.0.0.0/0\"": ` +
+						`unexpected character '=' at position 27`,
+					Field: "spec.capture[default-gw]",
+				},
+				{
+					Type: metav1.CauseTypeFieldValueInvalid,
+					Message: `spec.desiredState.interfaces[0].bridge.port[0].name: Invalid value: "{{ capture.base-iface.interfaces.0.name }}": ` +
+						`references undefined capture "base-iface"`,
+					Field: "spec.desiredState.interfaces[0].bridge.port[0].name",
+				},
+			},
+		}),
+		Entry("policy does not conflict with policies at other nodes", ValidationWebhookCase{
+			policy: statePolicy("eth1-up", map[string]string{"rack": "r1"}, eth1Up),
+			validationFn: validatePolicyConflicts(clientWithNodesAndPolicies(workerNodes,
+				statePolicy("eth1-down", map[string]string{"rack": "r2"}, eth1Down),
+			)),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy does not conflict with its previous version", ValidationWebhookCase{
+			policy: statePolicy("eth1", map[string]string{"rack": "r1"}, eth1Up),
+			validationFn: validatePolicyConflicts(clientWithNodesAndPolicies(workerNodes,
+				statePolicy("eth1", map[string]string{"rack": "r1"}, eth1Down),
+			)),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy conflicts with a policy at the same nodes", ValidationWebhookCase{
+			policy: statePolicy("eth1-up", map[string]string{"rack": "r1"}, eth1Up),
+			validationFn: validatePolicyConflicts(clientWithNodesAndPolicies(workerNodes,
+				statePolicy("eth1-down", map[string]string{"role": "worker"}, eth1Down),
+			)),
+			validationResult: []metav1.StatusCause{{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[0].state: Invalid value: "up": ` +
+					`conflicts with policy "eth1-down" setting it to "down", both policies select nodes node01`,
+				Field: "spec.desiredState.interfaces[0].state",
+			}},
+		}),
+		Entry("policy may conflict with a policy at nodes not existing yet", ValidationWebhookCase{
+			policy: statePolicy("eth1-up", map[string]string{"rack": "r3"}, eth1Up),
+			validationFn: validatePolicyConflicts(clientWithNodesAndPolicies(workerNodes,
+				statePolicy("eth1-down", allNodes, eth1Down),
+			)),
+			validationResult: []metav1.StatusCause{{
+				Type: causeTypeWarning,
+				Message: `spec.desiredState.interfaces[0].state: Invalid value: "up": ` +
+					`conflicts with policy "eth1-down" setting it to "down", no node is selected by both policies yet`,
+				Field: "spec.desiredState.interfaces[0].state",
+			}},
+		}),
+		Entry("policy may conflict with a policy it depends on", ValidationWebhookCase{
+			policy: func() nmstatev1.NodeNetworkConfigurationPolicy {
+				policy := statePolicy("eth1-up", allNodes, eth1Up)
+				policy.Spec.DependsOn = []string{"eth1-down"}
+				return policy
+			}(),
+			validationFn: validatePolicyConflicts(clientWithNodesAndPolicies(workerNodes,
+				statePolicy("eth1-down", allNodes, eth1Down),
+			)),
+			validationResult: []metav1.StatusCause{{
+				Type: causeTypeWarning,
+				Message: `spec.desiredState.interfaces[0].state: Invalid value: "up": ` +
+					`conflicts with policy "eth1-down" setting it to "down", the policies are applied in dependency order`,
+				Field: "spec.desiredState.interfaces[0].state",
+			}},
+		}),
+		Entry("policy may conflict with a policy removing the interface", ValidationWebhookCase{
+			policy: statePolicy("eth1-up", allNodes, eth1Up),
+			validationFn: validatePolicyConflicts(clientWithNodesAndPolicies(workerNodes,
+				statePolicy("eth1-absent", allNodes, eth1Absent),
+			)),
+			validationResult: []metav1.StatusCause{{
+				Type: causeTypeWarning,
+				Message: `spec.desiredState.interfaces[0].state: Invalid value: "up": ` +
+					`conflicts with policy "eth1-absent" setting it to "absent", ` +
+					`one of the policies removes it, they are expected to be applied one after the other`,
+				Field: "spec.desiredState.interfaces[0].state",
+			}},
+		}),
 		Entry("policy cannot delete capture field", ValidationWebhookCase{
 			currentPolicy: nmstatev1.NodeNetworkConfigurationPolicy{
 				Spec: shared.NodeNetworkConfigurationPolicySpec{
@@ -218,5 +957,71 @@ var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 				Field:   "capture",
 			}},
 		}),
+		Entry("policy modifies a protected interface", ValidationWebhookCase{
+			policy: statePolicy("eth1-up", map[string]string{"role": "worker"}, eth1Up),
+			validationFn: validatePolicyProtected(clientWithNodesAndProtected(workerNodes,
+				nmstatev1.ProtectedInterface{Name: "eth*", NodeSelector: map[string]string{"rack": "r2"}},
+			)),
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseType(field.ErrorTypeForbidden),
+				Message: "spec.desiredState.interfaces[0].name: Forbidden: interface eth1 is protected by NMState nmstate",
+				Field:   "spec.desiredState.interfaces[0].name",
+			}},
+		}),
+		Entry("policy uses a protected interface as a vrf port", ValidationWebhookCase{
+			policy: statePolicy("vrf0", map[string]string{"role": "worker"}, `
+interfaces:
+- name: vrf0
+  type: vrf
+  vrf:
+    port:
+    - eth1
+    route-table-id: 100
+`),
+			validationFn: validatePolicyProtected(clientWithNodesAndProtected(workerNodes, nmstatev1.ProtectedInterface{Name: "eth1"})),
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseType(field.ErrorTypeForbidden),
+				Message: "spec.desiredState.interfaces[0].vrf.port[0]: Forbidden: interface eth1 is protected by NMState nmstate",
+				Field:   "spec.desiredState.interfaces[0].vrf.port[0]",
+			}},
+		}),
+		Entry("policy modifies an interface protected at nodes it does not select", ValidationWebhookCase{
+			policy: statePolicy("eth1-up", map[string]string{"rack": "r1"}, eth1Up),
+			validationFn: validatePolicyProtected(clientWithNodesAndProtected(workerNodes,
+				nmstatev1.ProtectedInterface{Name: "eth*", NodeSelector: map[string]string{"rack": "r2"}},
+			)),
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy modifies a protected interface with the override annotation", ValidationWebhookCase{
+			policy: func() nmstatev1.NodeNetworkConfigurationPolicy {
+				policy := statePolicy("eth1-up", allNodes, eth1Up)
+				policy.Annotations = map[string]string{shared.NodeNetworkConfigurationPolicyOverrideProtectedAnnotation: "true"}
+				return policy
+			}(),
+			validationFn:     validatePolicyProtected(clientWithNodesAndProtected(workerNodes, nmstatev1.ProtectedInterface{Name: "eth1"})),
+			validationResult: []metav1.StatusCause{},
+		}),
+	)
+})
+
+var _ = Describe("Conflicts between the example policies", func() {
+	examplePolicy := func(name string) nmstatev1.NodeNetworkConfigurationPolicy {
+		manifest, err := os.ReadFile(filepath.Join("..", "..", "..", "docs", "examples", name+".yaml"))
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		policy := nmstatev1.NodeNetworkConfigurationPolicy{}
+		ExpectWithOffset(1, yaml.Unmarshal(manifest, &policy)).To(Succeed())
+		return policy
+	}
+	DescribeTable("should admit applying one after the other",
+		func(first, second string) {
+			nodes := map[string]map[string]string{"node01": {"role": "worker"}}
+			policy := examplePolicy(second)
+			causes := validatePolicyConflicts(clientWithNodesAndPolicies(nodes, examplePolicy(first)))(&policy, nil)
+			errCauses, warnings := splitWarnings(causes)
+			Expect(errCauses).To(BeEmpty())
+			Expect(warnings).ToNot(BeEmpty())
+		},
+		Entry("dns and dns-cleanup", "dns", "dns-cleanup"),
+		Entry("linux-bridge and detach-bridge-port-and-restore-eth", "linux-bridge", "detach-bridge-port-and-restore-eth"),
 	)
 })